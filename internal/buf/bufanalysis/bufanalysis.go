@@ -30,6 +30,10 @@ const (
 	FormatJSON
 	// FormatMSVS is the MSVS format for FileAnnotations.
 	FormatMSVS
+	// FormatSARIF is the SARIF format for FileAnnotations.
+	//
+	// Unlike the other formats, all FileAnnotations are printed as a single SARIF log.
+	FormatSARIF
 )
 
 var (
@@ -40,6 +44,7 @@ var (
 		"text",
 		"json",
 		"msvs",
+		"sarif",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -49,19 +54,22 @@ var (
 		"gcc",
 		"json",
 		"msvs",
+		"sarif",
 	}
 
 	stringToFormat = map[string]Format{
 		"text": FormatText,
 		// alias for text
-		"gcc":  FormatText,
-		"json": FormatJSON,
-		"msvs": FormatMSVS,
+		"gcc":   FormatText,
+		"json":  FormatJSON,
+		"msvs":  FormatMSVS,
+		"sarif": FormatSARIF,
 	}
 	formatToString = map[Format]string{
-		FormatText: "text",
-		FormatJSON: "json",
-		FormatMSVS: "msvs",
+		FormatText:  "text",
+		FormatJSON:  "json",
+		FormatMSVS:  "msvs",
+		FormatSARIF: "sarif",
 	}
 )
 
@@ -197,11 +205,16 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) ([]File
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
+//
+// If the format is FormatSARIF, the file annotations are printed as a single SARIF log.
 func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	if format == FormatSARIF {
+		return printFileAnnotationsSARIF(writer, fileAnnotations)
+	}
 	for _, fileAnnotation := range fileAnnotations {
 		s, err := FormatFileAnnotation(fileAnnotation, format)
 		if err != nil {
@@ -215,6 +228,9 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 }
 
 // FormatFileAnnotation formats the FileAnnotation.
+//
+// If the format is FormatSARIF, the FileAnnotation is formatted as a SARIF log
+// containing only this FileAnnotation.
 func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string, error) {
 	switch format {
 	case FormatText:
@@ -227,6 +243,12 @@ func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string,
 		return string(data), nil
 	case FormatMSVS:
 		return fileAnnotation.MSVSString(), nil
+	case FormatSARIF:
+		data, err := marshalSARIF([]FileAnnotation{fileAnnotation})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
 	default:
 		return "", fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
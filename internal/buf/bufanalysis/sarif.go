@@ -0,0 +1,155 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifTool    = "buf"
+)
+
+// externalSARIFLog is a minimal SARIF log.
+//
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type externalSARIFLog struct {
+	Version string             `json:"version"`
+	Schema  string             `json:"$schema"`
+	Runs    []externalSARIFRun `json:"runs"`
+}
+
+type externalSARIFRun struct {
+	Tool    externalSARIFTool     `json:"tool"`
+	Results []externalSARIFResult `json:"results"`
+}
+
+type externalSARIFTool struct {
+	Driver externalSARIFDriver `json:"driver"`
+}
+
+type externalSARIFDriver struct {
+	Name string `json:"name"`
+}
+
+type externalSARIFResult struct {
+	RuleID    string                  `json:"ruleId,omitempty"`
+	Level     string                  `json:"level"`
+	Message   externalSARIFMessage    `json:"message"`
+	Locations []externalSARIFLocation `json:"locations,omitempty"`
+}
+
+type externalSARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type externalSARIFLocation struct {
+	PhysicalLocation externalSARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type externalSARIFPhysicalLocation struct {
+	ArtifactLocation externalSARIFArtifactLocation `json:"artifactLocation"`
+	Region           *externalSARIFRegion          `json:"region,omitempty"`
+}
+
+type externalSARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type externalSARIFRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// printFileAnnotationsSARIF prints the FileAnnotations as a single SARIF log.
+func printFileAnnotationsSARIF(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	data, err := marshalSARIF(fileAnnotations)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+func marshalSARIF(fileAnnotations []FileAnnotation) ([]byte, error) {
+	results := make([]externalSARIFResult, 0, len(fileAnnotations))
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation == nil {
+			continue
+		}
+		results = append(results, newExternalSARIFResult(fileAnnotation))
+	}
+	return json.Marshal(
+		externalSARIFLog{
+			Version: sarifVersion,
+			Schema:  sarifSchema,
+			Runs: []externalSARIFRun{
+				{
+					Tool: externalSARIFTool{
+						Driver: externalSARIFDriver{
+							Name: sarifTool,
+						},
+					},
+					Results: results,
+				},
+			},
+		},
+	)
+}
+
+func newExternalSARIFResult(fileAnnotation FileAnnotation) externalSARIFResult {
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = fileAnnotation.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = "FAILURE"
+		}
+	}
+	result := externalSARIFResult{
+		RuleID: fileAnnotation.Type(),
+		Level:  "error",
+		Message: externalSARIFMessage{
+			Text: message,
+		},
+	}
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		physicalLocation := externalSARIFPhysicalLocation{
+			ArtifactLocation: externalSARIFArtifactLocation{
+				URI: fileInfo.ExternalPath(),
+			},
+		}
+		// SARIF lines and columns are 1-based, so unknown locations are omitted
+		if fileAnnotation.StartLine() > 0 {
+			physicalLocation.Region = &externalSARIFRegion{
+				StartLine:   fileAnnotation.StartLine(),
+				StartColumn: fileAnnotation.StartColumn(),
+				EndLine:     fileAnnotation.EndLine(),
+				EndColumn:   fileAnnotation.EndColumn(),
+			}
+		}
+		result.Locations = []externalSARIFLocation{
+			{
+				PhysicalLocation: physicalLocation,
+			},
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreaking
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// againstSchemes are the schemes an against reference may have.
+var againstSchemes = []string{
+	"file",
+	"git",
+	"http",
+	"https",
+	"ssh",
+}
+
+// validateAgainst validates the syntax of the against reference.
+//
+// This does not check that the referenced image or source exists, as the
+// reference may be resolved relative to a remote bucket.
+//
+// An against reference is a path, URL, or module, optionally followed by
+// "#" and a comma-separated list of key=value options, ie "image.bin#format=bin".
+func validateAgainst(against string) error {
+	if against == "" {
+		return nil
+	}
+	if err := validateAgainstSyntax(against); err != nil {
+		return fmt.Errorf("invalid against %q: %w", against, err)
+	}
+	return nil
+}
+
+func validateAgainstSyntax(against string) error {
+	if strings.TrimSpace(against) != against {
+		return errors.New("must not have leading or trailing whitespace")
+	}
+	for _, r := range against {
+		if unicode.IsControl(r) {
+			return errors.New("must not contain control characters")
+		}
+	}
+	path := against
+	if index := strings.IndexByte(against, '#'); index >= 0 {
+		path = against[:index]
+		for _, option := range strings.Split(against[index+1:], ",") {
+			keyValue := strings.SplitN(option, "=", 2)
+			if len(keyValue) != 2 || keyValue[0] == "" || keyValue[1] == "" {
+				return fmt.Errorf("option %q must be of the form key=value", option)
+			}
+		}
+	}
+	if path == "" {
+		return errors.New("path is empty")
+	}
+	if index := strings.Index(path, "://"); index >= 0 {
+		scheme := path[:index]
+		if !againstSchemeIsKnown(scheme) {
+			return fmt.Errorf("unknown scheme %q, must be one of %s", scheme, strings.Join(againstSchemes, ", "))
+		}
+		if path[index+len("://"):] == "" {
+			return errors.New("path is empty")
+		}
+	}
+	return nil
+}
+
+func againstSchemeIsKnown(scheme string) bool {
+	for _, againstScheme := range againstSchemes {
+		if scheme == againstScheme {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,9 @@ package bufbreaking
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck"
@@ -29,6 +32,60 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// GranularityFile compares files by path.
+	GranularityFile Granularity = iota + 1
+	// GranularityPackage compares files by package.
+	//
+	// Messages, enums, and services may move between files of the same package,
+	// and files may be deleted as long as their package still exists.
+	GranularityPackage
+)
+
+var (
+	// AllGranularityStrings is all granularity strings.
+	AllGranularityStrings = []string{
+		"file",
+		"package",
+	}
+
+	stringToGranularity = map[string]Granularity{
+		"file":    GranularityFile,
+		"package": GranularityPackage,
+	}
+	granularityToString = map[Granularity]string{
+		GranularityFile:    "file",
+		GranularityPackage: "package",
+	}
+)
+
+// Granularity is the granularity at which breaking changes are detected.
+type Granularity int
+
+// String implements fmt.Stringer.
+func (g Granularity) String() string {
+	s, ok := granularityToString[g]
+	if !ok {
+		return strconv.Itoa(int(g))
+	}
+	return s
+}
+
+// ParseGranularity parses the Granularity.
+//
+// The empty string defaults to GranularityFile.
+func ParseGranularity(s string) (Granularity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return GranularityFile, nil
+	}
+	g, ok := stringToGranularity[s]
+	if ok {
+		return g, nil
+	}
+	return 0, fmt.Errorf("unknown granularity: %q", s)
+}
+
 // Handler handles the main breaking functionality.
 type Handler interface {
 	// Check runs the breaking checks.
@@ -67,6 +124,33 @@ type Config struct {
 	IgnoreIDToRootPaths    map[string]map[string]struct{}
 	IgnoreRootPaths        map[string]struct{}
 	IgnoreUnstablePackages bool
+	// Disabled is true if the checks were disabled with "enabled: false".
+	//
+	// If true, Check returns no FileAnnotations.
+	Disabled bool
+	// Granularity is the granularity at which breaking changes are detected.
+	//
+	// This is always set, and defaults to GranularityFile. If this is GranularityPackage,
+	// the rules that detect deletions from files were replaced with the rules that
+	// detect deletions from packages when the Config was created.
+	Granularity Granularity
+	// Parallelism is the maximum number of rules that are checked at once.
+	//
+	// If less than 1, runtime.GOMAXPROCS(0) is used.
+	Parallelism int
+	// Against is the reference of the image or source to check against, such as
+	// a path, a URL, or a module.
+	//
+	// This is empty if not set, in which case the reference must be given on the
+	// command line. Only the syntax of the reference is validated, not that it exists.
+	// This is only set for v1.
+	Against string
+	// Use are the ids and categories of the rules to use as configured, before
+	// they were resolved to Rules.
+	Use []string
+	// Except are the ids and categories of the rules to except as configured,
+	// before they were resolved to Rules.
+	Except []string
 }
 
 // GetRules returns the rules.
@@ -78,36 +162,62 @@ func (c *Config) GetRules() []bufcheck.Rule {
 
 // NewConfigV1Beta1 returns a new Config.
 func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) (*Config, error) {
-	internalConfig, err := internal.ConfigBuilder{
-		Use:                           externalConfig.Use,
-		Except:                        externalConfig.Except,
-		IgnoreRootPaths:               externalConfig.Ignore,
-		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
-		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
-	}.NewConfig(
+	granularity, err := ParseGranularity(externalConfig.Granularity)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := newInternalConfigForGranularity(
+		internal.ConfigBuilder{
+			Use:                           externalConfig.Use,
+			Except:                        externalConfig.Except,
+			IgnoreRootPaths:               externalConfig.Ignore,
+			IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
+			IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		},
 		bufbreakingv1beta1.VersionSpec,
+		granularity,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return internalConfigToConfig(internalConfig), nil
+	config := internalConfigToConfig(internalConfig)
+	config.Disabled = externalConfig.Enabled != nil && !*externalConfig.Enabled
+	config.Granularity = granularity
+	config.Use = externalConfig.Use
+	config.Except = externalConfig.Except
+	return config, nil
 }
 
 // NewConfigV1 returns a new Config.
 func NewConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
-	internalConfig, err := internal.ConfigBuilder{
-		Use:                           externalConfig.Use,
-		Except:                        externalConfig.Except,
-		IgnoreRootPaths:               externalConfig.Ignore,
-		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
-		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
-	}.NewConfig(
+	granularity, err := ParseGranularity(externalConfig.Granularity)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig, err := newInternalConfigForGranularity(
+		internal.ConfigBuilder{
+			Use:                           externalConfig.Use,
+			Except:                        externalConfig.Except,
+			IgnoreRootPaths:               externalConfig.Ignore,
+			IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
+			IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
+		},
 		bufbreakingv1.VersionSpec,
+		granularity,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return internalConfigToConfig(internalConfig), nil
+	if err := validateAgainst(externalConfig.Against); err != nil {
+		return nil, err
+	}
+	config := internalConfigToConfig(internalConfig)
+	config.Disabled = externalConfig.Enabled != nil && !*externalConfig.Enabled
+	config.Granularity = granularity
+	config.Against = externalConfig.Against
+	config.Use = externalConfig.Use
+	config.Except = externalConfig.Except
+	return config, nil
 }
 
 // GetAllRulesV1Beta1 gets all known rules.
@@ -140,26 +250,49 @@ func GetAllRulesV1() ([]bufcheck.Rule, error) {
 	return rulesToBufcheckRules(config.Rules), nil
 }
 
+// ValidateIDsOrCategoriesV1Beta1 returns an error listing every given id or category
+// that is not known at v1beta1, along with the closest known id or category.
+func ValidateIDsOrCategoriesV1Beta1(idsOrCategories []string) error {
+	return internal.ValidateIDsOrCategories(idsOrCategories, bufbreakingv1beta1.VersionSpec)
+}
+
+// ValidateIDsOrCategoriesV1 returns an error listing every given id or category
+// that is not known at v1, along with the closest known id or category.
+func ValidateIDsOrCategoriesV1(idsOrCategories []string) error {
+	return internal.ValidateIDsOrCategories(idsOrCategories, bufbreakingv1.VersionSpec)
+}
+
 // ExternalConfigV1Beta1 is an external config.
 type ExternalConfigV1Beta1 struct {
-	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
-	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	Use    []string `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
+	Except []string `json:"except,omitempty" yaml:"except,omitempty" toml:"except,omitempty"`
 	// IgnoreRootPaths
-	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty" toml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty" toml:"ignore_only,omitempty"`
+	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty" toml:"ignore_unstable_packages,omitempty"`
+	// Enabled is true if unset.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// Granularity is either "file" or "package", and is "file" if unset.
+	Granularity string `json:"granularity,omitempty" yaml:"granularity,omitempty" toml:"granularity,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
 type ExternalConfigV1 struct {
-	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
-	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	Use    []string `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
+	Except []string `json:"except,omitempty" yaml:"except,omitempty" toml:"except,omitempty"`
 	// IgnoreRootPaths
-	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty" toml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty" toml:"ignore_only,omitempty"`
+	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty" toml:"ignore_unstable_packages,omitempty"`
+	// Enabled is true if unset.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// Granularity is either "file" or "package", and is "file" if unset.
+	Granularity string `json:"granularity,omitempty" yaml:"granularity,omitempty" toml:"granularity,omitempty"`
+	// Against is the reference of the image or source to check against, ie
+	// "image.bin" or "https://github.com/acme/protos.git#branch=main".
+	Against string `json:"against,omitempty" yaml:"against,omitempty" toml:"against,omitempty"`
 }
 
 func internalConfigToConfig(internalConfig *internal.Config) *Config {
@@ -168,6 +301,7 @@ func internalConfigToConfig(internalConfig *internal.Config) *Config {
 		IgnoreIDToRootPaths:    internalConfig.IgnoreIDToRootPaths,
 		IgnoreRootPaths:        internalConfig.IgnoreRootPaths,
 		IgnoreUnstablePackages: internalConfig.IgnoreUnstablePackages,
+		Parallelism:            internalConfig.Parallelism,
 	}
 }
 
@@ -177,6 +311,7 @@ func configToInternalConfig(config *Config) *internal.Config {
 		IgnoreIDToRootPaths:    config.IgnoreIDToRootPaths,
 		IgnoreRootPaths:        config.IgnoreRootPaths,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		Parallelism:            config.Parallelism,
 	}
 }
 
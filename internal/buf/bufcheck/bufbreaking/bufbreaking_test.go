@@ -603,6 +603,28 @@ func TestRunBreakingIgnoreUnstablePackagesFalse(t *testing.T) {
 	)
 }
 
+func TestRunBreakingDisabled(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_disabled",
+	)
+}
+
+func TestRunBreakingGranularityFile(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_granularity_file",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a/v1/a.proto", "MESSAGE_NO_DELETE"),
+	)
+}
+
+func TestRunBreakingGranularityPackage(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_granularity_package",
+	)
+}
+
 func testBreaking(
 	t *testing.T,
 	relDirPath string,
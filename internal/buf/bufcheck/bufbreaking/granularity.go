@@ -0,0 +1,60 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreaking
+
+import (
+	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+)
+
+// fileRuleIDToPackageRuleID maps the ids of the rules that detect deletions from
+// files to the ids of the equivalent rules that detect deletions from packages.
+var fileRuleIDToPackageRuleID = map[string]string{
+	"ENUM_NO_DELETE":    "PACKAGE_ENUM_NO_DELETE",
+	"FILE_NO_DELETE":    "PACKAGE_NO_DELETE",
+	"MESSAGE_NO_DELETE": "PACKAGE_MESSAGE_NO_DELETE",
+	"SERVICE_NO_DELETE": "PACKAGE_SERVICE_NO_DELETE",
+}
+
+// newInternalConfigForGranularity returns a new internal Config for the ConfigBuilder.
+//
+// If the granularity is GranularityPackage, the rules in fileRuleIDToPackageRuleID are
+// replaced with their package equivalents, so that moving declarations between files
+// of the same package is not detected as a breaking change.
+func newInternalConfigForGranularity(
+	configBuilder internal.ConfigBuilder,
+	versionSpec *internal.VersionSpec,
+	granularity Granularity,
+) (*internal.Config, error) {
+	internalConfig, err := configBuilder.NewConfig(versionSpec)
+	if err != nil {
+		return nil, err
+	}
+	// an empty Use would result in the default rules
+	if granularity != GranularityPackage || len(internalConfig.Rules) == 0 {
+		return internalConfig, nil
+	}
+	ids := make([]string, 0, len(internalConfig.Rules))
+	for _, rule := range internalConfig.Rules {
+		id := rule.ID()
+		if packageID, ok := fileRuleIDToPackageRuleID[id]; ok {
+			id = packageID
+		}
+		ids = append(ids, id)
+	}
+	// the excepted ids were already removed
+	configBuilder.Use = ids
+	configBuilder.Except = nil
+	return configBuilder.NewConfig(versionSpec)
+}
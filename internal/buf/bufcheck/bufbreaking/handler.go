@@ -47,6 +47,9 @@ func (h *handler) Check(
 	previousImage bufimage.Image,
 	image bufimage.Image,
 ) ([]bufanalysis.FileAnnotation, error) {
+	if config.Disabled {
+		return nil, nil
+	}
 	previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
 	if err != nil {
 		return nil, err
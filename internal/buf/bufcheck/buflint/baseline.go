@@ -0,0 +1,113 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+)
+
+type externalBaseline struct {
+	Findings []externalBaselineEntry `json:"findings,omitempty" yaml:"findings,omitempty"`
+}
+
+type externalBaselineEntry struct {
+	Path   string `json:"path,omitempty" yaml:"path,omitempty"`
+	Rule   string `json:"rule,omitempty" yaml:"rule,omitempty"`
+	Line   int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column int    `json:"column,omitempty" yaml:"column,omitempty"`
+}
+
+func parseBaseline(data []byte) ([]BaselineEntry, error) {
+	var externalBaseline externalBaseline
+	if err := encoding.UnmarshalJSONOrYAMLStrict(data, &externalBaseline); err != nil {
+		return nil, err
+	}
+	baseline := make([]BaselineEntry, 0, len(externalBaseline.Findings))
+	for i, externalBaselineEntry := range externalBaseline.Findings {
+		if externalBaselineEntry.Path == "" {
+			return nil, fmt.Errorf("findings[%d]: path is required", i)
+		}
+		path, err := normalpath.NormalizeAndValidate(externalBaselineEntry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("findings[%d]: %w", i, err)
+		}
+		if externalBaselineEntry.Rule == "" {
+			return nil, fmt.Errorf("findings[%d]: rule is required", i)
+		}
+		baseline = append(
+			baseline,
+			BaselineEntry{
+				Path:        path,
+				RuleID:      externalBaselineEntry.Rule,
+				StartLine:   externalBaselineEntry.Line,
+				StartColumn: externalBaselineEntry.Column,
+			},
+		)
+	}
+	return baseline, nil
+}
+
+// normalizeBaselinePath normalizes and validates the baseline path.
+//
+// The empty string is returned as-is.
+func normalizeBaselinePath(baselinePath string) (string, error) {
+	if baselinePath == "" {
+		return "", nil
+	}
+	normalizedBaselinePath, err := normalpath.NormalizeAndValidate(baselinePath)
+	if err != nil {
+		return "", fmt.Errorf("baseline: %w", err)
+	}
+	if normalizedBaselinePath == "." {
+		return "", errors.New("baseline: must be a file")
+	}
+	return normalizedBaselinePath, nil
+}
+
+// applyBaseline returns the FileAnnotations that do not match any BaselineEntry.
+func applyBaseline(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	baseline []BaselineEntry,
+) []bufanalysis.FileAnnotation {
+	if len(baseline) == 0 {
+		return fileAnnotations
+	}
+	baselineEntries := make(map[BaselineEntry]struct{}, len(baseline))
+	for _, baselineEntry := range baseline {
+		baselineEntries[baselineEntry] = struct{}{}
+	}
+	var result []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		var path string
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			path = fileInfo.Path()
+		}
+		if _, ok := baselineEntries[BaselineEntry{
+			Path:        path,
+			RuleID:      fileAnnotation.Type(),
+			StartLine:   fileAnnotation.StartLine(),
+			StartColumn: fileAnnotation.StartColumn(),
+		}]; ok {
+			continue
+		}
+		result = append(result, fileAnnotation)
+	}
+	return result
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint/internal/buflintv1beta1"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
 	"github.com/bufbuild/buf/internal/buf/bufimage"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"go.uber.org/zap"
 )
 
@@ -74,7 +75,81 @@ type Config struct {
 	Rules               []Rule
 	IgnoreIDToRootPaths map[string]map[string]struct{}
 	IgnoreRootPaths     map[string]struct{}
+	// IgnoreSyntaxToIDs contains the ids that are ignored for files of a given syntax.
+	IgnoreSyntaxToIDs   map[protosource.Syntax]map[string]struct{}
 	AllowCommentIgnores bool
+	// Disabled is true if the checks were disabled with "enabled: false".
+	//
+	// If true, Check returns no FileAnnotations.
+	Disabled bool
+	// MessageTemplates is a map from rule id to the template to use for the
+	// messages of the FileAnnotations of that rule.
+	//
+	// Templates may contain the placeholders {path}, {line}, {column}, {rule},
+	// and {message}, where {message} is the default message.
+	// Rules without a template use the default message.
+	MessageTemplates map[string]string
+	// AllowedFieldNumberRanges are the ranges field numbers must be within
+	// for FIELD_NUMBER_ALLOWED.
+	//
+	// If empty, all field numbers outside of the reserved range 19000 to 19999 are allowed.
+	AllowedFieldNumberRanges []FieldNumberRange
+	// EnumValuePrefix is the prefix enum value names must have for ENUM_VALUE_PREFIX,
+	// where "{ENUM_NAME}" is replaced with the UPPER_SNAKE_CASE name of the enum.
+	//
+	// This is always set, and defaults to "{ENUM_NAME}_".
+	EnumValuePrefix string
+	// Parallelism is the maximum number of rules that are checked at once.
+	//
+	// If less than 1, runtime.GOMAXPROCS(0) is used.
+	Parallelism int
+	// BaselinePath is the normalized path of the baseline file, relative to the
+	// directory of the configuration file.
+	//
+	// This is empty if there is no baseline. This package never reads the file, the
+	// caller is expected to read it with ParseBaseline and set Baseline.
+	BaselinePath string
+	// Baseline are the accepted existing findings.
+	//
+	// FileAnnotations that match a BaselineEntry are not returned by Check.
+	Baseline []BaselineEntry
+	// Use are the ids and categories of the rules to use as configured, before
+	// they were resolved to Rules.
+	Use []string
+	// Except are the ids and categories of the rules to except as configured,
+	// before they were resolved to Rules.
+	Except []string
+}
+
+// BaselineEntry is an accepted existing finding within a baseline file.
+//
+// A FileAnnotation matches a BaselineEntry if its path, rule id, start line,
+// and start column are equal to those of the BaselineEntry.
+type BaselineEntry struct {
+	// Path is the path of the file relative to the root of the module.
+	Path        string
+	RuleID      string
+	StartLine   int
+	StartColumn int
+}
+
+// ParseBaseline parses the JSON or YAML data of a baseline file.
+//
+// A baseline file has a list of findings, for example:
+//
+//   findings:
+//     - path: foo/foo.proto
+//       rule: ENUM_PASCAL_CASE
+//       line: 5
+//       column: 1
+func ParseBaseline(data []byte) ([]BaselineEntry, error) {
+	return parseBaseline(data)
+}
+
+// FieldNumberRange is an inclusive range of field numbers.
+type FieldNumberRange struct {
+	Start int32
+	End   int32
 }
 
 // GetRules returns the rules.
@@ -84,46 +159,100 @@ func (c *Config) GetRules() []bufcheck.Rule {
 
 // NewConfigV1Beta1 returns a new Config.
 func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) (*Config, error) {
+	allowedFieldNumberRanges, err := parseFieldNumberRanges(externalConfig.AllowedFieldNumberRanges)
+	if err != nil {
+		return nil, err
+	}
+	baselinePath, err := normalizeBaselinePath(externalConfig.Baseline)
+	if err != nil {
+		return nil, err
+	}
+	enumValuePrefix, err := parseEnumValuePrefix(externalConfig.EnumValuePrefix)
+	if err != nil {
+		return nil, err
+	}
 	internalConfig, err := internal.ConfigBuilder{
 		Use:                                  externalConfig.Use,
 		Except:                               externalConfig.Except,
+		Proto2Except:                         externalConfig.Proto2Except,
+		Proto3Except:                         externalConfig.Proto3Except,
 		IgnoreRootPaths:                      externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths:        externalConfig.IgnoreOnly,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		EnumValuePrefix:                      enumValuePrefix,
 		EnumZeroValueSuffix:                  externalConfig.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          externalConfig.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  externalConfig.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
+		AllowedFieldNumberRanges:             fieldNumberRangesToInternalFieldNumberRanges(allowedFieldNumberRanges),
 	}.NewConfig(
 		buflintv1beta1.VersionSpec,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return internalConfigToConfig(internalConfig), nil
+	if err := validateMessageTemplates(externalConfig.MessageTemplates, ValidateIDsOrCategoriesV1Beta1); err != nil {
+		return nil, err
+	}
+	config := internalConfigToConfig(internalConfig)
+	config.Disabled = externalConfig.Enabled != nil && !*externalConfig.Enabled
+	config.MessageTemplates = externalConfig.MessageTemplates
+	config.AllowedFieldNumberRanges = allowedFieldNumberRanges
+	config.BaselinePath = baselinePath
+	config.EnumValuePrefix = enumValuePrefix
+	config.Use = externalConfig.Use
+	config.Except = externalConfig.Except
+	return config, nil
 }
 
 // NewConfigV1 returns a new Config.
 func NewConfigV1(externalConfig ExternalConfigV1) (*Config, error) {
+	allowedFieldNumberRanges, err := parseFieldNumberRanges(externalConfig.AllowedFieldNumberRanges)
+	if err != nil {
+		return nil, err
+	}
+	baselinePath, err := normalizeBaselinePath(externalConfig.Baseline)
+	if err != nil {
+		return nil, err
+	}
+	enumValuePrefix, err := parseEnumValuePrefix(externalConfig.EnumValuePrefix)
+	if err != nil {
+		return nil, err
+	}
 	internalConfig, err := internal.ConfigBuilder{
 		Use:                                  externalConfig.Use,
 		Except:                               externalConfig.Except,
+		Proto2Except:                         externalConfig.Proto2Except,
+		Proto3Except:                         externalConfig.Proto3Except,
 		IgnoreRootPaths:                      externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths:        externalConfig.IgnoreOnly,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		EnumValuePrefix:                      enumValuePrefix,
 		EnumZeroValueSuffix:                  externalConfig.EnumZeroValueSuffix,
 		RPCAllowSameRequestResponse:          externalConfig.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  externalConfig.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
+		AllowedFieldNumberRanges:             fieldNumberRangesToInternalFieldNumberRanges(allowedFieldNumberRanges),
 	}.NewConfig(
 		buflintv1.VersionSpec,
 	)
 	if err != nil {
 		return nil, err
 	}
-	return internalConfigToConfig(internalConfig), nil
+	if err := validateMessageTemplates(externalConfig.MessageTemplates, ValidateIDsOrCategoriesV1); err != nil {
+		return nil, err
+	}
+	config := internalConfigToConfig(internalConfig)
+	config.Disabled = externalConfig.Enabled != nil && !*externalConfig.Enabled
+	config.MessageTemplates = externalConfig.MessageTemplates
+	config.AllowedFieldNumberRanges = allowedFieldNumberRanges
+	config.BaselinePath = baselinePath
+	config.EnumValuePrefix = enumValuePrefix
+	config.Use = externalConfig.Use
+	config.Except = externalConfig.Except
+	return config, nil
 }
 
 // GetAllRulesV1Beta1 gets all known rules.
@@ -156,36 +285,81 @@ func GetAllRulesV1() ([]bufcheck.Rule, error) {
 	return rulesToBufcheckRules(config.Rules), nil
 }
 
+// ValidateIDsOrCategoriesV1Beta1 returns an error listing every given id or category
+// that is not known at v1beta1, along with the closest known id or category.
+func ValidateIDsOrCategoriesV1Beta1(idsOrCategories []string) error {
+	return internal.ValidateIDsOrCategories(idsOrCategories, buflintv1beta1.VersionSpec)
+}
+
+// ValidateIDsOrCategoriesV1 returns an error listing every given id or category
+// that is not known at v1, along with the closest known id or category.
+func ValidateIDsOrCategoriesV1(idsOrCategories []string) error {
+	return internal.ValidateIDsOrCategories(idsOrCategories, buflintv1.VersionSpec)
+}
+
 // ExternalConfigV1Beta1 is an external config.
 type ExternalConfigV1Beta1 struct {
-	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
-	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	Use    []string `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
+	Except []string `json:"except,omitempty" yaml:"except,omitempty" toml:"except,omitempty"`
+	// Proto2Except are excepted for proto2 files only.
+	Proto2Except []string `json:"proto2_except,omitempty" yaml:"proto2_except,omitempty" toml:"proto2_except,omitempty"`
+	// Proto3Except are excepted for proto3 files only.
+	Proto3Except []string `json:"proto3_except,omitempty" yaml:"proto3_except,omitempty" toml:"proto3_except,omitempty"`
 	// IgnoreRootPaths
-	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty" toml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty" toml:"ignore_only,omitempty"`
+	EnumValuePrefix                      string              `json:"enum_value_prefix,omitempty" yaml:"enum_value_prefix,omitempty" toml:"enum_value_prefix,omitempty"`
+	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty" toml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty" toml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty" toml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty" toml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty" toml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty" toml:"allow_comment_ignores,omitempty"`
+	// Enabled is true if unset.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// MessageTemplates is a map from rule id to message template.
+	MessageTemplates map[string]string `json:"message_templates,omitempty" yaml:"message_templates,omitempty" toml:"message_templates,omitempty"`
+	// AllowedFieldNumberRanges are ranges of the form "start-end" or "number",
+	// where end may be "max".
+	AllowedFieldNumberRanges []string `json:"allowed_field_number_ranges,omitempty" yaml:"allowed_field_number_ranges,omitempty" toml:"allowed_field_number_ranges,omitempty"`
+	// Baseline is the path of the baseline file, relative to the directory of
+	// the configuration file.
+	Baseline string `json:"baseline,omitempty" yaml:"baseline,omitempty" toml:"baseline,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
 type ExternalConfigV1 struct {
-	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
-	Except []string `json:"except,omitempty" yaml:"except,omitempty"`
+	// Use and Except may contain both ids and categories such as DEFAULT, BASIC,
+	// or COMMENTS. Categories are expanded to their ids when the Config is created,
+	// and unknown ids or categories are reported with the closest known match.
+	Use    []string `json:"use,omitempty" yaml:"use,omitempty" toml:"use,omitempty"`
+	Except []string `json:"except,omitempty" yaml:"except,omitempty" toml:"except,omitempty"`
+	// Proto2Except are excepted for proto2 files only.
+	Proto2Except []string `json:"proto2_except,omitempty" yaml:"proto2_except,omitempty" toml:"proto2_except,omitempty"`
+	// Proto3Except are excepted for proto3 files only.
+	Proto3Except []string `json:"proto3_except,omitempty" yaml:"proto3_except,omitempty" toml:"proto3_except,omitempty"`
 	// IgnoreRootPaths
-	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
+	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty" toml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty" toml:"ignore_only,omitempty"`
+	EnumValuePrefix                      string              `json:"enum_value_prefix,omitempty" yaml:"enum_value_prefix,omitempty" toml:"enum_value_prefix,omitempty"`
+	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty" toml:"enum_zero_value_suffix,omitempty"`
+	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty" toml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty" toml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty" toml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty" toml:"service_suffix,omitempty"`
+	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty" toml:"allow_comment_ignores,omitempty"`
+	// Enabled is true if unset.
+	Enabled *bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// MessageTemplates is a map from rule id to message template.
+	MessageTemplates map[string]string `json:"message_templates,omitempty" yaml:"message_templates,omitempty" toml:"message_templates,omitempty"`
+	// AllowedFieldNumberRanges are ranges of the form "start-end" or "number",
+	// where end may be "max".
+	AllowedFieldNumberRanges []string `json:"allowed_field_number_ranges,omitempty" yaml:"allowed_field_number_ranges,omitempty" toml:"allowed_field_number_ranges,omitempty"`
+	// Baseline is the path of the baseline file, relative to the directory of
+	// the configuration file.
+	Baseline string `json:"baseline,omitempty" yaml:"baseline,omitempty" toml:"baseline,omitempty"`
 }
 
 // PrintFileAnnotations prints the FileAnnotations to the Writer.
@@ -265,7 +439,9 @@ func internalConfigToConfig(internalConfig *internal.Config) *Config {
 		Rules:               internalRulesToRules(internalConfig.Rules),
 		IgnoreIDToRootPaths: internalConfig.IgnoreIDToRootPaths,
 		IgnoreRootPaths:     internalConfig.IgnoreRootPaths,
+		IgnoreSyntaxToIDs:   internalConfig.IgnoreSyntaxToIDs,
 		AllowCommentIgnores: internalConfig.AllowCommentIgnores,
+		Parallelism:         internalConfig.Parallelism,
 	}
 }
 
@@ -274,7 +450,9 @@ func configToInternalConfig(config *Config) *internal.Config {
 		Rules:               rulesToInternalRules(config.Rules),
 		IgnoreIDToRootPaths: config.IgnoreIDToRootPaths,
 		IgnoreRootPaths:     config.IgnoreRootPaths,
+		IgnoreSyntaxToIDs:   config.IgnoreSyntaxToIDs,
 		AllowCommentIgnores: config.AllowCommentIgnores,
+		Parallelism:         config.Parallelism,
 	}
 }
 
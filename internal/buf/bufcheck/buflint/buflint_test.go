@@ -873,6 +873,103 @@ func TestCommentIgnoresCascadeOn(t *testing.T) {
 	)
 }
 
+func TestRunProto2Except(t *testing.T) {
+	testLint(
+		t,
+		"proto2_except",
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 5, 6, 5, 9, "ENUM_PASCAL_CASE"),
+	)
+}
+
+func TestRunFieldNumberAllowed(t *testing.T) {
+	testLint(
+		t,
+		"field_number_allowed",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 16, 7, 19, "FIELD_NUMBER_ALLOWED"),
+	)
+}
+
+func TestAllowedFieldNumberRangesInvalid(t *testing.T) {
+	t.Parallel()
+	for _, allowedFieldNumberRange := range []string{"0-10", "10-1", "foo", "1-536870912"} {
+		_, err := buflint.NewConfigV1(
+			buflint.ExternalConfigV1{
+				AllowedFieldNumberRanges: []string{allowedFieldNumberRange},
+			},
+		)
+		assert.Error(t, err, allowedFieldNumberRange)
+	}
+}
+
+func TestRunEnumValuePrefixConfigured(t *testing.T) {
+	testLint(
+		t,
+		"enum_value_prefix_configured",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 3, 7, 12, "ENUM_VALUE_PREFIX"),
+	)
+}
+
+func TestEnumValuePrefixInvalid(t *testing.T) {
+	t.Parallel()
+	for _, enumValuePrefix := range []string{"{ENUM}_", "{ENUM_NAME}-", "K_{ENUM_NAME"} {
+		_, err := buflint.NewConfigV1(
+			buflint.ExternalConfigV1{
+				EnumValuePrefix: enumValuePrefix,
+			},
+		)
+		assert.Error(t, err, enumValuePrefix)
+	}
+	config, err := buflint.NewConfigV1(buflint.ExternalConfigV1{})
+	require.NoError(t, err)
+	assert.Equal(t, "{ENUM_NAME}_", config.EnumValuePrefix)
+}
+
+func TestRunMessageTemplates(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := testLintGetFileAnnotations(t, "message_templates", nil)
+	require.Len(t, fileAnnotations, 1)
+	assert.Equal(t, "ENUM_PASCAL_CASE", fileAnnotations[0].Type())
+	assert.Equal(
+		t,
+		`a.proto:5 violates ENUM_PASCAL_CASE (Enum name "foo" should be PascalCase, such as "Foo".) - see https://example.com/style`,
+		fileAnnotations[0].Message(),
+	)
+}
+
+func TestMessageTemplatesUnknownPlaceholder(t *testing.T) {
+	t.Parallel()
+	_, err := buflint.NewConfigV1(
+		buflint.ExternalConfigV1{
+			MessageTemplates: map[string]string{
+				"ENUM_PASCAL_CASE": "{file} is wrong",
+			},
+		},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown placeholder "{file}"`)
+}
+
+func TestRunBaseline(t *testing.T) {
+	testLint(
+		t,
+		"baseline",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 6, 9, 9, "ENUM_PASCAL_CASE"),
+	)
+}
+
+func TestParseBaselineInvalid(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{
+		`findings: [{rule: ENUM_PASCAL_CASE}]`,
+		`findings: [{path: a.proto}]`,
+		`findings: [{path: ../a.proto, rule: ENUM_PASCAL_CASE}]`,
+		`findings: [{path: a.proto, rule: ENUM_PASCAL_CASE, foo: bar}]`,
+	} {
+		_, err := buflint.ParseBaseline([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
 func testLint(
 	t *testing.T,
 	relDirPath string,
@@ -893,6 +990,18 @@ func testLintConfigModifier(
 	expectedFileAnnotations ...bufanalysis.FileAnnotation,
 ) {
 	t.Parallel()
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		testLintGetFileAnnotations(t, relDirPath, configModifier),
+	)
+}
+
+func testLintGetFileAnnotations(
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+) []bufanalysis.FileAnnotation {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	logger := zap.NewNop()
@@ -940,12 +1049,8 @@ func testLintConfigModifier(
 		config.Lint,
 		image,
 	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	require.NoError(t, err)
+	return fileAnnotations
 }
 
 func testGetConfig(
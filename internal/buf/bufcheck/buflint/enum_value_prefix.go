@@ -0,0 +1,50 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+)
+
+// parseEnumValuePrefix parses the enum value prefix for ENUM_VALUE_PREFIX.
+//
+// The empty string defaults to internal.DefaultEnumValuePrefix.
+func parseEnumValuePrefix(enumValuePrefix string) (string, error) {
+	enumValuePrefix = strings.TrimSpace(enumValuePrefix)
+	if enumValuePrefix == "" {
+		return internal.DefaultEnumValuePrefix, nil
+	}
+	withoutPlaceholders := strings.ReplaceAll(enumValuePrefix, internal.EnumValuePrefixEnumNamePlaceholder, "")
+	if strings.ContainsAny(withoutPlaceholders, "{}") {
+		return "", fmt.Errorf(
+			"enum_value_prefix %q has an unknown placeholder, only %s is supported",
+			enumValuePrefix,
+			internal.EnumValuePrefixEnumNamePlaceholder,
+		)
+	}
+	for _, c := range withoutPlaceholders {
+		if !(('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '_') {
+			return "", fmt.Errorf(
+				"enum_value_prefix %q can only contain letters, digits, underscores, and %s",
+				enumValuePrefix,
+				internal.EnumValuePrefixEnumNamePlaceholder,
+			)
+		}
+	}
+	return enumValuePrefix, nil
+}
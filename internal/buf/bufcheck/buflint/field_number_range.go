@@ -0,0 +1,99 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+)
+
+const (
+	minFieldNumber = 1
+	// maxFieldNumber is the maximum field number, 2^29 - 1.
+	maxFieldNumber = 536870911
+	// maxFieldNumberString can be used as the end of a range in place of maxFieldNumber.
+	maxFieldNumberString = "max"
+)
+
+// parseFieldNumberRanges parses ranges of the form "start-end" or "number",
+// where the end may also be "max".
+func parseFieldNumberRanges(fieldNumberRangeStrings []string) ([]FieldNumberRange, error) {
+	if len(fieldNumberRangeStrings) == 0 {
+		return nil, nil
+	}
+	fieldNumberRanges := make([]FieldNumberRange, 0, len(fieldNumberRangeStrings))
+	for _, fieldNumberRangeString := range fieldNumberRangeStrings {
+		fieldNumberRange, err := parseFieldNumberRange(fieldNumberRangeString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_field_number_ranges value %q: %w", fieldNumberRangeString, err)
+		}
+		fieldNumberRanges = append(fieldNumberRanges, fieldNumberRange)
+	}
+	return fieldNumberRanges, nil
+}
+
+func parseFieldNumberRange(fieldNumberRangeString string) (FieldNumberRange, error) {
+	split := strings.SplitN(fieldNumberRangeString, "-", 2)
+	start, err := parseFieldNumber(split[0])
+	if err != nil {
+		return FieldNumberRange{}, err
+	}
+	end := start
+	if len(split) == 2 {
+		if strings.TrimSpace(split[1]) == maxFieldNumberString {
+			end = maxFieldNumber
+		} else {
+			end, err = parseFieldNumber(split[1])
+			if err != nil {
+				return FieldNumberRange{}, err
+			}
+		}
+	}
+	if start > end {
+		return FieldNumberRange{}, fmt.Errorf("start %d is greater than end %d", start, end)
+	}
+	return FieldNumberRange{
+		Start: start,
+		End:   end,
+	}, nil
+}
+
+func parseFieldNumber(fieldNumberString string) (int32, error) {
+	fieldNumber, err := strconv.ParseInt(strings.TrimSpace(fieldNumberString), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", strings.TrimSpace(fieldNumberString))
+	}
+	if fieldNumber < minFieldNumber || fieldNumber > maxFieldNumber {
+		return 0, fmt.Errorf("field number %d must be between %d and %d", fieldNumber, minFieldNumber, maxFieldNumber)
+	}
+	return int32(fieldNumber), nil
+}
+
+func fieldNumberRangesToInternalFieldNumberRanges(fieldNumberRanges []FieldNumberRange) []internal.FieldNumberRange {
+	if fieldNumberRanges == nil {
+		return nil
+	}
+	internalFieldNumberRanges := make([]internal.FieldNumberRange, len(fieldNumberRanges))
+	for i, fieldNumberRange := range fieldNumberRanges {
+		internalFieldNumberRanges[i] = internal.FieldNumberRange{
+			Start: fieldNumberRange.Start,
+			End:   fieldNumberRange.End,
+		}
+	}
+	return internalFieldNumberRanges
+}
@@ -50,9 +50,17 @@ func (h *handler) Check(
 	config *Config,
 	image bufimage.Image,
 ) ([]bufanalysis.FileAnnotation, error) {
+	if config.Disabled {
+		return nil, nil
+	}
 	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, configToInternalConfig(config), nil, files)
+	fileAnnotations, err := h.runner.Check(ctx, configToInternalConfig(config), nil, files)
+	if err != nil {
+		return nil, err
+	}
+	fileAnnotations = applyBaseline(fileAnnotations, config.Baseline)
+	return applyMessageTemplates(fileAnnotations, config.MessageTemplates), nil
 }
@@ -92,10 +92,25 @@ var (
 		newAdapter(buflintcheck.CheckEnumPascalCase),
 	)
 	// EnumValuePrefixRuleBuilder is a rule builder.
-	EnumValuePrefixRuleBuilder = internal.NewNopRuleBuilder(
+	EnumValuePrefixRuleBuilder = internal.NewRuleBuilder(
 		"ENUM_VALUE_PREFIX",
-		"enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE",
-		newAdapter(buflintcheck.CheckEnumValuePrefix),
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.EnumValuePrefix == "" {
+				return "", errors.New("enum_value_prefix is empty")
+			}
+			if configBuilder.EnumValuePrefix == internal.DefaultEnumValuePrefix {
+				return "enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE", nil
+			}
+			return "enum values are prefixed with " + configBuilder.EnumValuePrefix + " (prefix is configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.EnumValuePrefix == "" {
+				return nil, errors.New("enum_value_prefix is empty")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckEnumValuePrefix(id, ignoreFunc, files, configBuilder.EnumValuePrefix)
+			}), nil
+		},
 	)
 	// EnumValueUpperSnakeCaseRuleBuilder is a rule builder.
 	EnumValueUpperSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
@@ -133,6 +148,18 @@ var (
 		`field names are not name capitalization of "descriptor" with any number of prefix or suffix underscores`,
 		newAdapter(buflintcheck.CheckFieldNoDescriptor),
 	)
+	// FieldNumberAllowedRuleBuilder is a rule builder.
+	FieldNumberAllowedRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_NUMBER_ALLOWED",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "field numbers are within the allowed ranges and not within the reserved range 19000 to 19999 (ranges are configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckFieldNumberAllowed(id, ignoreFunc, files, configBuilder.AllowedFieldNumberRanges)
+			}), nil
+		},
+	)
 	// FileLowerSnakeCaseRuleBuilder is a rule builder.
 	FileLowerSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"FILE_LOWER_SNAKE_CASE",
@@ -37,6 +37,9 @@ const (
 	// This is also used in buflint when constructing a new Runner, and is passed to the
 	// RunnerWithIgnorePrefix option.
 	CommentIgnorePrefix = "buf:lint:ignore"
+
+	reservedFieldNumberStart = 19000
+	reservedFieldNumberEnd   = 19999
 )
 
 var (
@@ -154,11 +157,26 @@ func checkEnumFirstValueZero(add addFunc, enum protosource.Enum) error {
 }
 
 // CheckEnumValuePrefix is a check function.
-var CheckEnumValuePrefix = newEnumValueCheckFunc(checkEnumValuePrefix)
+var CheckEnumValuePrefix = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	prefix string,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newEnumValueCheckFunc(
+		func(add addFunc, enumValue protosource.EnumValue) error {
+			return checkEnumValuePrefix(add, enumValue, prefix)
+		},
+	)(id, ignoreFunc, files)
+}
 
-func checkEnumValuePrefix(add addFunc, enumValue protosource.EnumValue) error {
+func checkEnumValuePrefix(add addFunc, enumValue protosource.EnumValue, prefix string) error {
 	name := enumValue.Name()
-	expectedPrefix := fieldToUpperSnakeCase(enumValue.Enum().Name()) + "_"
+	expectedPrefix := strings.ReplaceAll(
+		prefix,
+		internal.EnumValuePrefixEnumNamePlaceholder,
+		fieldToUpperSnakeCase(enumValue.Enum().Name()),
+	)
 	if !strings.HasPrefix(name, expectedPrefix) {
 		add(
 			enumValue,
@@ -278,6 +296,62 @@ func checkFieldNoDescriptor(add addFunc, field protosource.Field) error {
 	return nil
 }
 
+// CheckFieldNumberAllowed is a check function.
+var CheckFieldNumberAllowed = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	allowedFieldNumberRanges []internal.FieldNumberRange,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFieldCheckFunc(
+		func(add addFunc, field protosource.Field) error {
+			return checkFieldNumberAllowed(add, field, allowedFieldNumberRanges)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkFieldNumberAllowed(add addFunc, field protosource.Field, allowedFieldNumberRanges []internal.FieldNumberRange) error {
+	message := field.Message()
+	if message == nil {
+		// just a sanity check
+		return errors.New("field.Message() was nil")
+	}
+	if message.IsMapEntry() {
+		return nil
+	}
+	number := field.Number()
+	if number >= reservedFieldNumberStart && number <= reservedFieldNumberEnd {
+		add(
+			field,
+			field.NumberLocation(),
+			nil,
+			"Field %q has number %d, which is in the range %d to %d reserved for the Protobuf implementation.",
+			field.Name(),
+			number,
+			reservedFieldNumberStart,
+			reservedFieldNumberEnd,
+		)
+		return nil
+	}
+	if len(allowedFieldNumberRanges) == 0 {
+		return nil
+	}
+	for _, allowedFieldNumberRange := range allowedFieldNumberRanges {
+		if number >= int(allowedFieldNumberRange.Start) && number <= int(allowedFieldNumberRange.End) {
+			return nil
+		}
+	}
+	add(
+		field,
+		field.NumberLocation(),
+		nil,
+		"Field %q has number %d, which is not within the allowed field number ranges.",
+		field.Name(),
+		number,
+	)
+	return nil
+}
+
 // CheckFileLowerSnakeCase is a check function.
 var CheckFileLowerSnakeCase = newFileCheckFunc(checkFileLowerSnakeCase)
 
@@ -37,6 +37,7 @@ var (
 		buflintbuild.EnumValueUpperSnakeCaseRuleBuilder,
 		buflintbuild.EnumZeroValueSuffixRuleBuilder,
 		buflintbuild.FieldLowerSnakeCaseRuleBuilder,
+		buflintbuild.FieldNumberAllowedRuleBuilder,
 		buflintbuild.FileLowerSnakeCaseRuleBuilder,
 		buflintbuild.ImportNoPublicRuleBuilder,
 		buflintbuild.ImportNoWeakRuleBuilder,
@@ -132,6 +133,9 @@ var (
 			"BASIC",
 			"DEFAULT",
 		},
+		"FIELD_NUMBER_ALLOWED": {
+			"DEFAULT",
+		},
 		"FILE_LOWER_SNAKE_CASE": {
 			"DEFAULT",
 		},
@@ -0,0 +1,110 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"go.uber.org/multierr"
+)
+
+// messageTemplatePlaceholderRegexp matches placeholders such as {path}.
+var messageTemplatePlaceholderRegexp = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// messageTemplatePlaceholders are the known placeholders, without braces.
+var messageTemplatePlaceholders = []string{
+	"column",
+	"line",
+	"message",
+	"path",
+	"rule",
+}
+
+// validateMessageTemplates validates that all rule ids are known per validateIDs,
+// and that all templates only contain known placeholders.
+func validateMessageTemplates(
+	ruleIDToMessageTemplate map[string]string,
+	validateIDs func([]string) error,
+) error {
+	knownPlaceholders := stringutil.SliceToMap(messageTemplatePlaceholders)
+	ruleIDs := make([]string, 0, len(ruleIDToMessageTemplate))
+	for ruleID := range ruleIDToMessageTemplate {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+	err := validateIDs(ruleIDs)
+	for _, ruleID := range ruleIDs {
+		for _, match := range messageTemplatePlaceholderRegexp.FindAllStringSubmatch(ruleIDToMessageTemplate[ruleID], -1) {
+			if _, ok := knownPlaceholders[match[1]]; !ok {
+				err = multierr.Append(
+					err,
+					fmt.Errorf(
+						"message template for %q has unknown placeholder %q, known placeholders are %s",
+						ruleID,
+						match[0],
+						stringutil.SliceToHumanStringQuoted(messageTemplatePlaceholders),
+					),
+				)
+			}
+		}
+	}
+	return err
+}
+
+// applyMessageTemplates returns the FileAnnotations with the messages of all
+// FileAnnotations for rules with a message template replaced.
+func applyMessageTemplates(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	ruleIDToMessageTemplate map[string]string,
+) []bufanalysis.FileAnnotation {
+	if len(ruleIDToMessageTemplate) == 0 {
+		return fileAnnotations
+	}
+	result := make([]bufanalysis.FileAnnotation, len(fileAnnotations))
+	for i, fileAnnotation := range fileAnnotations {
+		messageTemplate, ok := ruleIDToMessageTemplate[fileAnnotation.Type()]
+		if !ok {
+			result[i] = fileAnnotation
+			continue
+		}
+		var path string
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			path = fileInfo.Path()
+		}
+		message := strings.NewReplacer(
+			"{column}", strconv.Itoa(fileAnnotation.StartColumn()),
+			"{line}", strconv.Itoa(fileAnnotation.StartLine()),
+			"{message}", fileAnnotation.Message(),
+			"{path}", path,
+			"{rule}", fileAnnotation.Type(),
+		).Replace(messageTemplate)
+		result[i] = bufanalysis.NewFileAnnotation(
+			fileAnnotation.FileInfo(),
+			fileAnnotation.StartLine(),
+			fileAnnotation.StartColumn(),
+			fileAnnotation.EndLine(),
+			fileAnnotation.EndColumn(),
+			fileAnnotation.Type(),
+			message,
+		)
+	}
+	return result
+}
@@ -15,15 +15,24 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"go.uber.org/multierr"
 )
 
 const (
+	// EnumValuePrefixEnumNamePlaceholder is the placeholder within an enum value
+	// prefix that is replaced with the UPPER_SNAKE_CASE name of the enum.
+	EnumValuePrefixEnumNamePlaceholder = "{ENUM_NAME}"
+	// DefaultEnumValuePrefix is the default enum value prefix.
+	DefaultEnumValuePrefix = EnumValuePrefixEnumNamePlaceholder + "_"
+
 	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
 	defaultServiceSuffix       = "Service"
 )
@@ -38,15 +47,39 @@ type Config struct {
 
 	IgnoreRootPaths     map[string]struct{}
 	IgnoreIDToRootPaths map[string]map[string]struct{}
+	// IgnoreSyntaxToIDs contains the ids that are ignored for files of a given syntax.
+	IgnoreSyntaxToIDs map[protosource.Syntax]map[string]struct{}
 
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
+	// Parallelism is the maximum number of rules the Runner checks at once.
+	//
+	// If less than 1, runtime.GOMAXPROCS(0) is used.
+	Parallelism int
+}
+
+// ValidateIDsOrCategories returns an error listing every element of idsOrCategories
+// that is not a known id or category for the VersionSpec.
+//
+// Each unknown id or category is reported with the closest known id or category, if any.
+func ValidateIDsOrCategories(idsOrCategories []string, versionSpec *VersionSpec) error {
+	_, err := transformToIDMap(
+		idsOrCategories,
+		versionSpec.IDToCategories,
+		getCategoryToIDs(versionSpec.IDToCategories),
+	)
+	return err
 }
 
 // ConfigBuilder is a config builder.
 type ConfigBuilder struct {
 	Use    []string
 	Except []string
+	// Proto2Except are the ids or categories to except for proto2 files,
+	// including files with no syntax specified.
+	Proto2Except []string
+	// Proto3Except are the ids or categories to except for proto3 files.
+	Proto3Except []string
 
 	IgnoreRootPaths               []string
 	IgnoreIDOrCategoryToRootPaths map[string][]string
@@ -54,11 +87,27 @@ type ConfigBuilder struct {
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
 
+	// EnumValuePrefix is the prefix enum value names must have, where
+	// EnumValuePrefixEnumNamePlaceholder is replaced with the UPPER_SNAKE_CASE
+	// name of the enum.
+	//
+	// If empty, DefaultEnumValuePrefix is used.
+	EnumValuePrefix                      string
 	EnumZeroValueSuffix                  string
 	RPCAllowSameRequestResponse          bool
 	RPCAllowGoogleProtobufEmptyRequests  bool
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
+	// AllowedFieldNumberRanges are the ranges field numbers must be within.
+	//
+	// If empty, all field numbers are allowed except for the reserved range.
+	AllowedFieldNumberRanges []FieldNumberRange
+}
+
+// FieldNumberRange is an inclusive range of field numbers.
+type FieldNumberRange struct {
+	Start int32
+	End   int32
 }
 
 // NewConfig returns a new Config.
@@ -73,6 +122,9 @@ func newConfig(configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config,
 		// default behavior
 		configBuilder.Use = versionSpec.DefaultCategories
 	}
+	if configBuilder.EnumValuePrefix == "" {
+		configBuilder.EnumValuePrefix = DefaultEnumValuePrefix
+	}
 	if configBuilder.EnumZeroValueSuffix == "" {
 		configBuilder.EnumZeroValueSuffix = defaultEnumZeroValueSuffix
 	}
@@ -98,13 +150,22 @@ func newConfigForRuleBuilders(
 		return nil, err
 	}
 	categoryToIDs := getCategoryToIDs(idToCategories)
-	useIDMap, err := transformToIDMap(configBuilder.Use, idToCategories, categoryToIDs)
-	if err != nil {
+	useIDMap, useErr := transformToIDMap(configBuilder.Use, idToCategories, categoryToIDs)
+	exceptIDMap, exceptErr := transformToIDMap(configBuilder.Except, idToCategories, categoryToIDs)
+	proto2ExceptIDMap, proto2ExceptErr := transformToIDMap(configBuilder.Proto2Except, idToCategories, categoryToIDs)
+	proto3ExceptIDMap, proto3ExceptErr := transformToIDMap(configBuilder.Proto3Except, idToCategories, categoryToIDs)
+	// report all unknown ids or categories at once
+	if err := multierr.Combine(useErr, exceptErr, proto2ExceptErr, proto3ExceptErr); err != nil {
 		return nil, err
 	}
-	exceptIDMap, err := transformToIDMap(configBuilder.Except, idToCategories, categoryToIDs)
-	if err != nil {
-		return nil, err
+	var ignoreSyntaxToIDs map[protosource.Syntax]map[string]struct{}
+	if len(proto2ExceptIDMap) > 0 || len(proto3ExceptIDMap) > 0 {
+		ignoreSyntaxToIDs = map[protosource.Syntax]map[string]struct{}{
+			// no syntax is functionally equivalent to proto2
+			protosource.SyntaxUnspecified: proto2ExceptIDMap,
+			protosource.SyntaxProto2:      proto2ExceptIDMap,
+			protosource.SyntaxProto3:      proto3ExceptIDMap,
+		}
 	}
 
 	// this removes duplicates
@@ -188,6 +249,7 @@ func newConfigForRuleBuilders(
 		Rules:                  resultRules,
 		IgnoreIDToRootPaths:    ignoreIDToRootPaths,
 		IgnoreRootPaths:        ignoreRootPaths,
+		IgnoreSyntaxToIDs:      ignoreSyntaxToIDs,
 		AllowCommentIgnores:    configBuilder.AllowCommentIgnores,
 		IgnoreUnstablePackages: configBuilder.IgnoreUnstablePackages,
 	}, nil
@@ -198,6 +260,7 @@ func transformToIDMap(idsOrCategories []string, idToCategories map[string][]stri
 		return nil, nil
 	}
 	idMap := make(map[string]struct{}, len(idsOrCategories))
+	var unknownIDsOrCategories []string
 	for _, idOrCategory := range idsOrCategories {
 		if idOrCategory == "" {
 			continue
@@ -210,9 +273,12 @@ func transformToIDMap(idsOrCategories []string, idToCategories map[string][]stri
 				idMap[id] = struct{}{}
 			}
 		} else {
-			return nil, fmt.Errorf("%q is not a known id or category", idOrCategory)
+			unknownIDsOrCategories = append(unknownIDsOrCategories, idOrCategory)
 		}
 	}
+	if len(unknownIDsOrCategories) > 0 {
+		return nil, newUnknownIDsOrCategoriesError(unknownIDsOrCategories, idToCategories, categoryToIDs)
+	}
 	return idMap, nil
 }
 
@@ -243,7 +309,7 @@ func transformToIDToListMap(idOrCategoryToList map[string][]string, idToCategori
 				}
 			}
 		} else {
-			return nil, fmt.Errorf("%q is not a known id or category", idOrCategory)
+			return nil, newUnknownIDsOrCategoriesError([]string{idOrCategory}, idToCategories, categoryToIDs)
 		}
 	}
 	return idToListMap, nil
@@ -314,3 +380,72 @@ func sortRules(rules []*Rule) {
 		},
 	)
 }
+
+func newUnknownIDsOrCategoriesError(
+	unknownIDsOrCategories []string,
+	idToCategories map[string][]string,
+	categoryToIDs map[string][]string,
+) error {
+	candidates := make([]string, 0, len(idToCategories)+len(categoryToIDs))
+	for id := range idToCategories {
+		candidates = append(candidates, id)
+	}
+	for category := range categoryToIDs {
+		candidates = append(candidates, category)
+	}
+	// sort so that ties in the suggestions are deterministic
+	sort.Strings(candidates)
+	messages := make([]string, len(unknownIDsOrCategories))
+	for i, unknownIDOrCategory := range unknownIDsOrCategories {
+		if suggestion := closestString(unknownIDOrCategory, candidates); suggestion != "" {
+			messages[i] = fmt.Sprintf("%q is not a known id or category, did you mean %q?", unknownIDOrCategory, suggestion)
+		} else {
+			messages[i] = fmt.Sprintf("%q is not a known id or category", unknownIDOrCategory)
+		}
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+// closestString returns the candidate with the smallest edit distance to s.
+//
+// Returns empty if no candidate is close enough to be a plausible suggestion.
+func closestString(s string, candidates []string) string {
+	// anything further than a third of the string is not a useful suggestion
+	maxDistance := len(s)/3 + 1
+	closest := ""
+	closestDistance := maxDistance + 1
+	for _, candidate := range candidates {
+		if distance := levenshteinDistance(s, candidate); distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+	return closest
+}
+
+func levenshteinDistance(one string, two string) int {
+	previous := make([]int, len(two)+1)
+	current := make([]int, len(two)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(one); i++ {
+		current[0] = i
+		for j := 1; j <= len(two); j++ {
+			cost := 1
+			if one[i-1] == two[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(minInt(previous[j]+1, current[j-1]+1), previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(two)]
+}
+
+func minInt(one int, two int) int {
+	if one < two {
+		return one
+	}
+	return two
+}
@@ -16,6 +16,7 @@ package internal
 
 import (
 	"context"
+	"runtime"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
@@ -70,17 +71,33 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	span.AddAttributes(
 		trace.Int64Attribute("num_files", int64(len(files))),
 		trace.Int64Attribute("num_rules", int64(len(rules))),
+		trace.Int64Attribute("parallelism", int64(config.Parallelism)),
 	)
 	defer span.End()
 
 	ignoreFunc := r.newIgnoreFunc(config)
 	var fileAnnotations []bufanalysis.FileAnnotation
-	resultC := make(chan *result, len(rules))
+	parallelism := config.Parallelism
+	if parallelism < 1 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if parallelism > len(rules) {
+		parallelism = len(rules)
+	}
+	ruleC := make(chan *Rule, len(rules))
 	for _, rule := range rules {
-		rule := rule
+		ruleC <- rule
+	}
+	close(ruleC)
+	// resultC is buffered for every rule so that workers never block
+	// if we return early because the context was cancelled
+	resultC := make(chan *result, len(rules))
+	for i := 0; i < parallelism; i++ {
 		go func() {
-			iFileAnnotations, iErr := rule.check(ignoreFunc, previousFiles, files)
-			resultC <- newResult(iFileAnnotations, iErr)
+			for rule := range ruleC {
+				iFileAnnotations, iErr := rule.check(ignoreFunc, previousFiles, files)
+				resultC <- newResult(iFileAnnotations, iErr)
+			}
 		}()
 	}
 	var err error
@@ -143,6 +160,9 @@ func idIsIgnoredForDescriptor(id string, descriptor protosource.Descriptor, conf
 	if id == "" {
 		return false
 	}
+	if _, ok := config.IgnoreSyntaxToIDs[descriptor.File().Syntax()][id]; ok {
+		return true
+	}
 	ignoreRootPaths, ok := config.IgnoreIDToRootPaths[id]
 	if !ok {
 		return false
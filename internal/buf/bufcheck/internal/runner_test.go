@@ -0,0 +1,69 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRunnerParallelism(t *testing.T) {
+	t.Parallel()
+	var lock sync.Mutex
+	var active int
+	var maxActive int
+	var numChecked int
+	checkFunc := func(string, IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+		lock.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		lock.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		lock.Lock()
+		active--
+		numChecked++
+		lock.Unlock()
+		return nil, nil
+	}
+	var rules []*Rule
+	for i := 0; i < 8; i++ {
+		rules = append(rules, newRule("RULE_"+strconv.Itoa(i), nil, "the probe is run", checkFunc))
+	}
+	fileAnnotations, err := NewRunner(zap.NewNop()).Check(
+		context.Background(),
+		&Config{
+			Rules:       rules,
+			Parallelism: 2,
+		},
+		nil,
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Empty(t, fileAnnotations)
+	assert.Equal(t, 8, numChecked)
+	assert.LessOrEqual(t, maxActive, 2)
+	assert.GreaterOrEqual(t, maxActive, 1)
+}
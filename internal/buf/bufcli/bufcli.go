@@ -329,6 +329,34 @@ func GetStringSliceFlagOrDeprecatedFlag(
 	return deprecatedFlag, nil
 }
 
+// GetErrorFormat gets the error format to print check violations with.
+//
+// The error format flag takes precedence. If it is not set, the output_format
+// of the first configuration that sets one is used. If no configuration sets
+// an output_format, the empty string is returned, which prints as text.
+func GetErrorFormat(errorFormatFlag string, imageConfigs []bufwire.ImageConfig) string {
+	if errorFormatFlag != "" {
+		return errorFormatFlag
+	}
+	for _, imageConfig := range imageConfigs {
+		if outputFormat := imageConfig.Config().OutputFormat; outputFormat != 0 {
+			return outputFormat.String()
+		}
+	}
+	return ""
+}
+
+// GetBuildErrorFormat gets the error format to print build errors with.
+//
+// Build errors are not affected by the output_format of the configuration. The
+// config-ignore-yaml format only applies to lint violations, so text is used instead.
+func GetBuildErrorFormat(errorFormatFlag string) string {
+	if errorFormatFlag == "config-ignore-yaml" {
+		return "text"
+	}
+	return errorFormatFlag
+}
+
 // NewWireImageConfigReader returns a new ImageConfigReader.
 func NewWireImageConfigReader(
 	container appflag.Container,
@@ -0,0 +1,115 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+func affectedFilesByConfigChange(
+	oldConfig *Config,
+	newConfig *Config,
+	fileInfos []bufmodule.FileInfo,
+) ([]bufmodule.FileInfo, error) {
+	if oldConfig == nil || oldConfig.Lint == nil || newConfig == nil || newConfig.Lint == nil {
+		return nil, errors.New("config has no lint configuration")
+	}
+	oldLintConfig := oldConfig.Lint
+	newLintConfig := newConfig.Lint
+	if !lintConfigGlobalsEqual(oldLintConfig, newLintConfig) {
+		return fileInfos, nil
+	}
+	var affectedFileInfos []bufmodule.FileInfo
+	for _, fileInfo := range fileInfos {
+		oldIgnored, oldIgnoredIDs := lintConfigIgnoresForPath(oldLintConfig, fileInfo.Path())
+		newIgnored, newIgnoredIDs := lintConfigIgnoresForPath(newLintConfig, fileInfo.Path())
+		if oldIgnored && newIgnored {
+			continue
+		}
+		if oldIgnored != newIgnored || !stringutil.SliceElementsEqual(oldIgnoredIDs, newIgnoredIDs) {
+			affectedFileInfos = append(affectedFileInfos, fileInfo)
+		}
+	}
+	return affectedFileInfos, nil
+}
+
+// lintConfigGlobalsEqual returns true if the settings of the lint configurations
+// that apply regardless of file path are equal.
+func lintConfigGlobalsEqual(one *buflint.Config, two *buflint.Config) bool {
+	if one.AllowCommentIgnores != two.AllowCommentIgnores || one.Disabled != two.Disabled {
+		return false
+	}
+	if !stringutil.SliceElementsEqual(lintConfigRuleIDs(one), lintConfigRuleIDs(two)) {
+		return false
+	}
+	if len(one.AllowedFieldNumberRanges) != len(two.AllowedFieldNumberRanges) {
+		return false
+	}
+	for i, allowedFieldNumberRange := range one.AllowedFieldNumberRanges {
+		if allowedFieldNumberRange != two.AllowedFieldNumberRanges[i] {
+			return false
+		}
+	}
+	if len(one.MessageTemplates) != len(two.MessageTemplates) {
+		return false
+	}
+	for ruleID, messageTemplate := range one.MessageTemplates {
+		if otherMessageTemplate, ok := two.MessageTemplates[ruleID]; !ok || otherMessageTemplate != messageTemplate {
+			return false
+		}
+	}
+	// empty sets of ignored ids are equivalent to no entry for the syntax
+	for syntax, ids := range one.IgnoreSyntaxToIDs {
+		if !stringutil.SliceElementsEqual(stringutil.MapToSortedSlice(ids), stringutil.MapToSortedSlice(two.IgnoreSyntaxToIDs[syntax])) {
+			return false
+		}
+	}
+	for syntax, ids := range two.IgnoreSyntaxToIDs {
+		if !stringutil.SliceElementsEqual(stringutil.MapToSortedSlice(ids), stringutil.MapToSortedSlice(one.IgnoreSyntaxToIDs[syntax])) {
+			return false
+		}
+	}
+	return true
+}
+
+func lintConfigRuleIDs(lintConfig *buflint.Config) []string {
+	ruleIDs := make([]string, 0, len(lintConfig.Rules))
+	for _, rule := range lintConfig.Rules {
+		ruleIDs = append(ruleIDs, rule.ID())
+	}
+	sort.Strings(ruleIDs)
+	return ruleIDs
+}
+
+// lintConfigIgnoresForPath returns true if the path is ignored entirely, and
+// otherwise the ids of the rules that are ignored for the path.
+func lintConfigIgnoresForPath(lintConfig *buflint.Config, path string) (bool, []string) {
+	if normalpath.MapHasEqualOrContainingPath(lintConfig.IgnoreRootPaths, path, normalpath.Relative) {
+		return true, nil
+	}
+	var ignoredIDs []string
+	for _, ruleID := range lintConfigRuleIDs(lintConfig) {
+		if normalpath.MapHasEqualOrContainingPath(lintConfig.IgnoreIDToRootPaths[ruleID], path, normalpath.Relative) {
+			ignoredIDs = append(ignoredIDs, ruleID)
+		}
+	}
+	return false, ignoredIDs
+}
@@ -0,0 +1,81 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAffectedFilesByConfigChange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	oldConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+  ignore_only:
+    ENUM_PASCAL_CASE:
+      - other`))
+	require.NoError(t, err)
+	overrideConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+  ignore_only:
+    ENUM_PASCAL_CASE:
+      - other
+    FIELD_LOWER_SNAKE_CASE:
+      - foo`))
+	require.NoError(t, err)
+	globalConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - MINIMAL
+  ignore_only:
+    ENUM_PASCAL_CASE:
+      - other`))
+	require.NoError(t, err)
+	var fileInfos []bufmodule.FileInfo
+	for _, path := range []string{"baz/c.proto", "foo/a.proto", "foo/bar/b.proto", "foobar/d.proto"} {
+		fileInfo, err := bufmodule.NewFileInfo(path, path, false, nil, "")
+		require.NoError(t, err)
+		fileInfos = append(fileInfos, fileInfo)
+	}
+
+	affectedFileInfos, err := AffectedFilesByConfigChange(oldConfig, oldConfig, fileInfos)
+	require.NoError(t, err)
+	assert.Empty(t, affectedFileInfos)
+	affectedFileInfos, err = AffectedFilesByConfigChange(oldConfig, overrideConfig, fileInfos)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo/a.proto", "foo/bar/b.proto"}, testFileInfoPaths(affectedFileInfos))
+	affectedFileInfos, err = AffectedFilesByConfigChange(oldConfig, globalConfig, fileInfos)
+	require.NoError(t, err)
+	assert.Equal(t, testFileInfoPaths(fileInfos), testFileInfoPaths(affectedFileInfos))
+}
+
+func testFileInfoPaths(fileInfos []bufmodule.FileInfo) []string {
+	paths := make([]string, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		paths = append(paths, fileInfo.Path())
+	}
+	return paths
+}
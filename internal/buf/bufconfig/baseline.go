@@ -0,0 +1,67 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+)
+
+// readLintBaselines returns a copy of the Config with the lint baselines of all
+// modules read from the readBucket.
+//
+// The Config may be cached, so it is never modified. If no module has a lint
+// baseline, the Config is returned as-is.
+func readLintBaselines(ctx context.Context, readBucket storage.ReadBucket, config *Config) (*Config, error) {
+	hasBaseline := false
+	for _, moduleConfig := range config.ModuleConfigs {
+		if moduleConfig.Lint != nil && moduleConfig.Lint.BaselinePath != "" {
+			hasBaseline = true
+		}
+	}
+	if !hasBaseline {
+		return config, nil
+	}
+	moduleConfigs := make([]*ModuleConfig, len(config.ModuleConfigs))
+	for i, moduleConfig := range config.ModuleConfigs {
+		moduleConfigs[i] = moduleConfig
+		if moduleConfig.Lint == nil || moduleConfig.Lint.BaselinePath == "" {
+			continue
+		}
+		baselinePath := normalpath.Join(moduleConfig.Path, moduleConfig.Lint.BaselinePath)
+		data, err := storage.ReadPath(ctx, readBucket, baselinePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read lint baseline: %w", err)
+		}
+		baseline, err := buflint.ParseBaseline(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", baselinePath, err)
+		}
+		lintConfig := *moduleConfig.Lint
+		lintConfig.Baseline = baseline
+		moduleConfigCopy := *moduleConfig
+		moduleConfigCopy.Lint = &lintConfig
+		moduleConfigs[i] = &moduleConfigCopy
+	}
+	configCopy := *config
+	configCopy.ModuleConfigs = moduleConfigs
+	// the top-level configurations are those of the first module
+	configCopy.Lint = moduleConfigs[0].Lint
+	return &configCopy, nil
+}
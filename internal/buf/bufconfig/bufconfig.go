@@ -17,7 +17,14 @@ package bufconfig
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
@@ -33,11 +40,45 @@ const (
 	// ExternalConfigV1Beta1FilePath is the v1beta1 file path.
 	ExternalConfigV1Beta1FilePath = "buf.yaml"
 
+	// StdinOverride is the override for ReadConfigWithOverride that reads the
+	// configuration data from stdin.
+	StdinOverride = "-"
+
 	// V1Version is the v1 version.
 	V1Version = "v1"
 
 	// V1Beta1Version is the v1beta1 version.
 	V1Beta1Version = "v1beta1"
+
+	// V2Version is the v2 version.
+	V2Version = "v2"
+)
+
+const (
+	// VisibilityPrivate is the private visibility.
+	VisibilityPrivate Visibility = iota + 1
+	// VisibilityPublic is the public visibility.
+	VisibilityPublic
+)
+
+const (
+	// ConfigSectionBuild is the build section.
+	ConfigSectionBuild ConfigSection = iota + 1
+	// ConfigSectionLint is the lint section.
+	ConfigSectionLint
+	// ConfigSectionBreaking is the breaking section.
+	ConfigSectionBreaking
+	// ConfigSectionDeps is the deps section.
+	ConfigSectionDeps
+)
+
+const (
+	// ConfigDataFormatYAML is the YAML format.
+	ConfigDataFormatYAML ConfigDataFormat = iota + 1
+	// ConfigDataFormatJSON is the JSON format.
+	ConfigDataFormatJSON
+	// ConfigDataFormatTOML is the TOML format.
+	ConfigDataFormatTOML
 )
 
 var (
@@ -45,16 +86,253 @@ var (
 	AllVersions = []string{
 		V1Beta1Version,
 		V1Version,
+		V2Version,
+	}
+
+	// AllVisibilityStrings is all visibility strings.
+	AllVisibilityStrings = []string{
+		"private",
+		"public",
+	}
+
+	stringToVisibility = map[string]Visibility{
+		"private": VisibilityPrivate,
+		"public":  VisibilityPublic,
+	}
+	visibilityToString = map[Visibility]string{
+		VisibilityPrivate: "private",
+		VisibilityPublic:  "public",
+	}
+
+	configSectionToString = map[ConfigSection]string{
+		ConfigSectionBuild:    "build",
+		ConfigSectionLint:     "lint",
+		ConfigSectionBreaking: "breaking",
+		ConfigSectionDeps:     "deps",
+	}
+
+	configDataFormatToString = map[ConfigDataFormat]string{
+		ConfigDataFormatYAML: "yaml",
+		ConfigDataFormatJSON: "json",
+		ConfigDataFormatTOML: "toml",
 	}
 )
 
+// Visibility is the visibility a module is pushed with.
+type Visibility int
+
+// String implements fmt.Stringer.
+func (v Visibility) String() string {
+	s, ok := visibilityToString[v]
+	if !ok {
+		return strconv.Itoa(int(v))
+	}
+	return s
+}
+
+// ParseVisibility parses the Visibility.
+//
+// The empty string defaults to VisibilityPrivate.
+func ParseVisibility(s string) (Visibility, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return VisibilityPrivate, nil
+	}
+	v, ok := stringToVisibility[s]
+	if ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unknown visibility: %q", s)
+}
+
+// ConfigSection is a section of the configuration file.
+type ConfigSection int
+
+// String implements fmt.Stringer.
+//
+// This is the key of the section within the configuration file.
+func (c ConfigSection) String() string {
+	s, ok := configSectionToString[c]
+	if !ok {
+		return strconv.Itoa(int(c))
+	}
+	return s
+}
+
 // Config is the user config.
+//
+// For v2, ModuleIdentity, Build, Breaking, and Lint are those of the first
+// ModuleConfig. Use ModuleConfigs to get the configuration of every module.
 type Config struct {
 	Version        string
 	ModuleIdentity bufmodule.ModuleIdentity
 	Build          *bufmodulebuild.Config
 	Breaking       *bufbreaking.Config
 	Lint           *buflint.Config
+	// ModuleConfigs are the configurations of the modules, sorted by path.
+	//
+	// This is always set. For v1beta1 and v1, this is a single ModuleConfig at the
+	// root that has the same ModuleIdentity, Build, Breaking, and Lint as the Config.
+	ModuleConfigs []*ModuleConfig
+	// DefaultVisibility is the visibility to use when creating the repository
+	// of the module without an explicit visibility.
+	//
+	// This is always set, and defaults to VisibilityPrivate.
+	DefaultVisibility Visibility
+	// DigestAlgorithm is the digest algorithm to use for new pins
+	// written to the lock file.
+	//
+	// This is always set, and defaults to bufmodule.DigestAlgorithmB1.
+	DigestAlgorithm bufmodule.DigestAlgorithm
+	// DependencyImportAliases are the import path aliases of the dependencies.
+	//
+	// This is a map from the identity string of a dependency, to the prefix that
+	// imports use, to the prefix of the files within the dependency. The files of
+	// the dependency within the target prefix can be imported with the alias prefix
+	// instead. This is only set for v1, and is nil if there are no aliases.
+	DependencyImportAliases map[string]map[string]string
+	// Parallelism is the maximum number of rules the lint and breaking checks run at once.
+	//
+	// This is also set on Lint and Breaking. If 0, runtime.GOMAXPROCS(0) is used.
+	// This is never negative.
+	Parallelism int
+	// DocumentationExempt is true if the module is exempt from the requirement
+	// to have documentation.
+	//
+	// This is only set for v1.
+	DocumentationExempt bool
+	// OutputFormat is the format lint and breaking violations are printed in.
+	//
+	// This is 0 if not set, in which case the format given on the command line, or
+	// bufanalysis.FormatText, is used. The format given on the command line always
+	// overrides this.
+	OutputFormat bufanalysis.Format
+}
+
+// Validate returns a *ValidationError listing every problem with the Config.
+//
+// This checks that a name is set if there are deps, that no two deps have the same
+// module identity, and that the build excludes do not exclude every root. Configs
+// returned from a Provider are always valid.
+func (c *Config) Validate() error {
+	return validateConfig(c)
+}
+
+// WithoutLint returns a shallow copy of the Config without a lint configuration.
+//
+// The Lint of the returned Config and of each of its ModuleConfigs is a non-nil,
+// disabled buflint.Config with no rules. The original Config is not modified.
+func (c *Config) WithoutLint() *Config {
+	return configWithoutLint(c)
+}
+
+// WithoutBreaking returns a shallow copy of the Config without a breaking configuration.
+//
+// The Breaking of the returned Config and of each of its ModuleConfigs is a non-nil,
+// disabled bufbreaking.Config with no rules. The original Config is not modified.
+func (c *Config) WithoutBreaking() *Config {
+	return configWithoutBreaking(c)
+}
+
+// ValidationError is the error returned from Config.Validate.
+type ValidationError struct {
+	// Problems are the problems with the Config, in the order of the fields of the Config.
+	Problems []*ValidationProblem
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	problemStrings := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problemStrings[i] = problem.String()
+	}
+	return "invalid config: " + strings.Join(problemStrings, "; ")
+}
+
+// ValidationProblem is a single problem with a Config.
+type ValidationProblem struct {
+	// FieldPath is the path of the field of the configuration file the problem
+	// is with, such as deps[1] or modules["proto"].name.
+	FieldPath string
+	// Message describes the problem.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (p *ValidationProblem) String() string {
+	return p.FieldPath + ": " + p.Message
+}
+
+// ConfigParseError is an error with configuration data returned from a Provider.
+//
+// This is returned, possibly wrapped, if the data cannot be unmarshalled as JSON, YAML,
+// or TOML, or if the value of a field is invalid, such as an unknown version or lint
+// rule. Use errors.As to get the ConfigParseError. Problems found by Config.Validate
+// are returned as a *ValidationError instead.
+//
+// The position and field are set where they are known, and are zero otherwise.
+type ConfigParseError struct {
+	// Line is the 1-indexed line of the problem in the data.
+	Line int
+	// Column is the 1-indexed column of the problem in the data.
+	Column int
+	// Field is the path of the field of the configuration file the problem is with,
+	// such as lint.use or modules[1].name.
+	Field string
+	// Message describes the problem, without the position.
+	Message string
+
+	err error
+}
+
+// Error implements error.
+//
+// This is the error from the decoder or the validation of the field.
+func (e *ConfigParseError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the error from the decoder or the validation of the field.
+func (e *ConfigParseError) Unwrap() error {
+	return e.err
+}
+
+// ModuleConfig is the configuration of a single module within a Config.
+type ModuleConfig struct {
+	// Path is the normalized path of the module root relative to the configuration file.
+	//
+	// This is "." for the module at the root.
+	Path           string
+	ModuleIdentity bufmodule.ModuleIdentity
+	Build          *bufmodulebuild.Config
+	Breaking       *bufbreaking.Config
+	Lint           *buflint.Config
+}
+
+// ConfigDataFormat is the format of configuration data.
+type ConfigDataFormat int
+
+// String implements fmt.Stringer.
+func (c ConfigDataFormat) String() string {
+	s, ok := configDataFormatToString[c]
+	if !ok {
+		return strconv.Itoa(int(c))
+	}
+	return s
+}
+
+// DetectConfigDataFormat returns the format of the configuration data.
+//
+// Data is JSON if its first non-whitespace character is "{". Data is TOML if its
+// first line that is not blank or a comment is a table header such as "[lint]",
+// or a key/value pair such as `version = "v1"`. Data is YAML otherwise, including
+// if the data is empty. As JSON is a subset of YAML, configuration files in a
+// bucket are read as YAML regardless of their format.
+func DetectConfigDataFormat(data []byte) ConfigDataFormat {
+	return detectConfigDataFormat(data)
 }
 
 // Provider is a provider.
@@ -62,16 +340,96 @@ type Provider interface {
 	// GetConfig gets the Config for the YAML data at ConfigFilePath.
 	//
 	// If the data is of length 0, returns the default config.
+	//
+	// For v2, every module path must exist in the readBucket. Lint baselines are
+	// read from the readBucket, every build exclude pattern must match a
+	// .proto file in the readBucket, and every lint ignore_only path must be a file
+	// or directory within a root in the readBucket.
 	GetConfig(ctx context.Context, readBucket storage.ReadBucket) (*Config, error)
-	// GetConfig gets the Config for the given JSON or YAML data.
+	// GetConfigAndData is GetConfig that also returns the data of the configuration
+	// file exactly as it was read, before environment variables were expanded.
 	//
-	// If the data is of length 0, returns the default config.
+	// If there is no configuration file, the data is nil and the default config is
+	// returned. Use DetectConfigDataFormat to determine the format of the data.
+	GetConfigAndData(ctx context.Context, readBucket storage.ReadBucket) (*Config, []byte, error)
+	// GetConfig gets the Config for the given JSON, YAML, or TOML data.
+	//
+	// The format is detected with DetectConfigDataFormat.
+	//
+	// If the data is of length 0, returns the default config. Lint baselines are
+	// not read, as there is no bucket to read them from.
 	GetConfigForData(ctx context.Context, data []byte) (*Config, error)
+	// GetConfigForBucketAndOverride gets the Config for the YAML data at ConfigFilePath
+	// deep-merged with the given JSON or YAML override data.
+	//
+	// The override is merged as follows:
+	//
+	//   - Maps such as lint and breaking are merged key by key.
+	//   - Lists such as deps and lint use and except are unioned, with the elements of the
+	//     base first and then the elements of the override that are not in the base.
+	//   - All other values are replaced by the override.
+	//   - A map with the single key "replace" replaces the base value with the value of
+	//     the key instead of merging, for example "use: {replace: [DEFAULT]}".
+	//
+	// If the override sets a version, it must be the version of the base config. If there
+	// is no config in the readBucket, the override is read as if it were the config.
+	// If the override data is empty, this is equivalent to GetConfig.
+	GetConfigForBucketAndOverride(ctx context.Context, readBucket storage.ReadBucket, overrideData []byte) (*Config, error)
 }
 
 // NewProvider returns a new Provider.
-func NewProvider(logger *zap.Logger) Provider {
-	return newProvider(logger)
+func NewProvider(logger *zap.Logger, options ...ProviderOption) Provider {
+	return newProvider(logger, options...)
+}
+
+// ProviderOption is an option for a new Provider.
+type ProviderOption func(*provider)
+
+// ProviderWithConfigCache returns a new ProviderOption that caches parsed Configs
+// keyed by the digest of the configuration data.
+//
+// At most maxEntries Configs are cached, with the least recently used Config evicted
+// first. Changed configuration data has a different digest, and is parsed again.
+// The cache is safe for concurrent use.
+//
+// The default is to not cache. If maxEntries is less than 1, this has no effect.
+func ProviderWithConfigCache(maxEntries int) ProviderOption {
+	return func(provider *provider) {
+		if maxEntries > 0 {
+			provider.configCache = newConfigCache(maxEntries)
+		}
+	}
+}
+
+// ProviderWithEnvLookup returns a new ProviderOption that expands the environment
+// variables referenced by the name and deps of configurations using the lookup.
+//
+// Both ${VAR} and $VAR are expanded, and $$ is a literal $. A reference to a variable
+// that the lookup does not find is an error. This happens before the name and deps
+// are parsed, so the variables can contain any part of the module references.
+//
+// The default is to not expand environment variables, except within ReadConfig, which
+// uses os.LookupEnv if this option is not set.
+func ProviderWithEnvLookup(lookup func(string) (string, bool)) ProviderOption {
+	return func(provider *provider) {
+		provider.envLookup = lookup
+	}
+}
+
+// ProviderWithoutV1Beta1Fallback returns a new ProviderOption that only reads
+// ExternalConfigFilePath from buckets.
+//
+// If ExternalConfigV1Beta1FilePath exists in a bucket, GetConfig, GetConfigAndData,
+// GetConfigForBucketAndOverride, and ReadConfig without an override return an error,
+// whether or not ExternalConfigFilePath also exists. This is useful to catch
+// configuration files that were left behind after migrating.
+//
+// The default is to read ExternalConfigV1Beta1FilePath if ExternalConfigFilePath
+// does not exist.
+func ProviderWithoutV1Beta1Fallback() ProviderOption {
+	return func(provider *provider) {
+		provider.withoutV1Beta1Fallback = true
+	}
 }
 
 // WriteConfig writes an initial configuration file into the bucket.
@@ -129,8 +487,40 @@ func WriteConfigWithUncomment() WriteConfigOption {
 	}
 }
 
+// WriteConfigWithVersion returns a new WriteConfigOption that sets the version of the
+// resulting configuration file.
+//
+// The version must be V1Beta1Version or V1Version. The configuration file is written to
+// ExternalConfigV1Beta1FilePath for V1Beta1Version, and ExternalConfigFilePath for V1Version.
+// If the version is not supported, WriteConfig returns an error without writing anything.
+//
+// The default is V1Version.
+func WriteConfigWithVersion(version string) WriteConfigOption {
+	return func(writeConfigOptions *writeConfigOptions) {
+		writeConfigOptions.version = version
+	}
+}
+
+// WriteConfigWithStableFormat returns a new WriteConfigOption that makes the resulting
+// configuration file byte-stable for the same options, so that it can be checked in
+// without noisy diffs.
+//
+// The deps are sorted, the lint and breaking rule ids and categories are sorted and
+// deduplicated, and the file always ends with a newline. Keys are always written in
+// the order of the fields of the external configuration.
+//
+// The default is to write the deps in the order they were given.
+func WriteConfigWithStableFormat() WriteConfigOption {
+	return func(writeConfigOptions *writeConfigOptions) {
+		writeConfigOptions.stableFormat = true
+	}
+}
+
 // ReadConfig reads the configuration from the OS or an override, if any.
 //
+// Environment variables referenced by the name and deps are expanded with os.LookupEnv,
+// unless the Provider was created with ProviderWithEnvLookup.
+//
 // Only use in CLI tools.
 func ReadConfig(
 	ctx context.Context,
@@ -146,14 +536,35 @@ func ReadConfig(
 	)
 }
 
+// ReadConfigFromFS reads the configuration from the root of the fs.FS or an override, if any.
+//
+// Only the configuration files at the root of the fs.FS are read. This is otherwise
+// equivalent to ReadConfig.
+func ReadConfigFromFS(
+	ctx context.Context,
+	provider Provider,
+	fsys fs.FS,
+	options ...ReadConfigOption,
+) (*Config, error) {
+	return readConfigFromFS(
+		ctx,
+		provider,
+		fsys,
+		options...,
+	)
+}
+
 // ReadConfigOption is an option for ReadConfig.
 type ReadConfigOption func(*readConfigOptions)
 
 // ReadConfigWithOverride sets the override.
 //
-// If override is set, this will first check if the override ends in .json or .yaml, if so,
-// this reads the file at this path and uses it. Otherwise, this assumes this is configuration
-// data in either JSON or YAML format, and unmarshals it.
+// If override is set, this will first check if the override is StdinOverride, if so, this
+// reads the configuration data from the reader set with ReadConfigWithStdin. Otherwise, this
+// checks if the override starts with http:// or https://, if so, this fetches the configuration
+// data from the URL and uses it. Otherwise, this checks if the override ends in .json, .yaml,
+// .yml, or .toml, if so, this reads the file at this path and uses it. Otherwise, this assumes
+// this is configuration data in either JSON, YAML, or TOML format, and unmarshals it.
 //
 // If no override is set, this reads ExternalConfigFilePath in the bucket.
 func ReadConfigWithOverride(override string) ReadConfigOption {
@@ -162,43 +573,426 @@ func ReadConfigWithOverride(override string) ReadConfigOption {
 	}
 }
 
-// ConfigExists checks if a configuration file exists.
-func ConfigExists(ctx context.Context, readBucket storage.ReadBucket) (bool, error) {
-	exists, err := storage.Exists(ctx, readBucket, ExternalConfigFilePath)
-	if err != nil {
-		return false, err
+// ReadConfigWithStdin returns a new ReadConfigOption that sets the reader that the
+// configuration data is read from if the override is StdinOverride.
+//
+// The data is read in full, and its format is detected with DetectConfigDataFormat.
+//
+// The default is to use os.Stdin.
+func ReadConfigWithStdin(stdin io.Reader) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.stdin = stdin
 	}
-	if exists {
-		return true, nil
+}
+
+// ReadConfigWithHTTPClient returns a new ReadConfigOption that sets the client used to
+// fetch an override that is a http:// or https:// URL.
+//
+// Timeouts are controlled by the client. Any response status other than 200 OK results
+// in an error.
+//
+// The default is to use http.DefaultClient.
+func ReadConfigWithHTTPClient(httpClient *http.Client) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.httpClient = httpClient
+	}
+}
+
+// ReadConfigWithOfflineValidation returns a new ReadConfigOption that performs all local
+// validation of the configuration after reading it.
+//
+// This checks the syntax, that all lint and breaking rule ids are known, and that all
+// dependency references are well-formed and unique. Dependencies are never resolved, and
+// the registry is never contacted, so this is suitable for hermetic environments.
+func ReadConfigWithOfflineValidation() ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.offlineValidation = true
 	}
+}
+
+// ReadConfigWithIdentitySuffix returns a new ReadConfigOption that appends the suffix to
+// the repository of the ModuleIdentity of the configuration after it is read.
+//
+// For example, the suffix "-staging" results in buf.build/acme/weather becoming
+// buf.build/acme/weather-staging. The resulting ModuleIdentity is validated.
+//
+// If the suffix is empty, or the configuration has no name, this has no effect.
+func ReadConfigWithIdentitySuffix(suffix string) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.identitySuffix = suffix
+	}
+}
+
+// ReadConfigWithMinimumVersion returns a new ReadConfigOption that returns an error if the
+// version of the configuration file is older than the given version, such as V1Version.
+//
+// The error names the configuration file and its version. If there is no configuration file,
+// the default configuration is not subject to the minimum version. Returns an error when reading
+// if the version is not one of AllVersions.
+//
+// The default is to have no minimum version.
+func ReadConfigWithMinimumVersion(version string) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.minimumVersion = version
+	}
+}
+
+// ReadConfigWithRequireConfig returns a new ReadConfigOption that returns an error if
+// there is no configuration file in the bucket, instead of using the default configuration.
+//
+// The error lists the paths that were checked, ExternalConfigFilePath and
+// ExternalConfigV1Beta1FilePath. An override is treated as an explicit configuration.
+//
+// The default is to use the default configuration if there is no configuration file.
+func ReadConfigWithRequireConfig() ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.requireConfig = true
+	}
+}
+
+// ReadConfigWithSections returns a new ReadConfigOption that only reads the given
+// sections of the configuration file.
+//
+// The sections that are not given are ignored, and are left at their defaults
+// without being validated. For v2, this applies to the sections of each module.
+// This allows callers that only need, for example, the build configuration to skip
+// parsing the lint and breaking configurations.
+//
+// The default is to read all sections.
+func ReadConfigWithSections(sections ...ConfigSection) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.sections = make(map[ConfigSection]struct{}, len(sections))
+		for _, section := range sections {
+			readConfigOptions.sections[section] = struct{}{}
+		}
+	}
+}
+
+// ReadWorkspaceConfigs reads the configuration of each of the given module directories
+// within the workspace bucket.
+//
+// The module directories must be relative to the root of the workspace bucket.
+// The returned map is keyed by the normalized module directory.
+//
+// Returns an error if any module directory does not contain a configuration file.
+func ReadWorkspaceConfigs(
+	ctx context.Context,
+	provider Provider,
+	workspaceBucket storage.ReadBucket,
+	moduleDirs []string,
+) (map[string]*Config, error) {
+	return readWorkspaceConfigs(
+		ctx,
+		provider,
+		workspaceBucket,
+		moduleDirs,
+	)
+}
+
+// ConfigExists checks if a configuration file exists.
+func ConfigExists(ctx context.Context, readBucket storage.ReadBucket) (bool, error) {
 	// If the default filename does not exist, fallback to previous versions.
-	return storage.Exists(ctx, readBucket, ExternalConfigV1Beta1FilePath)
+	return configExists(ctx, readBucket, false)
+}
+
+// ModuleRoot is a module root found by DiscoverModuleRoots.
+type ModuleRoot struct {
+	// Dir is the normalized directory of the configuration file relative to the
+	// root of the bucket.
+	//
+	// This is "." for the root of the bucket.
+	Dir string
+	// EnclosingDir is the Dir of the closest other ModuleRoot whose directory
+	// contains this ModuleRoot.
+	//
+	// This is empty if this ModuleRoot is not nested within another ModuleRoot.
+	EnclosingDir string
+}
+
+// Nested returns true if the ModuleRoot is nested within the directory of
+// another ModuleRoot.
+func (m *ModuleRoot) Nested() bool {
+	return m.EnclosingDir != ""
+}
+
+// DiscoverModuleRoots returns the ModuleRoots for all configuration files within the
+// bucket, sorted by Dir.
+//
+// Configuration files are files named ExternalConfigFilePath or ExternalConfigV1Beta1FilePath
+// in any directory of the bucket. Note that a module root nested within the directory of
+// another module root is returned as its own ModuleRoot with EnclosingDir set, and is not
+// merged into the enclosing module root.
+//
+// This is in bufconfig instead of bufmodule as bufconfig owns the configuration file
+// names, and bufconfig imports bufmodule.
+func DiscoverModuleRoots(ctx context.Context, readBucket storage.ReadBucket) ([]*ModuleRoot, error) {
+	return discoverModuleRoots(ctx, readBucket)
+}
+
+// SuggestConfigForModule returns a suggested v1 Config for an existing Module.
+//
+// The name is the name of the Module, or a placeholder if the Module is not named.
+// The dependencies are inferred from the imports of commonly used files that are not
+// part of the Module, such as google/api/annotations.proto. Imports that cannot be
+// attributed to a known module are not added as dependencies. All other settings
+// are the defaults.
+//
+// The ModuleIdentity and the DependencyModuleReferences of the returned Config can be
+// written with WriteConfigWithModuleIdentity and WriteConfigWithDependencyModuleReferences.
+func SuggestConfigForModule(ctx context.Context, module bufmodule.Module) (*Config, error) {
+	return suggestConfigForModule(ctx, module)
+}
+
+// SuggestMissingDependencies returns the references of the modules to add to the
+// dependencies of the Config to provide the imports of the Module that are not
+// provided by the Module itself, sorted by identity.
+//
+// The lookup is called for every such import other than the Well-Known Types, and
+// returns the reference of the module that provides the import, or false if none is
+// known. References to modules that are already dependencies of the Config are not
+// returned, and each module is returned at most once.
+func SuggestMissingDependencies(
+	ctx context.Context,
+	config *Config,
+	module bufmodule.Module,
+	lookup func(importPath string) (bufmodule.ModuleReference, bool, error),
+) ([]bufmodule.ModuleReference, error) {
+	return suggestMissingDependencies(ctx, config, module, lookup)
+}
+
+// AssertRoundTrip returns an error if the JSON or YAML configuration data does not
+// read as the same Config after being written back out.
+//
+// The data is read, written as YAML in the canonical form of its version, and read
+// again. The error describes the first difference between the two Configs. Rules
+// are compared by ID.
+func AssertRoundTrip(data []byte) error {
+	return assertRoundTrip(data)
+}
+
+// FindConfigsNeedingMigration returns the paths of all configuration files within the
+// bucket whose version is older than toVersion, sorted by path.
+//
+// Configuration files are files named ExternalConfigFilePath or ExternalConfigV1Beta1FilePath
+// in any directory of the bucket. Configuration files without a version are v1beta1.
+func FindConfigsNeedingMigration(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	toVersion string,
+) ([]string, error) {
+	return findConfigsNeedingMigration(ctx, readBucket, toVersion)
+}
+
+// MigrateV1Beta1ToV1 migrates the v1beta1 configuration to v1.
+//
+// Name, Deps, and all lint and breaking options are preserved. Anything that cannot be
+// mapped one-to-one is described by a MigrationNote, with build notes first, then
+// breaking, then lint:
+//
+//   - build.roots does not exist in v1. A single root is migrated by making the excludes
+//     relative to it, and the configuration file must be moved to the root. Multiple roots
+//     must be split into separate modules of a workspace.
+//   - Lint and breaking categories that do not exist in v1 are replaced by the rules they
+//     contained, and rules that do not exist in v1 are dropped.
+//
+// Comments are not part of ExternalConfigV1Beta1 and cannot be carried over. Use
+// WriteConfigWithDocumentationComments to document the migrated configuration.
+//
+// Returns error if the v1beta1 configuration is invalid.
+func MigrateV1Beta1ToV1(externalConfig ExternalConfigV1Beta1) (ExternalConfigV1, []MigrationNote, error) {
+	return migrateV1Beta1ToV1(externalConfig)
+}
+
+// MigrationNote describes part of a configuration that could not be migrated one-to-one.
+type MigrationNote struct {
+	// Field is the key the note is about, such as "lint.use".
+	Field string
+	// Message is the human-readable description of what was changed.
+	Message string
+}
+
+// String implements fmt.Stringer.
+func (m MigrationNote) String() string {
+	return m.Field + ": " + m.Message
+}
+
+// ValidateDepsNormalized returns an error if the deps of the configuration file in the
+// bucket are not sorted and de-duplicated.
+//
+// The error contains the expected deps in order.
+func ValidateDepsNormalized(ctx context.Context, readBucket storage.ReadBucket) error {
+	return validateDepsNormalized(ctx, readBucket)
+}
+
+// ValidateDependencies returns an error if any dependency of the Config is not a valid
+// module reference or, if exists is not nil, does not exist.
+//
+// exists is called once for every valid dependency, and should return false if the
+// dependency does not exist. If exists is nil, only the format of the dependencies is
+// validated. All problems are reported together.
+func ValidateDependencies(config *Config, exists func(bufmodule.ModuleReference) (bool, error)) error {
+	return validateDependencies(config, exists)
+}
+
+// ValidateModuleIdentityStrict applies stricter checks to the ModuleIdentity of the Config
+// than are applied when parsing.
+//
+// The remote must be a lowercase fully-qualified hostname, and the owner and repository must
+// only contain lowercase letters, digits, and hyphens. The owner must be at most 32 characters
+// and the repository at most 100 characters.
+//
+// If the Config has no ModuleIdentity, this returns nil.
+func ValidateModuleIdentityStrict(config *Config) error {
+	return validateModuleIdentityStrict(config)
+}
+
+// LintConfigDigest returns a hex-encoded SHA256 digest of the resolved lint
+// configuration of the Config.
+//
+// Only the lint section is considered, so Configs that differ only in their
+// name, dependencies, build, or breaking configuration produce the same digest.
+// The digest is independent of the order of rules and ignores in the original
+// configuration file.
+func LintConfigDigest(config *Config) (string, error) {
+	return lintConfigDigest(config)
+}
+
+// ConfigDigest returns a hex-encoded SHA256 digest of the entire Config.
+//
+// The lint, breaking, and build configurations are normalized before hashing,
+// so the digest is independent of the order of rules, ignores, roots, excludes,
+// and dependencies, and nil and empty values produce the same digest. The digest
+// is stable across processes and can be used as a cache key.
+func ConfigDigest(config *Config) (string, error) {
+	return configDigest(config)
+}
+
+// ConfigEqual returns true if the two Configs are equivalent.
+//
+// Two Configs are equivalent if and only if they have the same ConfigDigest.
+// Two nil Configs are equal.
+func ConfigEqual(one *Config, two *Config) bool {
+	return configEqual(one, two)
+}
+
+// AffectedFilesByConfigChange returns the FileInfos whose lint results may change
+// when the lint configuration changes from oldConfig to newConfig.
+//
+// If the rules, comment ignores, syntax excepts, message templates, allowed field number ranges,
+// or enablement change, all FileInfos are returned. Otherwise, only the FileInfos whose resolved ignores,
+// as configured with ignore and ignore_only, differ are returned.
+// The returned FileInfos are in the same order as the given FileInfos.
+func AffectedFilesByConfigChange(
+	oldConfig *Config,
+	newConfig *Config,
+	fileInfos []bufmodule.FileInfo,
+) ([]bufmodule.FileInfo, error) {
+	return affectedFilesByConfigChange(oldConfig, newConfig, fileInfos)
+}
+
+// ValidateRuleIDsExist returns an error if any lint or breaking use or except id or
+// category of the Config is not known to this version of buf for the version of the Config.
+//
+// The ids and categories are checked as configured, before they were resolved to rules.
+// All unknown ids and categories of all modules are reported together, each with the
+// closest known id or category.
+func ValidateRuleIDsExist(config *Config) error {
+	return validateRuleIDsExist(config)
+}
+
+// AggregateRuleUsage returns a map from every lint and breaking rule id enabled by any of
+// the Configs to the number of Configs that enable it.
+//
+// Rules are counted after resolution, so a rule enabled through a category counts the same as
+// a rule enabled by id, and excepted rules are not counted. Rules of a lint or breaking section
+// disabled with "enabled: false" are not counted. Returns an error if any Config is nil.
+func AggregateRuleUsage(configs ...*Config) (map[string]int, error) {
+	return aggregateRuleUsage(configs...)
+}
+
+// ValidateDocumentationRequired returns an error if the Module has no documentation at
+// DocumentationFilePath, unless the Config declares the module exempt with
+// "documentation_exempt: true".
+//
+// Documentation that only contains whitespace is treated as missing. When an exempt module
+// has no documentation, the exemption is logged so that it is never silent.
+func ValidateDocumentationRequired(logger *zap.Logger, config *Config, module bufmodule.Module) error {
+	return validateDocumentationRequired(logger, config, module)
+}
+
+// ExternalConfigJSONSchema returns a JSON Schema document for the configuration file
+// at the given version, such as V1Version.
+//
+// The schema is generated from the struct tags of the external configuration for the
+// version, and can be used by editors for completion and validation. The version field
+// is required and must be one of AllVersions, and the rule lists of the lint and
+// breaking sections only accept the rule ids and categories known at the version.
+func ExternalConfigJSONSchema(version string) ([]byte, error) {
+	return externalConfigJSONSchema(version)
 }
 
 // ExternalConfigV1Beta1 represents the on-disk representation of the Config
 // at version v1beta1.
 type ExternalConfigV1Beta1 struct {
-	Version  string                               `json:"version,omitempty" yaml:"version,omitempty"`
-	Name     string                               `json:"name,omitempty" yaml:"name,omitempty"`
-	Deps     []string                             `json:"deps,omitempty" yaml:"deps,omitempty"`
-	Build    bufmodulebuild.ExternalConfigV1Beta1 `json:"build,omitempty" yaml:"build,omitempty"`
-	Breaking bufbreaking.ExternalConfigV1Beta1    `json:"breaking,omitempty" yaml:"breaking,omitempty"`
-	Lint     buflint.ExternalConfigV1Beta1        `json:"lint,omitempty" yaml:"lint,omitempty"`
+	Version  string                               `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	Name     string                               `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Deps     []string                             `json:"deps,omitempty" yaml:"deps,omitempty" toml:"deps,omitempty"`
+	Build    bufmodulebuild.ExternalConfigV1Beta1 `json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+	Breaking bufbreaking.ExternalConfigV1Beta1    `json:"breaking,omitempty" yaml:"breaking,omitempty" toml:"breaking,omitempty"`
+	Lint     buflint.ExternalConfigV1Beta1        `json:"lint,omitempty" yaml:"lint,omitempty" toml:"lint,omitempty"`
 }
 
 // ExternalConfigV1 represents the on-disk representation of the Config
 // at version v1.
 type ExternalConfigV1 struct {
-	Version  string                          `json:"version,omitempty" yaml:"version,omitempty"`
-	Name     string                          `json:"name,omitempty" yaml:"name,omitempty"`
-	Deps     []string                        `json:"deps,omitempty" yaml:"deps,omitempty"`
-	Build    bufmodulebuild.ExternalConfigV1 `json:"build,omitempty" yaml:"build,omitempty"`
-	Breaking bufbreaking.ExternalConfigV1    `json:"breaking,omitempty" yaml:"breaking,omitempty"`
-	Lint     buflint.ExternalConfigV1        `json:"lint,omitempty" yaml:"lint,omitempty"`
+	Version           string                          `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	Name              string                          `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Deps              []string                        `json:"deps,omitempty" yaml:"deps,omitempty" toml:"deps,omitempty"`
+	Build             bufmodulebuild.ExternalConfigV1 `json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+	Breaking          bufbreaking.ExternalConfigV1    `json:"breaking,omitempty" yaml:"breaking,omitempty" toml:"breaking,omitempty"`
+	Lint              buflint.ExternalConfigV1        `json:"lint,omitempty" yaml:"lint,omitempty" toml:"lint,omitempty"`
+	DefaultVisibility string                          `json:"default_visibility,omitempty" yaml:"default_visibility,omitempty" toml:"default_visibility,omitempty"`
+	DigestAlgorithm   string                          `json:"digest_algorithm,omitempty" yaml:"digest_algorithm,omitempty" toml:"digest_algorithm,omitempty"`
+	// DepImportAliases is a map from a dependency, to the prefix that imports use,
+	// to the prefix of the files within the dependency.
+	DepImportAliases map[string]map[string]string `json:"dep_import_aliases,omitempty" yaml:"dep_import_aliases,omitempty" toml:"dep_import_aliases,omitempty"`
+	// Parallelism is the maximum number of rules the lint and breaking checks run at once.
+	//
+	// If 0, runtime.GOMAXPROCS(0) is used.
+	Parallelism int `json:"parallelism,omitempty" yaml:"parallelism,omitempty" toml:"parallelism,omitempty"`
+	// DocumentationExempt exempts the module from the requirement to have documentation.
+	DocumentationExempt bool `json:"documentation_exempt,omitempty" yaml:"documentation_exempt,omitempty" toml:"documentation_exempt,omitempty"`
+	// OutputFormat is the format lint and breaking violations are printed in, such as
+	// "text", "json", or "sarif".
+	OutputFormat string `json:"output_format,omitempty" yaml:"output_format,omitempty" toml:"output_format,omitempty"`
+}
+
+// ExternalConfigV2 represents the on-disk representation of the Config
+// at version v2.
+type ExternalConfigV2 struct {
+	Version string `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	// Modules are the modules. No two modules may have the same or overlapping paths.
+	//
+	// If empty, a single module at the root with the default configuration is used.
+	Modules []ExternalModuleConfigV2 `json:"modules,omitempty" yaml:"modules,omitempty" toml:"modules,omitempty"`
+}
+
+// ExternalModuleConfigV2 represents the on-disk representation of a single
+// module of the Config at version v2.
+type ExternalModuleConfigV2 struct {
+	// Path is the path of the module root relative to the configuration file.
+	//
+	// This is "." if unset.
+	Path     string                          `json:"path,omitempty" yaml:"path,omitempty" toml:"path,omitempty"`
+	Name     string                          `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Deps     []string                        `json:"deps,omitempty" yaml:"deps,omitempty" toml:"deps,omitempty"`
+	Build    bufmodulebuild.ExternalConfigV1 `json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+	Breaking bufbreaking.ExternalConfigV1    `json:"breaking,omitempty" yaml:"breaking,omitempty" toml:"breaking,omitempty"`
+	Lint     buflint.ExternalConfigV1        `json:"lint,omitempty" yaml:"lint,omitempty" toml:"lint,omitempty"`
 }
 
 // ExternalConfigVersion defines the subset of all config
 // file versions that is used to determine the configuration version.
 type ExternalConfigVersion struct {
-	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
 }
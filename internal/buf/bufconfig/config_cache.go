@@ -0,0 +1,83 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"container/list"
+	"sync"
+)
+
+// configCache is a concurrency-safe LRU cache of parsed Configs.
+type configCache struct {
+	maxEntries int
+	lock       sync.Mutex
+	// the front of the list is the most recently used entry
+	entries      *list.List
+	keyToElement map[string]*list.Element
+}
+
+type configCacheEntry struct {
+	key    string
+	config *Config
+}
+
+func newConfigCache(maxEntries int) *configCache {
+	return &configCache{
+		maxEntries:   maxEntries,
+		entries:      list.New(),
+		keyToElement: make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the cached Config for the key, if it exists.
+//
+// A copy is returned so that callers modifying the returned Config do not
+// modify the cached value.
+func (c *configCache) get(key string) (*Config, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.keyToElement[key]
+	if !ok {
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	return copyConfig(element.Value.(*configCacheEntry).config), true
+}
+
+func (c *configCache) put(key string, config *Config) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if element, ok := c.keyToElement[key]; ok {
+		element.Value.(*configCacheEntry).config = copyConfig(config)
+		c.entries.MoveToFront(element)
+		return
+	}
+	c.keyToElement[key] = c.entries.PushFront(
+		&configCacheEntry{
+			key:    key,
+			config: copyConfig(config),
+		},
+	)
+	for c.entries.Len() > c.maxEntries {
+		oldest := c.entries.Back()
+		c.entries.Remove(oldest)
+		delete(c.keyToElement, oldest.Value.(*configCacheEntry).key)
+	}
+}
+
+func copyConfig(config *Config) *Config {
+	configCopy := *config
+	return &configCopy
+}
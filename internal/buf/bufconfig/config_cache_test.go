@@ -0,0 +1,64 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProviderWithConfigCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := newProvider(zap.NewNop(), ProviderWithConfigCache(1))
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/foo/bar`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+	assert.Equal(t, uint64(1), provider.decodeCount)
+	// modifying the returned config does not modify the cache
+	config.ModuleIdentity = nil
+	config, err = provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+	assert.Equal(t, uint64(1), provider.decodeCount)
+
+	changedReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/foo/baz`),
+		},
+	)
+	require.NoError(t, err)
+	config, err = provider.GetConfig(ctx, changedReadBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/foo/baz", config.ModuleIdentity.IdentityString())
+	assert.Equal(t, uint64(2), provider.decodeCount)
+	// the first config was evicted as the cache only has one entry
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), provider.decodeCount)
+}
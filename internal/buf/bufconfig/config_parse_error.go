@@ -0,0 +1,199 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// decoderLineRegexp matches the errors of the YAML decoder that have a line.
+	decoderLineRegexp = regexp.MustCompile(`yaml: line (\d+): (.*)`)
+	// tomlLineRegexp matches the errors of the TOML decoder that have a line.
+	tomlLineRegexp = regexp.MustCompile(`Near line (\d+) \(last key parsed '[^']*'\): (.*)`)
+	// yamlTypeErrorRegexp matches the errors of a yaml.TypeError.
+	yamlTypeErrorRegexp = regexp.MustCompile(`^line (\d+): (.*)$`)
+	// yamlKeyMessageRegexp matches the messages of yaml.TypeErrors for a key.
+	yamlKeyMessageRegexp = regexp.MustCompile(`^(?:field (\S+) not found in type|mapping key "([^"]*)" already defined)`)
+	// jsonUnknownFieldRegexp matches the error of the JSON decoder for an unknown field.
+	jsonUnknownFieldRegexp = regexp.MustCompile(`json: unknown field "([^"]*)"`)
+	// jsonDuplicateKeyRegexp matches the error of encoding.UnmarshalJSONStrict for a duplicate key.
+	jsonDuplicateKeyRegexp = regexp.MustCompile(`line (\d+): duplicate key "([^"]*)"`)
+)
+
+// newConfigParseError returns a new ConfigParseError for the error from unmarshalling the data.
+func newConfigParseError(data []byte, err error) *ConfigParseError {
+	configParseError := &ConfigParseError{
+		Message: err.Error(),
+		err:     err,
+	}
+	if detectConfigDataFormat(data) == ConfigDataFormatJSON && configParseError.setJSONPosition(data) {
+		return configParseError
+	}
+	configParseError.setYAMLOrTOMLPosition(data)
+	return configParseError
+}
+
+// newConfigFieldError returns a new ConfigParseError for the invalid field.
+//
+// If err is nil, this returns nil.
+func newConfigFieldError(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ConfigParseError{
+		Field:   field,
+		Message: err.Error(),
+		err:     err,
+	}
+}
+
+// newModuleConfigFieldError returns a new ConfigParseError for the invalid field of
+// the v2 module at the path, with the module path in the message.
+//
+// If err is nil, this returns nil.
+func newModuleConfigFieldError(field string, path string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return newConfigFieldError(field, fmt.Errorf("module %q: %w", path, err))
+}
+
+// setJSONPosition sets the position from the errors of the JSON decoder.
+//
+// Returns false if the error is not from the JSON decoder.
+func (e *ConfigParseError) setJSONPosition(data []byte) bool {
+	var jsonSyntaxError *json.SyntaxError
+	if errors.As(e.err, &jsonSyntaxError) {
+		e.Line, e.Column = offsetToLineAndColumn(data, jsonSyntaxError.Offset)
+		e.Message = jsonSyntaxError.Error()
+		return true
+	}
+	var jsonUnmarshalTypeError *json.UnmarshalTypeError
+	if errors.As(e.err, &jsonUnmarshalTypeError) {
+		e.Line, e.Column = offsetToLineAndColumn(data, jsonUnmarshalTypeError.Offset)
+		e.Field = jsonUnmarshalTypeError.Field
+		e.Message = jsonUnmarshalTypeError.Error()
+		return true
+	}
+	errString := e.err.Error()
+	if matches := jsonUnknownFieldRegexp.FindStringSubmatch(errString); matches != nil {
+		e.Field, e.Line, e.Column = findKey(data, matches[1], 0)
+		e.Message = fmt.Sprintf("unknown field %q", matches[1])
+		return true
+	}
+	if matches := jsonDuplicateKeyRegexp.FindStringSubmatch(errString); matches != nil {
+		line, _ := strconv.Atoi(matches[1])
+		e.Field, _, e.Column = findKey(data, matches[2], line)
+		e.Line = line
+		e.Message = fmt.Sprintf("duplicate key %q", matches[2])
+		return true
+	}
+	return false
+}
+
+// setYAMLOrTOMLPosition sets the position from the errors of the YAML or TOML decoder.
+func (e *ConfigParseError) setYAMLOrTOMLPosition(data []byte) {
+	var yamlTypeError *yaml.TypeError
+	if errors.As(e.err, &yamlTypeError) && len(yamlTypeError.Errors) > 0 {
+		matches := yamlTypeErrorRegexp.FindStringSubmatch(yamlTypeError.Errors[0])
+		if matches == nil {
+			return
+		}
+		line, _ := strconv.Atoi(matches[1])
+		e.Line = line
+		e.Message = matches[2]
+		if keyMatches := yamlKeyMessageRegexp.FindStringSubmatch(matches[2]); keyMatches != nil {
+			key := keyMatches[1] + keyMatches[2]
+			e.Field, _, e.Column = findKey(data, key, line)
+		}
+		return
+	}
+	for _, lineRegexp := range []*regexp.Regexp{decoderLineRegexp, tomlLineRegexp} {
+		if matches := lineRegexp.FindStringSubmatch(e.err.Error()); matches != nil {
+			e.Line, _ = strconv.Atoi(matches[1])
+			e.Message = matches[2]
+			return
+		}
+	}
+}
+
+// findKey returns the field path, line, and column of the first mapping key with
+// the name in the YAML or JSON data.
+//
+// If line is not 0, only keys on the line are found. Returns empty values if the
+// key is not found.
+func findKey(data []byte, name string, line int) (string, int, int) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return "", 0, 0
+	}
+	return findKeyInNode(&node, "", name, line)
+}
+
+func findKeyInNode(node *yaml.Node, path string, name string, line int) (string, int, int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if field, keyLine, keyColumn := findKeyInNode(child, path, name, line); field != "" {
+				return field, keyLine, keyColumn
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if field, keyLine, keyColumn := findKeyInNode(child, fmt.Sprintf("%s[%d]", path, i), name, line); field != "" {
+				return field, keyLine, keyColumn
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			keyPath := keyNode.Value
+			if path != "" {
+				keyPath = path + "." + keyNode.Value
+			}
+			if keyNode.Value == name && (line == 0 || keyNode.Line == line) {
+				return keyPath, keyNode.Line, keyNode.Column
+			}
+			if field, keyLine, keyColumn := findKeyInNode(node.Content[i+1], keyPath, name, line); field != "" {
+				return field, keyLine, keyColumn
+			}
+		}
+	}
+	return "", 0, 0
+}
+
+// offsetToLineAndColumn returns the 1-indexed line and column of the byte before the
+// offset, which is the byte the JSON decoder stopped at.
+func offsetToLineAndColumn(data []byte, offset int64) (int, int) {
+	index := int(offset) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index > len(data) {
+		index = len(data)
+	}
+	before := data[:index]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := index - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,159 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConfigParseError(t *testing.T) {
+	t.Parallel()
+	testConfigParseError(
+		t,
+		"version: v1\nlint:\n  use:\n    - DEFAULT\n  excepts:\n    - FOO\n",
+		&ConfigParseError{
+			Line:    5,
+			Column:  3,
+			Field:   "lint.excepts",
+			Message: "field excepts not found in type buflint.ExternalConfigV1",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v1\nlint:\n  use:\n    - DEFAULT\nlint:\n  use:\n    - BASIC\n",
+		&ConfigParseError{
+			Line:    5,
+			Column:  1,
+			Field:   "lint",
+			Message: `mapping key "lint" already defined at line 2`,
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v1\nlint: [\n",
+		&ConfigParseError{
+			Line:    2,
+			Message: "did not find expected node content",
+		},
+	)
+	testConfigParseError(
+		t,
+		"{\n  \"version\": \"v1\",\n  \"lint\": {\n    \"excepts\": []\n  }\n}",
+		&ConfigParseError{
+			Line:    4,
+			Column:  5,
+			Field:   "lint.excepts",
+			Message: `unknown field "excepts"`,
+		},
+	)
+	testConfigParseError(
+		t,
+		"{\n  \"version\": \"v1\",\n  \"lint\": {\n    \"use\": \"DEFAULT\"\n  }\n}",
+		&ConfigParseError{
+			Line:    4,
+			Column:  20,
+			Field:   "lint.use",
+			Message: "json: cannot unmarshal string into Go struct field ExternalConfigV1.lint.use of type []string",
+		},
+	)
+	testConfigParseError(
+		t,
+		"{\n  \"version\": \"v1\",\n  \"name\" \"foo\"\n}",
+		&ConfigParseError{
+			Line:    3,
+			Column:  10,
+			Message: "invalid character '\"' after object key",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version = \"v1\"\n[lint]\nuse = [\"DEFAULT\"\n",
+		&ConfigParseError{
+			Line:    3,
+			Message: `expected a comma or array terminator ']', but got '\x00' instead`,
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v3\n",
+		&ConfigParseError{
+			Field:   "version",
+			Message: `Configuration data has an invalid "version: v3" set. Please add "version: v1". See https://docs.buf.build/faq for more details`,
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v1\nname: foo\n",
+		&ConfigParseError{
+			Field: "name",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v1\nlint:\n  use:\n    - NOT_A_RULE\n",
+		&ConfigParseError{
+			Field: "lint",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v2\nmodules:\n  - path: a\n  - path: b\n    lint:\n      use:\n        - NOT_A_RULE\n",
+		&ConfigParseError{
+			Field: "modules[1].lint",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v2\nmodules:\n  - path: a\n    breaking:\n      use:\n        - NOT_A_RULE\n",
+		&ConfigParseError{
+			Field: "modules[0].breaking",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v2\nmodules:\n  - path: a\n    name: foo\n",
+		&ConfigParseError{
+			Field: "modules[0].name",
+		},
+	)
+	testConfigParseError(
+		t,
+		"version: v2\nmodules:\n  - path: ../a\n",
+		&ConfigParseError{
+			Field: "modules[0].path",
+		},
+	)
+}
+
+func testConfigParseError(t *testing.T, data string, expectedConfigParseError *ConfigParseError) {
+	_, err := NewProvider(zap.NewNop()).GetConfigForData(context.Background(), []byte(data))
+	require.Error(t, err)
+	configParseError := &ConfigParseError{}
+	require.True(t, errors.As(err, &configParseError), err.Error())
+	assert.Equal(t, expectedConfigParseError.Line, configParseError.Line, data)
+	assert.Equal(t, expectedConfigParseError.Column, configParseError.Column, data)
+	assert.Equal(t, expectedConfigParseError.Field, configParseError.Field, data)
+	if expectedConfigParseError.Message != "" {
+		assert.Equal(t, expectedConfigParseError.Message, configParseError.Message, data)
+	}
+	// the error is the same as the error of the decoder or field
+	assert.Equal(t, configParseError.Unwrap().Error(), configParseError.Error())
+}
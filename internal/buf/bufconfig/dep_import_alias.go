@@ -0,0 +1,123 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+)
+
+// parseDependencyImportAliases parses and validates the dep_import_aliases of a configuration file.
+//
+// The returned map is from the identity string of the dependency, to the prefix imports use,
+// to the prefix of the files within the dependency.
+func parseDependencyImportAliases(
+	externalDependencyImportAliases map[string]map[string]string,
+	dependencyModuleReferences []bufmodule.ModuleReference,
+) (map[string]map[string]string, error) {
+	if len(externalDependencyImportAliases) == 0 {
+		return nil, nil
+	}
+	dependencyIdentityStrings := make(map[string]struct{}, len(dependencyModuleReferences))
+	for _, dependencyModuleReference := range dependencyModuleReferences {
+		dependencyIdentityStrings[dependencyModuleReference.IdentityString()] = struct{}{}
+	}
+	// sort so that errors are deterministic
+	externalIdentities := make([]string, 0, len(externalDependencyImportAliases))
+	for externalIdentity := range externalDependencyImportAliases {
+		externalIdentities = append(externalIdentities, externalIdentity)
+	}
+	sort.Strings(externalIdentities)
+	dependencyImportAliases := make(map[string]map[string]string, len(externalDependencyImportAliases))
+	aliasPrefixToIdentityString := make(map[string]string)
+	for _, externalIdentity := range externalIdentities {
+		moduleIdentity, err := bufmodule.ModuleIdentityForString(externalIdentity)
+		if err != nil {
+			return nil, fmt.Errorf("dep_import_aliases: %w", err)
+		}
+		identityString := moduleIdentity.IdentityString()
+		if _, ok := dependencyIdentityStrings[identityString]; !ok {
+			return nil, fmt.Errorf("dep_import_aliases: %q is not a dependency", identityString)
+		}
+		if _, ok := dependencyImportAliases[identityString]; ok {
+			return nil, fmt.Errorf("dep_import_aliases: duplicate dependency %q", identityString)
+		}
+		aliases, err := parseDependencyImportAliasesForIdentity(
+			identityString,
+			externalDependencyImportAliases[externalIdentity],
+		)
+		if err != nil {
+			return nil, err
+		}
+		for aliasPrefix := range aliases {
+			if otherIdentityString, ok := aliasPrefixToIdentityString[aliasPrefix]; ok {
+				return nil, fmt.Errorf("dep_import_aliases: prefix %q is an alias for both %q and %q", aliasPrefix, otherIdentityString, identityString)
+			}
+			aliasPrefixToIdentityString[aliasPrefix] = identityString
+		}
+		dependencyImportAliases[identityString] = aliases
+	}
+	return dependencyImportAliases, nil
+}
+
+func parseDependencyImportAliasesForIdentity(
+	identityString string,
+	externalAliases map[string]string,
+) (map[string]string, error) {
+	externalAliasPrefixes := make([]string, 0, len(externalAliases))
+	for externalAliasPrefix := range externalAliases {
+		externalAliasPrefixes = append(externalAliasPrefixes, externalAliasPrefix)
+	}
+	sort.Strings(externalAliasPrefixes)
+	aliases := make(map[string]string, len(externalAliases))
+	targetPrefixToAliasPrefix := make(map[string]string, len(externalAliases))
+	aliasPrefixes := make([]string, 0, len(externalAliases))
+	for _, externalAliasPrefix := range externalAliasPrefixes {
+		aliasPrefix, err := normalpath.NormalizeAndValidate(externalAliasPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("dep_import_aliases for %q: alias prefix %q: %w", identityString, externalAliasPrefix, err)
+		}
+		targetPrefix, err := normalpath.NormalizeAndValidate(externalAliases[externalAliasPrefix])
+		if err != nil {
+			return nil, fmt.Errorf("dep_import_aliases for %q: target prefix %q: %w", identityString, externalAliases[externalAliasPrefix], err)
+		}
+		if _, ok := aliases[aliasPrefix]; ok {
+			return nil, fmt.Errorf("dep_import_aliases for %q: duplicate alias prefix %q", identityString, aliasPrefix)
+		}
+		if otherAliasPrefix, ok := targetPrefixToAliasPrefix[targetPrefix]; ok {
+			return nil, fmt.Errorf("dep_import_aliases for %q: target prefix %q is the target of both %q and %q", identityString, targetPrefix, otherAliasPrefix, aliasPrefix)
+		}
+		aliases[aliasPrefix] = targetPrefix
+		targetPrefixToAliasPrefix[targetPrefix] = aliasPrefix
+		aliasPrefixes = append(aliasPrefixes, aliasPrefix)
+	}
+	// an alias whose target prefix is the alias prefix of another alias chains to it, and
+	// a chain that leads back to where it started can never be resolved
+	//
+	// alias and target prefixes are both unique, so every chain is either a line or a cycle
+	for _, aliasPrefix := range aliasPrefixes {
+		chain := []string{aliasPrefix}
+		for next, ok := aliases[aliasPrefix]; ok && len(chain) <= len(aliases); next, ok = aliases[next] {
+			chain = append(chain, next)
+			if next == aliasPrefix {
+				return nil, fmt.Errorf("dep_import_aliases for %q: alias cycle %v", identityString, chain)
+			}
+		}
+	}
+	return aliases, nil
+}
@@ -0,0 +1,367 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+func lintConfigDigest(config *Config) (string, error) {
+	if config == nil || config.Lint == nil {
+		return "", errors.New("config has no lint configuration")
+	}
+	hash := sha256.New()
+	if err := writeLintConfigDigest(hash, config.Lint); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func configDigest(config *Config) (string, error) {
+	if config == nil {
+		return "", errors.New("config is nil")
+	}
+	hash := sha256.New()
+	if err := writeConfigDigest(hash, config); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func configEqual(one *Config, two *Config) bool {
+	if one == nil || two == nil {
+		return one == nil && two == nil
+	}
+	oneBuffer := bytes.NewBuffer(nil)
+	twoBuffer := bytes.NewBuffer(nil)
+	if err := writeConfigDigest(oneBuffer, one); err != nil {
+		return false
+	}
+	if err := writeConfigDigest(twoBuffer, two); err != nil {
+		return false
+	}
+	return bytes.Equal(oneBuffer.Bytes(), twoBuffer.Bytes())
+}
+
+// writeConfigDigest writes the normalized representation of the Config.
+//
+// Rules, ignores, roots, excludes, and dependencies are sorted, and nil and
+// empty slices and maps are written the same, so that equivalent Configs
+// always have the same representation.
+func writeConfigDigest(writer io.Writer, config *Config) error {
+	if err := writeDigestLine(writer, "version", config.Version); err != nil {
+		return err
+	}
+	if err := writeModuleSectionsDigest(writer, config.ModuleIdentity, config.Build, config.Breaking, config.Lint); err != nil {
+		return err
+	}
+	for _, moduleConfig := range config.ModuleConfigs {
+		if err := writeDigestLine(writer, "module", moduleConfig.Path); err != nil {
+			return err
+		}
+		if err := writeModuleSectionsDigest(
+			writer,
+			moduleConfig.ModuleIdentity,
+			moduleConfig.Build,
+			moduleConfig.Breaking,
+			moduleConfig.Lint,
+		); err != nil {
+			return err
+		}
+	}
+	if err := writeDigestLine(writer, "default_visibility", config.DefaultVisibility.String()); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "digest_algorithm", config.DigestAlgorithm.String()); err != nil {
+		return err
+	}
+	dependencyIdentities := make([]string, 0, len(config.DependencyImportAliases))
+	for dependencyIdentity := range config.DependencyImportAliases {
+		dependencyIdentities = append(dependencyIdentities, dependencyIdentity)
+	}
+	sort.Strings(dependencyIdentities)
+	for _, dependencyIdentity := range dependencyIdentities {
+		aliases := config.DependencyImportAliases[dependencyIdentity]
+		aliasPrefixes := make([]string, 0, len(aliases))
+		for aliasPrefix := range aliases {
+			aliasPrefixes = append(aliasPrefixes, aliasPrefix)
+		}
+		sort.Strings(aliasPrefixes)
+		for _, aliasPrefix := range aliasPrefixes {
+			if err := writeDigestLine(writer, "dependency_import_alias", dependencyIdentity, aliasPrefix, aliases[aliasPrefix]); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writeDigestLine(writer, "parallelism", strconv.Itoa(config.Parallelism)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "documentation_exempt", strconv.FormatBool(config.DocumentationExempt)); err != nil {
+		return err
+	}
+	return writeDigestLine(writer, "output_format", strconv.Itoa(int(config.OutputFormat)))
+}
+
+func writeModuleSectionsDigest(
+	writer io.Writer,
+	moduleIdentity bufmodule.ModuleIdentity,
+	buildConfig *bufmodulebuild.Config,
+	breakingConfig *bufbreaking.Config,
+	lintConfig *buflint.Config,
+) error {
+	if err := writeDigestLine(writer, "name", moduleIdentityString(moduleIdentity)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "build", strconv.FormatBool(buildConfig != nil)); err != nil {
+		return err
+	}
+	if buildConfig != nil {
+		if err := writeBuildConfigDigest(writer, buildConfig); err != nil {
+			return err
+		}
+	}
+	if err := writeDigestLine(writer, "breaking", strconv.FormatBool(breakingConfig != nil)); err != nil {
+		return err
+	}
+	if breakingConfig != nil {
+		if err := writeBreakingConfigDigest(writer, breakingConfig); err != nil {
+			return err
+		}
+	}
+	if err := writeDigestLine(writer, "lint", strconv.FormatBool(lintConfig != nil)); err != nil {
+		return err
+	}
+	if lintConfig != nil {
+		if err := writeLintConfigDigest(writer, lintConfig); err != nil {
+			return err
+		}
+		if err := writeLintConfigExtraDigest(writer, lintConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBuildConfigDigest(writer io.Writer, buildConfig *bufmodulebuild.Config) error {
+	roots := make([]string, 0, len(buildConfig.RootToExcludes))
+	for root := range buildConfig.RootToExcludes {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	for _, root := range roots {
+		if err := writeDigestLine(writer, "root", root); err != nil {
+			return err
+		}
+		for _, exclude := range stringutil.SliceToUniqueSortedSlice(buildConfig.RootToExcludes[root]) {
+			if err := writeDigestLine(writer, "exclude", root, exclude); err != nil {
+				return err
+			}
+		}
+	}
+	for _, dependency := range stringutil.SliceToUniqueSortedSlice(
+		moduleReferenceStrings(buildConfig.DependencyModuleReferences),
+	) {
+		if err := writeDigestLine(writer, "dep", dependency); err != nil {
+			return err
+		}
+	}
+	pathRulePrefixes := make([]string, 0, len(buildConfig.PathRules))
+	for prefix := range buildConfig.PathRules {
+		pathRulePrefixes = append(pathRulePrefixes, prefix)
+	}
+	sort.Strings(pathRulePrefixes)
+	for _, prefix := range pathRulePrefixes {
+		if err := writeDigestLine(writer, "path_rule", prefix, buildConfig.PathRules[prefix]); err != nil {
+			return err
+		}
+	}
+	for _, excludeGlob := range stringutil.SliceToUniqueSortedSlice(buildConfig.ExcludeGlobs) {
+		if err := writeDigestLine(writer, "exclude_glob", excludeGlob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeBreakingConfigDigest(writer io.Writer, breakingConfig *bufbreaking.Config) error {
+	for _, ruleID := range breakingConfigRuleIDs(breakingConfig) {
+		if err := writeDigestLine(writer, "rule", ruleID); err != nil {
+			return err
+		}
+	}
+	if err := writeIgnoresDigest(writer, breakingConfig.IgnoreRootPaths, breakingConfig.IgnoreIDToRootPaths); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "ignore_unstable_packages", strconv.FormatBool(breakingConfig.IgnoreUnstablePackages)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "disabled", strconv.FormatBool(breakingConfig.Disabled)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "granularity", breakingConfig.Granularity.String()); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "against", breakingConfig.Against); err != nil {
+		return err
+	}
+	return writeDigestLine(writer, "parallelism", strconv.Itoa(breakingConfig.Parallelism))
+}
+
+func writeLintConfigDigest(writer io.Writer, lintConfig *buflint.Config) error {
+	ruleIDs := make([]string, 0, len(lintConfig.Rules))
+	for _, rule := range lintConfig.Rules {
+		ruleIDs = append(ruleIDs, rule.ID())
+	}
+	sort.Strings(ruleIDs)
+	for _, ruleID := range ruleIDs {
+		if err := writeDigestLine(writer, "rule", ruleID); err != nil {
+			return err
+		}
+	}
+	if err := writeIgnoresDigest(writer, lintConfig.IgnoreRootPaths, lintConfig.IgnoreIDToRootPaths); err != nil {
+		return err
+	}
+	syntaxes := make([]protosource.Syntax, 0, len(lintConfig.IgnoreSyntaxToIDs))
+	for syntax := range lintConfig.IgnoreSyntaxToIDs {
+		syntaxes = append(syntaxes, syntax)
+	}
+	sort.Slice(syntaxes, func(i int, j int) bool { return syntaxes[i] < syntaxes[j] })
+	for _, syntax := range syntaxes {
+		for _, ignoreID := range stringutil.MapToSortedSlice(lintConfig.IgnoreSyntaxToIDs[syntax]) {
+			if err := writeDigestLine(writer, "ignore_syntax", syntax.String(), ignoreID); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writeDigestLine(writer, "allow_comment_ignores", strconv.FormatBool(lintConfig.AllowCommentIgnores)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "disabled", strconv.FormatBool(lintConfig.Disabled)); err != nil {
+		return err
+	}
+	messageTemplateRuleIDs := make([]string, 0, len(lintConfig.MessageTemplates))
+	for ruleID := range lintConfig.MessageTemplates {
+		messageTemplateRuleIDs = append(messageTemplateRuleIDs, ruleID)
+	}
+	sort.Strings(messageTemplateRuleIDs)
+	for _, ruleID := range messageTemplateRuleIDs {
+		if err := writeDigestLine(writer, "message_template", ruleID, lintConfig.MessageTemplates[ruleID]); err != nil {
+			return err
+		}
+	}
+	for _, allowedFieldNumberRange := range lintConfig.AllowedFieldNumberRanges {
+		if err := writeDigestLine(
+			writer,
+			"allowed_field_number_range",
+			strconv.Itoa(int(allowedFieldNumberRange.Start)),
+			strconv.Itoa(int(allowedFieldNumberRange.End)),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLintConfigExtraDigest writes the fields of the lint configuration that
+// are not part of LintConfigDigest.
+func writeLintConfigExtraDigest(writer io.Writer, lintConfig *buflint.Config) error {
+	if err := writeDigestLine(writer, "enum_value_prefix", lintConfig.EnumValuePrefix); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "parallelism", strconv.Itoa(lintConfig.Parallelism)); err != nil {
+		return err
+	}
+	if err := writeDigestLine(writer, "baseline_path", lintConfig.BaselinePath); err != nil {
+		return err
+	}
+	baseline := make([]buflint.BaselineEntry, len(lintConfig.Baseline))
+	copy(baseline, lintConfig.Baseline)
+	sort.Slice(baseline, func(i int, j int) bool {
+		one := baseline[i]
+		two := baseline[j]
+		if one.Path != two.Path {
+			return one.Path < two.Path
+		}
+		if one.RuleID != two.RuleID {
+			return one.RuleID < two.RuleID
+		}
+		if one.StartLine != two.StartLine {
+			return one.StartLine < two.StartLine
+		}
+		return one.StartColumn < two.StartColumn
+	})
+	for _, baselineEntry := range baseline {
+		if err := writeDigestLine(
+			writer,
+			"baseline",
+			baselineEntry.Path,
+			baselineEntry.RuleID,
+			strconv.Itoa(baselineEntry.StartLine),
+			strconv.Itoa(baselineEntry.StartColumn),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeIgnoresDigest(
+	writer io.Writer,
+	ignoreRootPaths map[string]struct{},
+	ignoreIDToRootPaths map[string]map[string]struct{},
+) error {
+	for _, ignoreRootPath := range stringutil.MapToSortedSlice(ignoreRootPaths) {
+		if err := writeDigestLine(writer, "ignore", ignoreRootPath); err != nil {
+			return err
+		}
+	}
+	ignoreIDs := make([]string, 0, len(ignoreIDToRootPaths))
+	for ignoreID := range ignoreIDToRootPaths {
+		ignoreIDs = append(ignoreIDs, ignoreID)
+	}
+	sort.Strings(ignoreIDs)
+	for _, ignoreID := range ignoreIDs {
+		for _, ignoreRootPath := range stringutil.MapToSortedSlice(ignoreIDToRootPaths[ignoreID]) {
+			if err := writeDigestLine(writer, "ignore_only", ignoreID, ignoreRootPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeDigestLine writes the key and values as a single line, with each
+// element quoted so that values containing separators cannot collide.
+func writeDigestLine(writer io.Writer, key string, values ...string) error {
+	line := strconv.Quote(key)
+	for _, value := range values {
+		line += " " + strconv.Quote(value)
+	}
+	_, err := writer.Write([]byte(line + "\n"))
+	return err
+}
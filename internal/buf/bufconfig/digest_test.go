@@ -0,0 +1,166 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLintConfigDigestIgnoresDeps(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config1, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+lint:
+  use:
+    - DEFAULT
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX
+  ignore:
+    - a
+    - b`))
+	require.NoError(t, err)
+	config2, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/other
+breaking:
+  use:
+    - WIRE
+lint:
+  ignore:
+    - b
+    - a
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX
+  use:
+    - DEFAULT`))
+	require.NoError(t, err)
+	digest1, err := LintConfigDigest(config1)
+	require.NoError(t, err)
+	digest2, err := LintConfigDigest(config2)
+	require.NoError(t, err)
+	assert.Equal(t, digest1, digest2)
+
+	config3, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+lint:
+  use:
+    - DEFAULT`))
+	require.NoError(t, err)
+	digest3, err := LintConfigDigest(config3)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest1, digest3)
+}
+
+func TestConfigDigestAndEqual(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config1, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+  - buf.build/foo/qux
+build:
+  excludes:
+    - a
+    - b
+breaking:
+  use:
+    - FILE
+  ignore:
+    - c
+    - d
+lint:
+  use:
+    - DEFAULT
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX`))
+	require.NoError(t, err)
+	config2, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/qux
+  - buf.build/foo/baz
+build:
+  excludes:
+    - b
+    - a
+breaking:
+  ignore:
+    - d
+    - c
+  use:
+    - FILE
+lint:
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX
+  use:
+    - DEFAULT`))
+	require.NoError(t, err)
+	digest1, err := ConfigDigest(config1)
+	require.NoError(t, err)
+	digest2, err := ConfigDigest(config2)
+	require.NoError(t, err)
+	assert.Equal(t, digest1, digest2)
+	assert.True(t, ConfigEqual(config1, config2))
+
+	// nil and empty are equivalent
+	config2.Lint.MessageTemplates = map[string]string{}
+	config2.Build.ExcludeGlobs = []string{}
+	assert.True(t, ConfigEqual(config1, config2))
+
+	config3, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+  - buf.build/foo/qux
+build:
+  excludes:
+    - a
+    - b
+breaking:
+  use:
+    - WIRE
+  ignore:
+    - c
+    - d
+lint:
+  use:
+    - DEFAULT
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX`))
+	require.NoError(t, err)
+	digest3, err := ConfigDigest(config3)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest1, digest3)
+	assert.False(t, ConfigEqual(config1, config3))
+
+	assert.True(t, ConfigEqual(nil, nil))
+	assert.False(t, ConfigEqual(config1, nil))
+	_, err = ConfigDigest(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+)
+
+func discoverModuleRoots(ctx context.Context, readBucket storage.ReadBucket) ([]*ModuleRoot, error) {
+	dirMap := make(map[string]struct{})
+	if err := walkConfigFiles(
+		ctx,
+		readBucket,
+		func(objectInfo storage.ObjectInfo) error {
+			// a directory with both configuration files is a single module root
+			dirMap[normalpath.Dir(objectInfo.Path())] = struct{}{}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0, len(dirMap))
+	for dir := range dirMap {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	moduleRoots := make([]*ModuleRoot, len(dirs))
+	for i, dir := range dirs {
+		moduleRoots[i] = &ModuleRoot{
+			Dir:          dir,
+			EnclosingDir: getEnclosingDir(dir, dirMap),
+		}
+	}
+	return moduleRoots, nil
+}
+
+// getEnclosingDir returns the closest parent directory of dir within dirMap.
+//
+// Returns empty if there is no such directory.
+func getEnclosingDir(dir string, dirMap map[string]struct{}) string {
+	for dir != "." {
+		dir = normalpath.Dir(dir)
+		if _, ok := dirMap[dir]; ok {
+			return dir
+		}
+	}
+	return ""
+}
+
+// walkConfigFiles calls f for every file named ExternalConfigFilePath or
+// ExternalConfigV1Beta1FilePath in any directory of the bucket.
+func walkConfigFiles(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	f func(storage.ObjectInfo) error,
+) error {
+	return readBucket.Walk(
+		ctx,
+		"",
+		func(objectInfo storage.ObjectInfo) error {
+			switch normalpath.Base(objectInfo.Path()) {
+			case ExternalConfigFilePath, ExternalConfigV1Beta1FilePath:
+				return f(objectInfo)
+			default:
+				return nil
+			}
+		},
+	)
+}
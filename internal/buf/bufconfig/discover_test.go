@@ -0,0 +1,86 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverModuleRoots(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"paymentapis/buf.mod":           []byte(`version: v1`),
+			"paymentapis/acme/a.proto":      []byte(`syntax = "proto3";`),
+			"petapis/buf.yaml":              []byte(`version: v1beta1`),
+			"petapis/nested/buf.mod":        []byte(`version: v1`),
+			"petapis/nested/acme/b.proto":   []byte(`syntax = "proto3";`),
+			"other/foo.proto":               []byte(`syntax = "proto3";`),
+			"paymentapis/buf.yaml":          []byte(`version: v1beta1`),
+			"petapis/acme/pet/v1/c.proto":   []byte(`syntax = "proto3";`),
+			"petapis/acme/pet/v1/README.md": []byte(`# pets`),
+		},
+	)
+	require.NoError(t, err)
+	moduleRoots, err := DiscoverModuleRoots(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]*ModuleRoot{
+			{
+				Dir: "paymentapis",
+			},
+			{
+				Dir: "petapis",
+			},
+			{
+				Dir:          "petapis/nested",
+				EnclosingDir: "petapis",
+			},
+		},
+		moduleRoots,
+	)
+	assert.False(t, moduleRoots[1].Nested())
+	assert.True(t, moduleRoots[2].Nested())
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			"buf.mod":     []byte(`version: v1`),
+			"a/b/buf.mod": []byte(`version: v1`),
+		},
+	)
+	require.NoError(t, err)
+	moduleRoots, err = DiscoverModuleRoots(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]*ModuleRoot{
+			{
+				Dir: ".",
+			},
+			{
+				Dir:          "a/b",
+				EnclosingDir: ".",
+			},
+		},
+		moduleRoots,
+	)
+}
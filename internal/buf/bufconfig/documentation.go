@@ -0,0 +1,38 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"go.uber.org/zap"
+)
+
+func validateDocumentationRequired(logger *zap.Logger, config *Config, module bufmodule.Module) error {
+	if strings.TrimSpace(module.Documentation()) != "" {
+		return nil
+	}
+	if config.DocumentationExempt {
+		moduleName := "module"
+		if config.ModuleIdentity != nil {
+			moduleName = config.ModuleIdentity.IdentityString()
+		}
+		logger.Sugar().Infof("%s has no %s but is exempt from the documentation requirement with documentation_exempt", moduleName, bufmodule.DocumentationFilePath)
+		return nil
+	}
+	return errors.New("module has no documentation, add a " + bufmodule.DocumentationFilePath + ` or set "documentation_exempt: true"`)
+}
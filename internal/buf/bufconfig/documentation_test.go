@@ -0,0 +1,75 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestValidateDocumentationRequired(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/internal`))
+	require.NoError(t, err)
+	assert.Error(t, ValidateDocumentationRequired(zap.NewNop(), config, module))
+
+	exemptConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/internal
+documentation_exempt: true`))
+	require.NoError(t, err)
+	assert.True(t, exemptConfig.DocumentationExempt)
+	core, observedLogs := observer.New(zapcore.InfoLevel)
+	assert.NoError(t, ValidateDocumentationRequired(zap.New(core), exemptConfig, module))
+	require.Equal(t, 1, observedLogs.Len())
+	assert.Contains(t, observedLogs.All()[0].Message, "buf.build/acme/internal")
+
+	documentedModule, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+			},
+			Documentation: "# Internal",
+		},
+	)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateDocumentationRequired(zap.NewNop(), config, documentedModule))
+}
@@ -0,0 +1,164 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/storage"
+)
+
+// newOSEnvLookupProvider returns a Provider that expands environment variables
+// with os.LookupEnv if the given Provider was created by this package without
+// ProviderWithEnvLookup. Otherwise, the given Provider is returned.
+func newOSEnvLookupProvider(p Provider) Provider {
+	packageProvider, ok := p.(*provider)
+	if !ok || packageProvider.envLookup != nil {
+		return p
+	}
+	return &envLookupProvider{
+		provider:  packageProvider,
+		envLookup: os.LookupEnv,
+	}
+}
+
+type envLookupProvider struct {
+	provider  *provider
+	envLookup func(string) (string, bool)
+}
+
+func (e *envLookupProvider) GetConfig(ctx context.Context, readBucket storage.ReadBucket) (*Config, error) {
+	return e.provider.getConfig(ctx, readBucket, e.envLookup)
+}
+
+func (e *envLookupProvider) GetConfigAndData(ctx context.Context, readBucket storage.ReadBucket) (*Config, []byte, error) {
+	return e.provider.getConfigAndData(ctx, readBucket, e.envLookup)
+}
+
+func (e *envLookupProvider) GetConfigForData(ctx context.Context, data []byte) (*Config, error) {
+	return e.provider.getConfigForData(ctx, data, e.envLookup)
+}
+
+func (e *envLookupProvider) GetConfigForBucketAndOverride(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	overrideData []byte,
+) (*Config, error) {
+	return e.provider.getConfigForBucketAndOverride(ctx, readBucket, overrideData, e.envLookup)
+}
+
+// expandNameAndDepsEnv expands the environment variables referenced by the name
+// and deps in place, with the field paths of errors prefixed by fieldPathPrefix.
+//
+// If envLookup is nil, this does nothing.
+func expandNameAndDepsEnv(
+	fieldPathPrefix string,
+	name *string,
+	deps []string,
+	envLookup func(string) (string, bool),
+) error {
+	if envLookup == nil {
+		return nil
+	}
+	expandedName, err := expandEnv(*name, envLookup)
+	if err != nil {
+		return fmt.Errorf("%sname: %w", fieldPathPrefix, err)
+	}
+	*name = expandedName
+	for i, dep := range deps {
+		expandedDep, err := expandEnv(dep, envLookup)
+		if err != nil {
+			return fmt.Errorf("%sdeps[%d]: %w", fieldPathPrefix, i, err)
+		}
+		deps[i] = expandedDep
+	}
+	return nil
+}
+
+// expandEnv replaces ${VAR} and $VAR in s with the value of the environment
+// variable VAR, and $$ with $.
+//
+// An error is returned if a variable is not set, or if a $ is not followed
+// by a variable name or another $.
+func expandEnv(s string, envLookup func(string) (string, bool)) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var builder strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			builder.WriteByte(s[i])
+			continue
+		}
+		if i+1 == len(s) {
+			return "", fmt.Errorf(`%q ends with "$", use "$$" for a literal "$"`, s)
+		}
+		var name string
+		switch next := s[i+1]; {
+		case next == '$':
+			builder.WriteByte('$')
+			i++
+			continue
+		case next == '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf(`%q has an unclosed "${"`, s)
+			}
+			name = s[i+2 : i+2+end]
+			if !isEnvName(name) {
+				return "", fmt.Errorf("%q references an invalid environment variable name %q", s, name)
+			}
+			i += 2 + end
+		case isEnvNameStart(next):
+			end := i + 2
+			for end < len(s) && isEnvNameChar(s[end]) {
+				end++
+			}
+			name = s[i+1 : end]
+			i = end - 1
+		default:
+			return "", fmt.Errorf(`%q has a "$" that is not followed by a variable name, use "$$" for a literal "$"`, s)
+		}
+		value, ok := envLookup(name)
+		if !ok {
+			return "", fmt.Errorf("%q references environment variable %q, which is not set", s, name)
+		}
+		builder.WriteString(value)
+	}
+	return builder.String(), nil
+}
+
+func isEnvName(name string) bool {
+	if name == "" || !isEnvNameStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isEnvNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isEnvNameStart(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isEnvNameChar(c byte) bool {
+	return isEnvNameStart(c) || ('0' <= c && c <= '9')
+}
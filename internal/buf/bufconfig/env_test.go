@@ -0,0 +1,123 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Parallel()
+	envLookup := newTestEnvLookup(map[string]string{
+		"BUF_REGISTRY": "buf.example.com",
+		"OWNER":        "acme",
+	})
+	for input, expected := range map[string]string{
+		"buf.build/acme/payments":          "buf.build/acme/payments",
+		"${BUF_REGISTRY}/acme/payments":    "buf.example.com/acme/payments",
+		"$BUF_REGISTRY/$OWNER/payments":    "buf.example.com/acme/payments",
+		"${BUF_REGISTRY}/${OWNER}payments": "buf.example.com/acmepayments",
+		"buf.build/acme/pay$$ments":        "buf.build/acme/pay$ments",
+		"$$BUF_REGISTRY":                   "$BUF_REGISTRY",
+	} {
+		actual, err := expandEnv(input, envLookup)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, actual, input)
+	}
+	for _, input := range []string{
+		"${UNSET}/acme/payments",
+		"$UNSET/acme/payments",
+		"${BUF_REGISTRY/acme/payments",
+		"${}/acme/payments",
+		"buf.build/acme/payments$",
+		"buf.build/$/payments",
+	} {
+		_, err := expandEnv(input, envLookup)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestGetConfigForDataWithEnvLookup(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	data := []byte(`version: v1
+name: ${BUF_REGISTRY}/acme/weather
+deps:
+  - ${BUF_REGISTRY}/acme/payments
+  - buf.build/acme/units`)
+	provider := NewProvider(
+		zap.NewNop(),
+		ProviderWithEnvLookup(newTestEnvLookup(map[string]string{"BUF_REGISTRY": "buf.example.com"})),
+	)
+	config, err := provider.GetConfigForData(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.example.com/acme/weather", config.ModuleIdentity.IdentityString())
+	require.Len(t, config.Build.DependencyModuleReferences, 2)
+	assert.Equal(t, "buf.example.com/acme/payments", config.Build.DependencyModuleReferences[0].IdentityString())
+
+	provider = NewProvider(zap.NewNop(), ProviderWithEnvLookup(newTestEnvLookup(nil)))
+	_, err = provider.GetConfigForData(ctx, data)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `environment variable "BUF_REGISTRY", which is not set`)
+
+	// without a lookup, the reference is parsed as written
+	_, err = NewProvider(zap.NewNop()).GetConfigForData(ctx, data)
+	require.Error(t, err)
+}
+
+// This test is not parallel as it sets an environment variable.
+func TestReadConfigEnvLookup(t *testing.T) {
+	const envName = "BUFCONFIG_TEST_READ_CONFIG_REGISTRY"
+	require.NoError(t, os.Setenv(envName, "buf.example.com"))
+	defer func() {
+		require.NoError(t, os.Unsetenv(envName))
+	}()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: ${` + envName + `}/acme/weather`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, NewProvider(zap.NewNop()), readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.example.com/acme/weather", config.ModuleIdentity.IdentityString())
+	// an injected lookup takes precedence
+	config, err = ReadConfig(
+		ctx,
+		NewProvider(
+			zap.NewNop(),
+			ProviderWithEnvLookup(newTestEnvLookup(map[string]string{envName: "buf.other.com"})),
+		),
+		readBucket,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.other.com/acme/weather", config.ModuleIdentity.IdentityString())
+}
+
+func newTestEnvLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// jsonSchemaRuleListFieldNames are the names of the fields of the lint and breaking
+// configurations that are lists of rule ids and categories.
+var jsonSchemaRuleListFieldNames = map[string]struct{}{
+	"use":           {},
+	"except":        {},
+	"proto2_except": {},
+	"proto3_except": {},
+}
+
+type jsonSchema map[string]interface{}
+
+func externalConfigJSONSchema(version string) ([]byte, error) {
+	var externalConfigType reflect.Type
+	var getAllLintRules func() ([]bufcheck.Rule, error)
+	var getAllBreakingRules func() ([]bufcheck.Rule, error)
+	switch version {
+	case V1Beta1Version:
+		externalConfigType = reflect.TypeOf(ExternalConfigV1Beta1{})
+		getAllLintRules = buflint.GetAllRulesV1Beta1
+		getAllBreakingRules = bufbreaking.GetAllRulesV1Beta1
+	case V1Version:
+		externalConfigType = reflect.TypeOf(ExternalConfigV1{})
+		getAllLintRules = buflint.GetAllRulesV1
+		getAllBreakingRules = bufbreaking.GetAllRulesV1
+	case V2Version:
+		externalConfigType = reflect.TypeOf(ExternalConfigV2{})
+		// v2 modules use the v1 lint and breaking configurations
+		getAllLintRules = buflint.GetAllRulesV1
+		getAllBreakingRules = bufbreaking.GetAllRulesV1
+	default:
+		return nil, fmt.Errorf("unknown configuration file version for ExternalConfigJSONSchema: %q", version)
+	}
+	lintIDsOrCategories, err := getJSONSchemaIDsOrCategories(getAllLintRules)
+	if err != nil {
+		return nil, err
+	}
+	breakingIDsOrCategories, err := getJSONSchemaIDsOrCategories(getAllBreakingRules)
+	if err != nil {
+		return nil, err
+	}
+	builder := &jsonSchemaBuilder{
+		typeToIDsOrCategories: map[reflect.Type][]string{
+			reflect.TypeOf(buflint.ExternalConfigV1Beta1{}):     lintIDsOrCategories,
+			reflect.TypeOf(buflint.ExternalConfigV1{}):          lintIDsOrCategories,
+			reflect.TypeOf(bufbreaking.ExternalConfigV1Beta1{}): breakingIDsOrCategories,
+			reflect.TypeOf(bufbreaking.ExternalConfigV1{}):      breakingIDsOrCategories,
+		},
+	}
+	schema := builder.schemaForStruct(externalConfigType, nil)
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = fmt.Sprintf("%s %s", ExternalConfigFilePath, version)
+	schema["required"] = []string{"version"}
+	properties := schema["properties"].(jsonSchema)
+	versionEnum := make([]string, len(AllVersions))
+	copy(versionEnum, AllVersions)
+	properties["version"] = jsonSchema{
+		"type": "string",
+		"enum": versionEnum,
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+type jsonSchemaBuilder struct {
+	// typeToIDsOrCategories are the rule ids and categories for the rule list fields
+	// of the lint and breaking configuration types.
+	typeToIDsOrCategories map[reflect.Type][]string
+}
+
+func (b *jsonSchemaBuilder) schemaForType(t reflect.Type, idsOrCategories []string) jsonSchema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schemaForType(t.Elem(), idsOrCategories)
+	case reflect.Bool:
+		return jsonSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonSchema{"type": "number"}
+	case reflect.String:
+		if len(idsOrCategories) > 0 {
+			return jsonSchema{"type": "string", "enum": idsOrCategories}
+		}
+		return jsonSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonSchema{
+			"type":  "array",
+			"items": b.schemaForType(t.Elem(), idsOrCategories),
+		}
+	case reflect.Map:
+		schema := jsonSchema{
+			"type":                 "object",
+			"additionalProperties": b.schemaForType(t.Elem(), nil),
+		}
+		if len(idsOrCategories) > 0 {
+			schema["propertyNames"] = jsonSchema{"enum": idsOrCategories}
+		}
+		return schema
+	case reflect.Struct:
+		return b.schemaForStruct(t, b.typeToIDsOrCategories[t])
+	default:
+		// this should never happen for the external configuration types
+		return jsonSchema{}
+	}
+}
+
+// schemaForStruct returns the schema for the struct type.
+//
+// If idsOrCategories is not empty, the rule list fields and the keys of
+// ignore_only only accept the given rule ids and categories.
+func (b *jsonSchemaBuilder) schemaForStruct(t reflect.Type, idsOrCategories []string) jsonSchema {
+	properties := make(jsonSchema)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := getJSONFieldName(field)
+		if name == "" {
+			continue
+		}
+		var fieldIDsOrCategories []string
+		if _, ok := jsonSchemaRuleListFieldNames[name]; ok || name == "ignore_only" {
+			fieldIDsOrCategories = idsOrCategories
+		}
+		properties[name] = b.schemaForType(field.Type, fieldIDsOrCategories)
+	}
+	return jsonSchema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// getJSONFieldName returns the name of the field in JSON, or empty if the field
+// is not serialized.
+func getJSONFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		// unexported
+		return ""
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+func getJSONSchemaIDsOrCategories(getAllRules func() ([]bufcheck.Rule, error)) ([]string, error) {
+	rules, err := getAllRules()
+	if err != nil {
+		return nil, err
+	}
+	idsOrCategories := make(map[string]struct{})
+	for _, rule := range rules {
+		idsOrCategories[rule.ID()] = struct{}{}
+		for _, category := range rule.Categories() {
+			idsOrCategories[category] = struct{}{}
+		}
+	}
+	return stringutil.MapToSortedSlice(idsOrCategories), nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalConfigJSONSchema(t *testing.T) {
+	t.Parallel()
+	for _, version := range AllVersions {
+		data, err := ExternalConfigJSONSchema(version)
+		require.NoError(t, err, version)
+		schema := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(data, &schema), version)
+		assert.Equal(t, []interface{}{"version"}, schema["required"], version)
+		assert.Equal(t, false, schema["additionalProperties"], version)
+		properties := schema["properties"].(map[string]interface{})
+		assert.Equal(
+			t,
+			map[string]interface{}{
+				"type": "string",
+				"enum": []interface{}{V1Beta1Version, V1Version, V2Version},
+			},
+			properties["version"],
+			version,
+		)
+	}
+
+	data, err := ExternalConfigJSONSchema(V1Version)
+	require.NoError(t, err)
+	schema := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]interface{})
+	assert.Contains(t, properties, "deps")
+	assert.Contains(t, properties, "dep_import_aliases")
+	buildProperties := properties["build"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"type": "string"},
+		},
+		buildProperties["excludes"],
+	)
+	lintProperties := properties["lint"].(map[string]interface{})["properties"].(map[string]interface{})
+	lintUseEnum := lintProperties["use"].(map[string]interface{})["items"].(map[string]interface{})["enum"].([]interface{})
+	assert.Contains(t, lintUseEnum, "DEFAULT")
+	assert.Contains(t, lintUseEnum, "ENUM_PASCAL_CASE")
+	assert.NotContains(t, lintUseEnum, "FILE_NO_DELETE")
+	assert.Equal(t, map[string]interface{}{"type": "boolean"}, lintProperties["enabled"])
+	lintIgnoreOnlyPropertyNames := lintProperties["ignore_only"].(map[string]interface{})["propertyNames"].(map[string]interface{})
+	assert.Contains(t, lintIgnoreOnlyPropertyNames["enum"], "ENUM_PASCAL_CASE")
+	breakingProperties := properties["breaking"].(map[string]interface{})["properties"].(map[string]interface{})
+	breakingExceptEnum := breakingProperties["except"].(map[string]interface{})["items"].(map[string]interface{})["enum"].([]interface{})
+	assert.Contains(t, breakingExceptEnum, "FILE_NO_DELETE")
+	assert.Contains(t, breakingExceptEnum, "WIRE_JSON")
+	assert.NotContains(t, breakingExceptEnum, "ENUM_PASCAL_CASE")
+
+	v1Beta1Data, err := ExternalConfigJSONSchema(V1Beta1Version)
+	require.NoError(t, err)
+	v1Beta1Schema := make(map[string]interface{})
+	require.NoError(t, json.Unmarshal(v1Beta1Data, &v1Beta1Schema))
+	v1Beta1Properties := v1Beta1Schema["properties"].(map[string]interface{})
+	v1Beta1BuildProperties := v1Beta1Properties["build"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Contains(t, v1Beta1BuildProperties, "roots")
+	assert.NotContains(t, v1Beta1Properties, "default_visibility")
+
+	_, err = ExternalConfigJSONSchema("v3")
+	require.Error(t, err)
+}
@@ -0,0 +1,343 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"go.uber.org/zap"
+)
+
+func findConfigsNeedingMigration(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	toVersion string,
+) ([]string, error) {
+	toVersionIndex, err := versionIndex(toVersion)
+	if err != nil {
+		return nil, err
+	}
+	var configFilePaths []string
+	if err := walkConfigFiles(
+		ctx,
+		readBucket,
+		func(objectInfo storage.ObjectInfo) error {
+			path := objectInfo.Path()
+			data, err := storage.ReadPath(ctx, readBucket, path)
+			if err != nil {
+				return err
+			}
+			version, err := detectConfigVersion(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", objectInfo.ExternalPath(), err)
+			}
+			configVersionIndex, err := versionIndex(version)
+			if err != nil {
+				return fmt.Errorf("%s: %w", objectInfo.ExternalPath(), err)
+			}
+			if configVersionIndex < toVersionIndex {
+				configFilePaths = append(configFilePaths, path)
+			}
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	sort.Strings(configFilePaths)
+	return configFilePaths, nil
+}
+
+// detectConfigVersion returns the version of the configuration data.
+//
+// Configuration data without a version is v1beta1 to maintain compatibility
+// with previous releases.
+func detectConfigVersion(data []byte) (string, error) {
+	var externalConfigVersion ExternalConfigVersion
+	if err := encoding.UnmarshalYAMLNonStrict(data, &externalConfigVersion); err != nil {
+		return "", err
+	}
+	if externalConfigVersion.Version == "" {
+		return V1Beta1Version, nil
+	}
+	return externalConfigVersion.Version, nil
+}
+
+// versionIndex returns the index of the version within AllVersions.
+func versionIndex(version string) (int, error) {
+	for i, knownVersion := range AllVersions {
+		if version == knownVersion {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown config version: %q", version)
+}
+
+func migrateV1Beta1ToV1(externalConfig ExternalConfigV1Beta1) (ExternalConfigV1, []MigrationNote, error) {
+	if externalConfig.Version != "" && externalConfig.Version != V1Beta1Version {
+		return ExternalConfigV1{}, nil, fmt.Errorf("cannot migrate config with version %q, expected %q", externalConfig.Version, V1Beta1Version)
+	}
+	provider := newProvider(zap.NewNop())
+	config, err := provider.newConfigV1Beta1(externalConfig)
+	if err != nil {
+		return ExternalConfigV1{}, nil, err
+	}
+	lintRuleMigrator, err := newRuleMigrator("lint", buflint.GetAllRulesV1Beta1, buflint.GetAllRulesV1)
+	if err != nil {
+		return ExternalConfigV1{}, nil, err
+	}
+	breakingRuleMigrator, err := newRuleMigrator("breaking", bufbreaking.GetAllRulesV1Beta1, bufbreaking.GetAllRulesV1)
+	if err != nil {
+		return ExternalConfigV1{}, nil, err
+	}
+	build, buildNotes := migrateBuildV1Beta1ToV1(config.Build)
+	lint := externalConfig.Lint
+	breaking := externalConfig.Breaking
+	migratedExternalConfig := ExternalConfigV1{
+		Version: V1Version,
+		Name:    externalConfig.Name,
+		Deps:    externalConfig.Deps,
+		Build:   build,
+		Breaking: bufbreaking.ExternalConfigV1{
+			Use:                    breakingRuleMigrator.migrateIDsOrCategories("use", breaking.Use),
+			Except:                 breakingRuleMigrator.migrateIDsOrCategories("except", breaking.Except),
+			Ignore:                 breaking.Ignore,
+			IgnoreOnly:             breakingRuleMigrator.migrateIgnoreOnly(breaking.IgnoreOnly),
+			IgnoreUnstablePackages: breaking.IgnoreUnstablePackages,
+			Enabled:                breaking.Enabled,
+			Granularity:            breaking.Granularity,
+		},
+		Lint: buflint.ExternalConfigV1{
+			Use:                                  lintRuleMigrator.migrateIDsOrCategories("use", lint.Use),
+			Except:                               lintRuleMigrator.migrateIDsOrCategories("except", lint.Except),
+			Proto2Except:                         lintRuleMigrator.migrateIDsOrCategories("proto2_except", lint.Proto2Except),
+			Proto3Except:                         lintRuleMigrator.migrateIDsOrCategories("proto3_except", lint.Proto3Except),
+			Ignore:                               lint.Ignore,
+			IgnoreOnly:                           lintRuleMigrator.migrateIgnoreOnly(lint.IgnoreOnly),
+			EnumValuePrefix:                      lint.EnumValuePrefix,
+			EnumZeroValueSuffix:                  lint.EnumZeroValueSuffix,
+			RPCAllowSameRequestResponse:          lint.RPCAllowSameRequestResponse,
+			RPCAllowGoogleProtobufEmptyRequests:  lint.RPCAllowGoogleProtobufEmptyRequests,
+			RPCAllowGoogleProtobufEmptyResponses: lint.RPCAllowGoogleProtobufEmptyResponses,
+			ServiceSuffix:                        lint.ServiceSuffix,
+			AllowCommentIgnores:                  lint.AllowCommentIgnores,
+			Enabled:                              lint.Enabled,
+			MessageTemplates:                     lintRuleMigrator.migrateMessageTemplates(lint.MessageTemplates),
+			AllowedFieldNumberRanges:             lint.AllowedFieldNumberRanges,
+			Baseline:                             lint.Baseline,
+		},
+	}
+	// the v1 config is validated as well so that we never return a config that cannot be read
+	if _, err := provider.newConfigV1(migratedExternalConfig); err != nil {
+		return ExternalConfigV1{}, nil, fmt.Errorf("migrated config is invalid: %w", err)
+	}
+	migrationNotes := append(buildNotes, breakingRuleMigrator.migrationNotes...)
+	migrationNotes = append(migrationNotes, lintRuleMigrator.migrationNotes...)
+	return migratedExternalConfig, migrationNotes, nil
+}
+
+// migrateBuildV1Beta1ToV1 migrates the build section.
+//
+// v1 has no roots, the directory of the configuration file is the single root
+// of the module. A single root can be migrated by moving the configuration file
+// to the root, multiple roots need a workspace.
+func migrateBuildV1Beta1ToV1(buildConfig *bufmodulebuild.Config) (bufmodulebuild.ExternalConfigV1, []MigrationNote) {
+	roots := make([]string, 0, len(buildConfig.RootToExcludes))
+	for root := range buildConfig.RootToExcludes {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	switch {
+	case len(roots) == 1 && roots[0] == ".":
+		return bufmodulebuild.ExternalConfigV1{
+			Excludes: buildConfig.RootToExcludes["."],
+		}, nil
+	case len(roots) == 1:
+		// excludes are already relative to the root
+		return bufmodulebuild.ExternalConfigV1{
+			Excludes: buildConfig.RootToExcludes[roots[0]],
+		}, []MigrationNote{
+			{
+				Field:   "build.roots",
+				Message: fmt.Sprintf("v1 has no roots, move the configuration file to %q, excludes are now relative to it", roots[0]),
+			},
+		}
+	default:
+		return bufmodulebuild.ExternalConfigV1{}, []MigrationNote{
+			{
+				Field: "build.roots",
+				Message: fmt.Sprintf(
+					"v1 has no roots, each of the roots %s must become its own module with its own configuration file listed in a buf.work.yaml, and excludes must be moved to the configuration file of the root they are within",
+					strings.Join(roots, ", "),
+				),
+			},
+		}
+	}
+}
+
+// ruleMigrator migrates the rule IDs and categories of a section from v1beta1 to v1.
+type ruleMigrator struct {
+	section string
+	// v1IDsAndCategories are the IDs and categories known at v1.
+	v1IDsAndCategories map[string]struct{}
+	// v1Beta1CategoryToIDs are the IDs of each v1beta1 category.
+	v1Beta1CategoryToIDs map[string][]string
+	migrationNotes       []MigrationNote
+}
+
+func newRuleMigrator(
+	section string,
+	getAllRulesV1Beta1 func() ([]bufcheck.Rule, error),
+	getAllRulesV1 func() ([]bufcheck.Rule, error),
+) (*ruleMigrator, error) {
+	v1Beta1Rules, err := getAllRulesV1Beta1()
+	if err != nil {
+		return nil, err
+	}
+	v1Rules, err := getAllRulesV1()
+	if err != nil {
+		return nil, err
+	}
+	v1IDsAndCategories := make(map[string]struct{})
+	for _, rule := range v1Rules {
+		v1IDsAndCategories[rule.ID()] = struct{}{}
+		for _, category := range rule.Categories() {
+			v1IDsAndCategories[category] = struct{}{}
+		}
+	}
+	v1Beta1CategoryToIDs := make(map[string][]string)
+	for _, rule := range v1Beta1Rules {
+		for _, category := range rule.Categories() {
+			v1Beta1CategoryToIDs[category] = append(v1Beta1CategoryToIDs[category], rule.ID())
+		}
+	}
+	return &ruleMigrator{
+		section:              section,
+		v1IDsAndCategories:   v1IDsAndCategories,
+		v1Beta1CategoryToIDs: v1Beta1CategoryToIDs,
+	}, nil
+}
+
+// migrateIDOrCategory returns the v1 IDs and categories for the v1beta1 ID or category.
+//
+// Categories that do not exist at v1 are replaced by the rules they contained
+// that do exist at v1. IDs that do not exist at v1 are dropped.
+func (r *ruleMigrator) migrateIDOrCategory(field string, idOrCategory string) []string {
+	if _, ok := r.v1IDsAndCategories[idOrCategory]; ok {
+		return []string{idOrCategory}
+	}
+	ids, isCategory := r.v1Beta1CategoryToIDs[idOrCategory]
+	if !isCategory {
+		r.addMigrationNote(field, "rule %s does not exist in v1 and was dropped", idOrCategory)
+		return nil
+	}
+	var v1IDs []string
+	for _, id := range ids {
+		if _, ok := r.v1IDsAndCategories[id]; ok {
+			v1IDs = append(v1IDs, id)
+		}
+	}
+	sort.Strings(v1IDs)
+	r.addMigrationNote(
+		field,
+		"category %s does not exist in v1 and was replaced by the rules it contained: %s",
+		idOrCategory,
+		strings.Join(v1IDs, ", "),
+	)
+	return v1IDs
+}
+
+func (r *ruleMigrator) migrateIDsOrCategories(field string, idsOrCategories []string) []string {
+	var migrated []string
+	seen := make(map[string]struct{})
+	for _, idOrCategory := range idsOrCategories {
+		for _, v1IDOrCategory := range r.migrateIDOrCategory(field, idOrCategory) {
+			if _, ok := seen[v1IDOrCategory]; !ok {
+				seen[v1IDOrCategory] = struct{}{}
+				migrated = append(migrated, v1IDOrCategory)
+			}
+		}
+	}
+	return migrated
+}
+
+func (r *ruleMigrator) migrateIgnoreOnly(ignoreOnly map[string][]string) map[string][]string {
+	if len(ignoreOnly) == 0 {
+		return ignoreOnly
+	}
+	idsOrCategories := make([]string, 0, len(ignoreOnly))
+	for idOrCategory := range ignoreOnly {
+		idsOrCategories = append(idsOrCategories, idOrCategory)
+	}
+	// iterate in sorted order so that the migration notes are deterministic
+	sort.Strings(idsOrCategories)
+	idOrCategoryToPathMap := make(map[string]map[string]struct{})
+	for _, idOrCategory := range idsOrCategories {
+		for _, v1IDOrCategory := range r.migrateIDOrCategory("ignore_only", idOrCategory) {
+			pathMap, ok := idOrCategoryToPathMap[v1IDOrCategory]
+			if !ok {
+				pathMap = make(map[string]struct{})
+				idOrCategoryToPathMap[v1IDOrCategory] = pathMap
+			}
+			for _, path := range ignoreOnly[idOrCategory] {
+				pathMap[path] = struct{}{}
+			}
+		}
+	}
+	migrated := make(map[string][]string, len(idOrCategoryToPathMap))
+	for idOrCategory, pathMap := range idOrCategoryToPathMap {
+		migrated[idOrCategory] = stringutil.MapToSortedSlice(pathMap)
+	}
+	return migrated
+}
+
+func (r *ruleMigrator) migrateMessageTemplates(messageTemplates map[string]string) map[string]string {
+	if len(messageTemplates) == 0 {
+		return messageTemplates
+	}
+	ids := make([]string, 0, len(messageTemplates))
+	for id := range messageTemplates {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	migrated := make(map[string]string, len(messageTemplates))
+	for _, id := range ids {
+		if _, ok := r.v1IDsAndCategories[id]; !ok {
+			r.addMigrationNote("message_templates", "rule %s does not exist in v1 and its message template was dropped", id)
+			continue
+		}
+		migrated[id] = messageTemplates[id]
+	}
+	return migrated
+}
+
+func (r *ruleMigrator) addMigrationNote(field string, format string, args ...interface{}) {
+	r.migrationNotes = append(
+		r.migrationNotes,
+		MigrationNote{
+			Field:   r.section + "." + field,
+			Message: fmt.Sprintf(format, args...),
+		},
+	)
+}
@@ -0,0 +1,143 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConfigsNeedingMigration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a/" + ExternalConfigFilePath:        []byte(`version: v1`),
+			"b/" + ExternalConfigV1Beta1FilePath: []byte(`version: v1beta1`),
+			"c/d/" + ExternalConfigV1Beta1FilePath: []byte(`lint:
+  use:
+    - DEFAULT`),
+			"e/" + ExternalConfigV1Beta1FilePath: []byte(`version: v1`),
+			"e/other.yaml":                       []byte(`version: v1beta1`),
+		},
+	)
+	require.NoError(t, err)
+	paths, err := FindConfigsNeedingMigration(ctx, readBucket, V1Version)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b/buf.yaml", "c/d/buf.yaml"}, paths)
+	paths, err = FindConfigsNeedingMigration(ctx, readBucket, V1Beta1Version)
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+	_, err = FindConfigsNeedingMigration(ctx, readBucket, "v3")
+	require.Error(t, err)
+}
+
+func TestMigrateV1Beta1ToV1(t *testing.T) {
+	t.Parallel()
+	enabled := false
+	externalConfig, migrationNotes, err := MigrateV1Beta1ToV1(
+		ExternalConfigV1Beta1{
+			Version: V1Beta1Version,
+			Name:    "buf.build/acme/weather",
+			Deps:    []string{"buf.build/acme/units"},
+			Build: bufmodulebuild.ExternalConfigV1Beta1{
+				Roots:    []string{"proto"},
+				Excludes: []string{"proto/a"},
+			},
+			Breaking: bufbreaking.ExternalConfigV1Beta1{
+				Use:     []string{"FILE"},
+				Enabled: &enabled,
+			},
+			Lint: buflint.ExternalConfigV1Beta1{
+				Use:    []string{"DEFAULT", "FIELD_NO_DESCRIPTOR"},
+				Except: []string{"OTHER"},
+				IgnoreOnly: map[string][]string{
+					"FIELD_LOWER_SNAKE_CASE": {"b"},
+					"FIELD_NO_DESCRIPTOR":    {"c"},
+				},
+				ServiceSuffix: "API",
+			},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, externalConfig.Version)
+	assert.Equal(t, "buf.build/acme/weather", externalConfig.Name)
+	assert.Equal(t, []string{"buf.build/acme/units"}, externalConfig.Deps)
+	assert.Equal(t, []string{"a"}, externalConfig.Build.Excludes)
+	assert.Equal(t, []string{"FILE"}, externalConfig.Breaking.Use)
+	assert.Equal(t, &enabled, externalConfig.Breaking.Enabled)
+	assert.Equal(t, []string{"DEFAULT"}, externalConfig.Lint.Use)
+	assert.Equal(t, []string{"FIELD_LOWER_SNAKE_CASE"}, mapKeys(externalConfig.Lint.IgnoreOnly))
+	assert.Equal(t, "API", externalConfig.Lint.ServiceSuffix)
+	migrationNoteFields := make([]string, len(migrationNotes))
+	for i, migrationNote := range migrationNotes {
+		migrationNoteFields[i] = migrationNote.Field
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"build.roots",
+			"lint.use",
+			"lint.except",
+			"lint.ignore_only",
+		},
+		migrationNoteFields,
+	)
+	assert.Contains(t, migrationNotes[2].String(), "category OTHER does not exist in v1")
+}
+
+func TestMigrateV1Beta1ToV1MultipleRoots(t *testing.T) {
+	t.Parallel()
+	externalConfig, migrationNotes, err := MigrateV1Beta1ToV1(
+		ExternalConfigV1Beta1{
+			Build: bufmodulebuild.ExternalConfigV1Beta1{
+				Roots: []string{"a", "b"},
+			},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, externalConfig.Version)
+	require.Len(t, migrationNotes, 1)
+	assert.Equal(t, "build.roots", migrationNotes[0].Field)
+}
+
+func TestMigrateV1Beta1ToV1Invalid(t *testing.T) {
+	t.Parallel()
+	_, _, err := MigrateV1Beta1ToV1(
+		ExternalConfigV1Beta1{
+			Lint: buflint.ExternalConfigV1Beta1{
+				Use: []string{"NOT_A_RULE"},
+			},
+		},
+	)
+	require.Error(t, err)
+	_, _, err = MigrateV1Beta1ToV1(ExternalConfigV1Beta1{Version: V1Version})
+	require.Error(t, err)
+}
+
+func mapKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
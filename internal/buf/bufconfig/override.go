@@ -0,0 +1,97 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+)
+
+// overrideReplaceKey is the key of the marker that replaces a list instead of
+// unioning it, for example "use: {replace: [DEFAULT]}".
+const overrideReplaceKey = "replace"
+
+// mergeConfigData deep-merges the JSON, YAML, or TOML overrideData into the YAML baseData,
+// and returns the merged YAML data.
+//
+// If baseData is nil, there is no base config, and the overrideData is used as-is
+// other than resolving replace markers.
+func mergeConfigData(baseData []byte, overrideData []byte) ([]byte, error) {
+	base := make(map[string]interface{})
+	if err := encoding.UnmarshalYAMLNonStrict(baseData, &base); err != nil {
+		return nil, err
+	}
+	override := make(map[string]interface{})
+	if err := unmarshalConfigDataNonStrict(overrideData, &override); err != nil {
+		return nil, fmt.Errorf("could not unmarshal override: %w", err)
+	}
+	if baseData != nil {
+		baseVersion, _ := base["version"].(string)
+		if baseVersion == "" {
+			baseVersion = V1Beta1Version
+		}
+		if overrideVersion, _ := override["version"].(string); overrideVersion != "" && overrideVersion != baseVersion {
+			return nil, fmt.Errorf("override has config version %q, but the base config has version %q", overrideVersion, baseVersion)
+		}
+	}
+	return encoding.MarshalYAML(mergeConfigValues(base, override))
+}
+
+// mergeConfigValues merges the override value into the base value.
+//
+// Maps are merged key by key, lists are unioned with the base elements first, and
+// all other values are replaced. A map with the single key overrideReplaceKey
+// replaces the base value with the value of the key.
+func mergeConfigValues(base interface{}, override interface{}) interface{} {
+	switch override := override.(type) {
+	case map[string]interface{}:
+		if replace, ok := override[overrideReplaceKey]; ok && len(override) == 1 {
+			return replace
+		}
+		baseMap, _ := base.(map[string]interface{})
+		merged := make(map[string]interface{}, len(baseMap)+len(override))
+		for key, value := range baseMap {
+			merged[key] = value
+		}
+		for key, value := range override {
+			merged[key] = mergeConfigValues(baseMap[key], value)
+		}
+		return merged
+	case []interface{}:
+		baseSlice, _ := base.([]interface{})
+		merged := make([]interface{}, 0, len(baseSlice)+len(override))
+		for _, values := range [][]interface{}{baseSlice, override} {
+			for _, value := range values {
+				if !sliceContainsValue(merged, value) {
+					merged = append(merged, value)
+				}
+			}
+		}
+		return merged
+	default:
+		return override
+	}
+}
+
+func sliceContainsValue(s []interface{}, value interface{}) bool {
+	for _, e := range s {
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
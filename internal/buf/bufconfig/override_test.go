@@ -0,0 +1,104 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetConfigForBucketAndOverride(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/acme/weather
+deps:
+  - buf.build/acme/foo
+lint:
+  use:
+    - BASIC
+  except:
+    - ENUM_PASCAL_CASE
+`),
+		},
+	)
+	require.NoError(t, err)
+
+	config, err := provider.GetConfigForBucketAndOverride(ctx, readBucket, nil)
+	require.NoError(t, err)
+	baseConfig, err := provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, baseConfig.ModuleIdentity.IdentityString(), config.ModuleIdentity.IdentityString())
+	assert.Equal(t, configLintRuleIDs(baseConfig), configLintRuleIDs(config))
+
+	config, err = provider.GetConfigForBucketAndOverride(ctx, readBucket, []byte(`name: buf.build/acme/weather-ci
+deps:
+  - buf.build/acme/bar
+  - buf.build/acme/foo
+lint:
+  except:
+    - FIELD_LOWER_SNAKE_CASE
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/acme/weather-ci", config.ModuleIdentity.IdentityString())
+	require.Len(t, config.Build.DependencyModuleReferences, 2)
+	assert.Equal(t, "buf.build/acme/foo", config.Build.DependencyModuleReferences[0].IdentityString())
+	assert.Equal(t, "buf.build/acme/bar", config.Build.DependencyModuleReferences[1].IdentityString())
+	lintIDs := configLintRuleIDs(config)
+	assert.NotContains(t, lintIDs, "ENUM_PASCAL_CASE")
+	assert.NotContains(t, lintIDs, "FIELD_LOWER_SNAKE_CASE")
+	assert.Contains(t, lintIDs, "PACKAGE_DIRECTORY_MATCH")
+
+	config, err = provider.GetConfigForBucketAndOverride(ctx, readBucket, []byte(`{"lint":{"except":{"replace":[]}}}`))
+	require.NoError(t, err)
+	assert.Contains(t, configLintRuleIDs(config), "ENUM_PASCAL_CASE")
+
+	_, err = provider.GetConfigForBucketAndOverride(ctx, readBucket, []byte(`version: v1beta1`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `override has config version "v1beta1", but the base config has version "v1"`)
+}
+
+func TestGetConfigForBucketAndOverrideNoBase(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, err := provider.GetConfigForBucketAndOverride(ctx, readBucket, []byte(`version: v1
+lint:
+  use:
+    replace:
+      - BASIC
+`))
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, config.Version)
+	assert.Contains(t, configLintRuleIDs(config), "PACKAGE_DIRECTORY_MATCH")
+}
+
+func configLintRuleIDs(config *Config) []string {
+	var ids []string
+	for _, rule := range config.Lint.Rules {
+		ids = append(ids, rule.ID())
+	}
+	return ids
+}
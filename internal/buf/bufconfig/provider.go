@@ -15,88 +15,282 @@
 package bufconfig
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
+	"sync/atomic"
 
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
 	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
 	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
 	"go.opencensus.io/trace"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
+// tomlFirstLineRegexp matches the first line of TOML data, which is either a table
+// header or a key/value pair. YAML keys are followed by ":" instead of "=".
+var tomlFirstLineRegexp = regexp.MustCompile(`^(\[.*\]|([A-Za-z0-9_.-]+|"[^"]*"|'[^']*')(\s*\.\s*([A-Za-z0-9_-]+|"[^"]*"|'[^']*'))*\s*=)`)
+
 type provider struct {
-	logger *zap.Logger
+	logger      *zap.Logger
+	configCache *configCache
+	envLookup   func(string) (string, bool)
+	// withoutV1Beta1Fallback is true if ExternalConfigV1Beta1FilePath is not read,
+	// and is instead an error if it exists.
+	withoutV1Beta1Fallback bool
+	// decodeCount is the number of times configuration data has been decoded.
+	//
+	// This is only used for testing.
+	decodeCount uint64
 }
 
-func newProvider(logger *zap.Logger) *provider {
-	return &provider{
+func newProvider(logger *zap.Logger, options ...ProviderOption) *provider {
+	provider := &provider{
 		logger: logger,
 	}
+	for _, option := range options {
+		option(provider)
+	}
+	return provider
+}
+
+func (p *provider) GetConfig(ctx context.Context, readBucket storage.ReadBucket) (*Config, error) {
+	return p.getConfig(ctx, readBucket, p.envLookup)
+}
+
+func (p *provider) GetConfigAndData(ctx context.Context, readBucket storage.ReadBucket) (*Config, []byte, error) {
+	return p.getConfigAndData(ctx, readBucket, p.envLookup)
+}
+
+func (p *provider) GetConfigForData(ctx context.Context, data []byte) (*Config, error) {
+	return p.getConfigForData(ctx, data, p.envLookup)
+}
+
+func (p *provider) GetConfigForBucketAndOverride(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	overrideData []byte,
+) (*Config, error) {
+	return p.getConfigForBucketAndOverride(ctx, readBucket, overrideData, p.envLookup)
 }
 
-func (p *provider) GetConfig(ctx context.Context, readBucket storage.ReadBucket) (_ *Config, retErr error) {
+// getConfig is GetConfig with the given envLookup instead of the envLookup of the provider.
+//
+// If envLookup is nil, environment variables are not expanded.
+func (p *provider) getConfig(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
+	config, _, err := p.getConfigAndData(ctx, readBucket, envLookup)
+	return config, err
+}
+
+// getConfigAndData is GetConfigAndData with the given envLookup instead of the
+// envLookup of the provider.
+//
+// If envLookup is nil, environment variables are not expanded.
+func (p *provider) getConfigAndData(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	envLookup func(string) (string, bool),
+) (*Config, []byte, error) {
 	ctx, span := trace.StartSpan(ctx, "get_config")
 	defer span.End()
 
-	readObjectCloser, err := readBucket.Get(ctx, ExternalConfigFilePath)
+	data, id, err := p.readConfigData(ctx, readBucket)
 	if err != nil {
-		if !storage.IsNotExist(err) {
-			return nil, err
-		}
-		// Look for old config file
-		readObjectCloser, err = readBucket.Get(ctx, ExternalConfigV1Beta1FilePath)
+		return nil, nil, err
+	}
+	if data == nil {
+		// TODO: change to V1 when we make V1 the default
+		config, err := p.newConfigV1Beta1(ExternalConfigV1Beta1{})
 		if err != nil {
-			if storage.IsNotExist(err) {
-				// TODO: change to V1 when we make V1 the default
-				return p.newConfigV1Beta1(ExternalConfigV1Beta1{})
-			}
-			return nil, err
+			return nil, nil, err
 		}
+		return config, nil, nil
 	}
-	defer func() {
-		retErr = multierr.Append(retErr, readObjectCloser.Close())
-	}()
-	data, err := io.ReadAll(readObjectCloser)
+	config, err := p.getConfigForBucketData(ctx, readBucket, data, id, envLookup)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return p.getConfigForData(
-		ctx,
-		encoding.UnmarshalYAMLNonStrict,
-		encoding.UnmarshalYAMLStrict,
-		data,
-		readObjectCloser.ExternalPath(),
-	)
+	return config, data, nil
 }
 
-func (p *provider) GetConfigForData(ctx context.Context, data []byte) (*Config, error) {
+// getConfigForData is GetConfigForData with the given envLookup instead of the
+// envLookup of the provider.
+//
+// If envLookup is nil, environment variables are not expanded.
+func (p *provider) getConfigForData(
+	ctx context.Context,
+	data []byte,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
 	_, span := trace.StartSpan(ctx, "get_config_for_data")
 	defer span.End()
-	return p.getConfigForData(
+	if detectConfigDataFormat(data) == ConfigDataFormatTOML {
+		return p.getConfigForDataCached(
+			ctx,
+			"toml",
+			encoding.UnmarshalTOMLNonStrict,
+			encoding.UnmarshalTOMLStrict,
+			data,
+			"Configuration data",
+			envLookup,
+		)
+	}
+	return p.getConfigForDataCached(
 		ctx,
+		"json_or_yaml",
 		encoding.UnmarshalJSONOrYAMLNonStrict,
 		encoding.UnmarshalJSONOrYAMLStrict,
 		data,
 		"Configuration data",
+		envLookup,
 	)
 }
 
-func (p *provider) getConfigForData(
+// getConfigForBucketAndOverride is GetConfigForBucketAndOverride with the given
+// envLookup instead of the envLookup of the provider.
+//
+// If envLookup is nil, environment variables are not expanded.
+func (p *provider) getConfigForBucketAndOverride(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	overrideData []byte,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
+	ctx, span := trace.StartSpan(ctx, "get_config_for_bucket_and_override")
+	defer span.End()
+
+	if len(bytes.TrimSpace(overrideData)) == 0 {
+		return p.getConfig(ctx, readBucket, envLookup)
+	}
+	data, id, err := p.readConfigData(ctx, readBucket)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		id = "Configuration override"
+	}
+	mergedData, err := mergeConfigData(data, overrideData)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	return p.getConfigForBucketData(ctx, readBucket, mergedData, id, envLookup)
+}
+
+// getConfigForBucketData gets the Config for the YAML data read from the readBucket.
+//
+// For v2, this validates that every module path exists in the readBucket. The lint
+// baselines are read from the readBucket.
+func (p *provider) getConfigForBucketData(
 	ctx context.Context,
+	readBucket storage.ReadBucket,
+	data []byte,
+	id string,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
+	config, err := p.getConfigForDataCached(
+		ctx,
+		"yaml",
+		encoding.UnmarshalYAMLNonStrict,
+		encoding.UnmarshalYAMLStrict,
+		data,
+		id,
+		envLookup,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if config.Version == V2Version {
+		if err := validateModuleConfigPathsExist(ctx, readBucket, config.ModuleConfigs); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+	}
+	if err := validateBuildExcludeGlobsMatch(ctx, readBucket, config.ModuleConfigs); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	if err := validateLintIgnoreOnlyPathsExist(ctx, readBucket, config.ModuleConfigs); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	config, err = readLintBaselines(ctx, readBucket, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	return config, nil
+}
+
+// getConfigForDataCached calls decodeAndValidateConfigData, using the config cache if enabled.
+//
+// The encoding is part of the cache key, as the same data may be decoded
+// differently as YAML than as JSON or YAML. Data that may reference environment
+// variables is never cached, as the environment may change between calls.
+func (p *provider) getConfigForDataCached(
+	ctx context.Context,
+	encodingName string,
+	unmarshalNonStrict func([]byte, interface{}) error,
+	unmarshalStrict func([]byte, interface{}) error,
+	data []byte,
+	id string,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
+	if p.configCache == nil || (envLookup != nil && bytes.ContainsRune(data, '$')) {
+		return p.decodeAndValidateConfigData(unmarshalNonStrict, unmarshalStrict, data, id, envLookup)
+	}
+	digest := sha256.Sum256(data)
+	key := encodingName + ":" + hex.EncodeToString(digest[:])
+	if config, ok := p.configCache.get(key); ok {
+		return config, nil
+	}
+	config, err := p.decodeAndValidateConfigData(unmarshalNonStrict, unmarshalStrict, data, id, envLookup)
+	if err != nil {
+		return nil, err
+	}
+	p.configCache.put(key, config)
+	return config, nil
+}
+
+func (p *provider) decodeAndValidateConfigData(
+	unmarshalNonStrict func([]byte, interface{}) error,
+	unmarshalStrict func([]byte, interface{}) error,
+	data []byte,
+	id string,
+	envLookup func(string) (string, bool),
+) (*Config, error) {
+	config, err := p.decodeConfigData(unmarshalNonStrict, unmarshalStrict, data, id, envLookup)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	return config, nil
+}
+
+func (p *provider) decodeConfigData(
 	unmarshalNonStrict func([]byte, interface{}) error,
 	unmarshalStrict func([]byte, interface{}) error,
 	data []byte,
 	id string,
+	envLookup func(string) (string, bool),
 ) (*Config, error) {
+	atomic.AddUint64(&p.decodeCount, 1)
 	var externalConfigVersion ExternalConfigVersion
 	if err := unmarshalNonStrict(data, &externalConfigVersion); err != nil {
-		return nil, err
+		return nil, newConfigParseError(data, err)
 	}
 	switch externalConfigVersion.Version {
 	case "":
@@ -105,27 +299,55 @@ func (p *provider) getConfigForData(
 		p.logger.Sugar().Warnf(`%s has no version set. Please add "version: %s". See https://docs.buf.build/faq for more details.`, id, V1Beta1Version)
 		var externalConfigV1Beta1 ExternalConfigV1Beta1
 		if err := unmarshalStrict(data, &externalConfigV1Beta1); err != nil {
-			return nil, err
+			return nil, newConfigParseError(data, err)
+		}
+		if err := expandNameAndDepsEnv("", &externalConfigV1Beta1.Name, externalConfigV1Beta1.Deps, envLookup); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
 		}
 		return p.newConfigV1Beta1(externalConfigV1Beta1)
 	case V1Beta1Version:
 		var externalConfigV1Beta1 ExternalConfigV1Beta1
 		if err := unmarshalStrict(data, &externalConfigV1Beta1); err != nil {
-			return nil, err
+			return nil, newConfigParseError(data, err)
+		}
+		if err := expandNameAndDepsEnv("", &externalConfigV1Beta1.Name, externalConfigV1Beta1.Deps, envLookup); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
 		}
 		return p.newConfigV1Beta1(externalConfigV1Beta1)
 	case V1Version:
 		var externalConfigV1 ExternalConfigV1
 		if err := unmarshalStrict(data, &externalConfigV1); err != nil {
-			return nil, err
+			return nil, newConfigParseError(data, err)
+		}
+		if err := expandNameAndDepsEnv("", &externalConfigV1.Name, externalConfigV1.Deps, envLookup); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
 		}
 		return p.newConfigV1(externalConfigV1)
+	case V2Version:
+		var externalConfigV2 ExternalConfigV2
+		if err := unmarshalStrict(data, &externalConfigV2); err != nil {
+			return nil, newConfigParseError(data, err)
+		}
+		for i := range externalConfigV2.Modules {
+			if err := expandNameAndDepsEnv(
+				fmt.Sprintf("modules[%d].", i),
+				&externalConfigV2.Modules[i].Name,
+				externalConfigV2.Modules[i].Deps,
+				envLookup,
+			); err != nil {
+				return nil, fmt.Errorf("%s: %w", id, err)
+			}
+		}
+		return p.newConfigV2(externalConfigV2)
 	default:
-		return nil, fmt.Errorf(
-			`%s has an invalid "version: %s" set. Please add "version: %s". See https://docs.buf.build/faq for more details`,
-			id,
-			externalConfigVersion.Version,
-			V1Version,
+		return nil, newConfigFieldError(
+			"version",
+			fmt.Errorf(
+				`%s has an invalid "version: %s" set. Please add "version: %s". See https://docs.buf.build/faq for more details`,
+				id,
+				externalConfigVersion.Version,
+				V1Version,
+			),
 		)
 	}
 }
@@ -135,27 +357,31 @@ func (p *provider) newConfigV1Beta1(externalConfig ExternalConfigV1Beta1) (*Conf
 	if err != nil {
 		return nil, err
 	}
-	breakingConfig, err := bufbreaking.NewConfigV1Beta1(externalConfig.Breaking)
-	if err != nil {
-		return nil, err
-	}
-	lintConfig, err := buflint.NewConfigV1Beta1(externalConfig.Lint)
-	if err != nil {
+	breakingConfig, breakingErr := bufbreaking.NewConfigV1Beta1(externalConfig.Breaking)
+	lintConfig, lintErr := buflint.NewConfigV1Beta1(externalConfig.Lint)
+	// report problems with both the breaking and lint sections at once
+	if err := multierr.Append(
+		newConfigFieldError("breaking", breakingErr),
+		newConfigFieldError("lint", lintErr),
+	); err != nil {
 		return nil, err
 	}
 	var moduleIdentity bufmodule.ModuleIdentity
 	if externalConfig.Name != "" {
 		moduleIdentity, err = bufmodule.ModuleIdentityForString(externalConfig.Name)
 		if err != nil {
-			return nil, err
+			return nil, newConfigFieldError("name", err)
 		}
 	}
 	return &Config{
-		Version:        V1Beta1Version,
-		ModuleIdentity: moduleIdentity,
-		Build:          buildConfig,
-		Breaking:       breakingConfig,
-		Lint:           lintConfig,
+		Version:           V1Beta1Version,
+		ModuleIdentity:    moduleIdentity,
+		Build:             buildConfig,
+		Breaking:          breakingConfig,
+		Lint:              lintConfig,
+		ModuleConfigs:     newRootModuleConfigs(moduleIdentity, buildConfig, breakingConfig, lintConfig),
+		DefaultVisibility: VisibilityPrivate,
+		DigestAlgorithm:   bufmodule.DigestAlgorithmB1,
 	}, nil
 }
 
@@ -164,26 +390,384 @@ func (p *provider) newConfigV1(externalConfig ExternalConfigV1) (*Config, error)
 	if err != nil {
 		return nil, err
 	}
-	breakingConfig, err := bufbreaking.NewConfigV1(externalConfig.Breaking)
-	if err != nil {
-		return nil, err
-	}
-	lintConfig, err := buflint.NewConfigV1(externalConfig.Lint)
-	if err != nil {
+	breakingConfig, breakingErr := bufbreaking.NewConfigV1(externalConfig.Breaking)
+	lintConfig, lintErr := buflint.NewConfigV1(externalConfig.Lint)
+	// report problems with both the breaking and lint sections at once
+	if err := multierr.Append(
+		newConfigFieldError("breaking", breakingErr),
+		newConfigFieldError("lint", lintErr),
+	); err != nil {
 		return nil, err
 	}
 	var moduleIdentity bufmodule.ModuleIdentity
 	if externalConfig.Name != "" {
 		moduleIdentity, err = bufmodule.ModuleIdentityForString(externalConfig.Name)
+		if err != nil {
+			return nil, newConfigFieldError("name", err)
+		}
+	}
+	defaultVisibility, err := ParseVisibility(externalConfig.DefaultVisibility)
+	if err != nil {
+		return nil, newConfigFieldError("default_visibility", err)
+	}
+	digestAlgorithm, err := bufmodule.ParseDigestAlgorithm(externalConfig.DigestAlgorithm)
+	if err != nil {
+		return nil, newConfigFieldError("digest_algorithm", err)
+	}
+	dependencyImportAliases, err := parseDependencyImportAliases(
+		externalConfig.DepImportAliases,
+		buildConfig.DependencyModuleReferences,
+	)
+	if err != nil {
+		return nil, newConfigFieldError("dep_import_aliases", err)
+	}
+	if externalConfig.Parallelism < 0 {
+		return nil, newConfigFieldError(
+			"parallelism",
+			fmt.Errorf("parallelism must not be negative but was %d", externalConfig.Parallelism),
+		)
+	}
+	breakingConfig.Parallelism = externalConfig.Parallelism
+	lintConfig.Parallelism = externalConfig.Parallelism
+	var outputFormat bufanalysis.Format
+	if externalConfig.OutputFormat != "" {
+		outputFormat, err = bufanalysis.ParseFormat(externalConfig.OutputFormat)
+		if err != nil {
+			return nil, newConfigFieldError("output_format", fmt.Errorf("output_format: %w", err))
+		}
+	}
+	return &Config{
+		Version:                 V1Version,
+		ModuleIdentity:          moduleIdentity,
+		Build:                   buildConfig,
+		Breaking:                breakingConfig,
+		Lint:                    lintConfig,
+		ModuleConfigs:           newRootModuleConfigs(moduleIdentity, buildConfig, breakingConfig, lintConfig),
+		DefaultVisibility:       defaultVisibility,
+		DigestAlgorithm:         digestAlgorithm,
+		DependencyImportAliases: dependencyImportAliases,
+		Parallelism:             externalConfig.Parallelism,
+		DocumentationExempt:     externalConfig.DocumentationExempt,
+		OutputFormat:            outputFormat,
+	}, nil
+}
+
+func (p *provider) newConfigV2(externalConfig ExternalConfigV2) (*Config, error) {
+	externalModuleConfigs := externalConfig.Modules
+	if len(externalModuleConfigs) == 0 {
+		externalModuleConfigs = []ExternalModuleConfigV2{{}}
+	}
+	moduleConfigs := make([]*ModuleConfig, 0, len(externalModuleConfigs))
+	for i, externalModuleConfig := range externalModuleConfigs {
+		moduleConfig, err := newModuleConfigV2(i, externalModuleConfig)
 		if err != nil {
 			return nil, err
 		}
+		moduleConfigs = append(moduleConfigs, moduleConfig)
+	}
+	sort.Slice(
+		moduleConfigs,
+		func(i int, j int) bool {
+			return moduleConfigs[i].Path < moduleConfigs[j].Path
+		},
+	)
+	if err := validateModuleConfigPathsDoNotOverlap(moduleConfigs); err != nil {
+		return nil, err
 	}
 	return &Config{
-		Version:        V1Version,
+		Version:           V2Version,
+		ModuleIdentity:    moduleConfigs[0].ModuleIdentity,
+		Build:             moduleConfigs[0].Build,
+		Breaking:          moduleConfigs[0].Breaking,
+		Lint:              moduleConfigs[0].Lint,
+		ModuleConfigs:     moduleConfigs,
+		DefaultVisibility: VisibilityPrivate,
+		DigestAlgorithm:   bufmodule.DigestAlgorithmB1,
+	}, nil
+}
+
+// newModuleConfigV2 returns a new ModuleConfig for the external module config at
+// the index within the modules of the v2 configuration.
+func newModuleConfigV2(index int, externalModuleConfig ExternalModuleConfigV2) (*ModuleConfig, error) {
+	fieldPrefix := fmt.Sprintf("modules[%d]", index)
+	path := externalModuleConfig.Path
+	if path == "" {
+		path = "."
+	}
+	path, err := normalpath.NormalizeAndValidate(path)
+	if err != nil {
+		return nil, newConfigFieldError(fieldPrefix+".path", fmt.Errorf("module path: %w", err))
+	}
+	// v2 modules use the v1 build, breaking, and lint configurations
+	buildConfig, err := bufmodulebuild.NewConfigV1(externalModuleConfig.Build, externalModuleConfig.Deps...)
+	if err != nil {
+		// the build configuration includes the deps, so this is not attributed to
+		// a single field of the module
+		return nil, newConfigFieldError(fieldPrefix, fmt.Errorf("module %q: %w", path, err))
+	}
+	breakingConfig, breakingErr := bufbreaking.NewConfigV1(externalModuleConfig.Breaking)
+	lintConfig, lintErr := buflint.NewConfigV1(externalModuleConfig.Lint)
+	// report problems with both the breaking and lint sections at once
+	if err := multierr.Append(
+		newModuleConfigFieldError(fieldPrefix+".breaking", path, breakingErr),
+		newModuleConfigFieldError(fieldPrefix+".lint", path, lintErr),
+	); err != nil {
+		return nil, err
+	}
+	var moduleIdentity bufmodule.ModuleIdentity
+	if externalModuleConfig.Name != "" {
+		moduleIdentity, err = bufmodule.ModuleIdentityForString(externalModuleConfig.Name)
+		if err != nil {
+			return nil, newModuleConfigFieldError(fieldPrefix+".name", path, err)
+		}
+	}
+	return &ModuleConfig{
+		Path:           path,
 		ModuleIdentity: moduleIdentity,
 		Build:          buildConfig,
 		Breaking:       breakingConfig,
 		Lint:           lintConfig,
 	}, nil
 }
+
+func newRootModuleConfigs(
+	moduleIdentity bufmodule.ModuleIdentity,
+	buildConfig *bufmodulebuild.Config,
+	breakingConfig *bufbreaking.Config,
+	lintConfig *buflint.Config,
+) []*ModuleConfig {
+	return []*ModuleConfig{
+		{
+			Path:           ".",
+			ModuleIdentity: moduleIdentity,
+			Build:          buildConfig,
+			Breaking:       breakingConfig,
+			Lint:           lintConfig,
+		},
+	}
+}
+
+// validateModuleConfigPathsDoNotOverlap validates that no module path is
+// equal to or contains another module path.
+//
+// The moduleConfigs are expected to be sorted by path.
+func validateModuleConfigPathsDoNotOverlap(moduleConfigs []*ModuleConfig) error {
+	for i, moduleConfig := range moduleConfigs {
+		for _, otherModuleConfig := range moduleConfigs[i+1:] {
+			if normalpath.EqualsOrContainsPath(moduleConfig.Path, otherModuleConfig.Path, normalpath.Relative) ||
+				normalpath.EqualsOrContainsPath(otherModuleConfig.Path, moduleConfig.Path, normalpath.Relative) {
+				return fmt.Errorf("module paths %q and %q overlap", moduleConfig.Path, otherModuleConfig.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// validateModuleConfigPathsExist validates that every module path contains
+// at least one file in the readBucket.
+func validateModuleConfigPathsExist(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	moduleConfigs []*ModuleConfig,
+) error {
+	var err error
+	for _, moduleConfig := range moduleConfigs {
+		isEmpty, isEmptyErr := storage.IsEmpty(ctx, readBucket, moduleConfig.Path)
+		if isEmptyErr != nil {
+			return isEmptyErr
+		}
+		if isEmpty {
+			err = multierr.Append(err, fmt.Errorf("module path %q does not exist", moduleConfig.Path))
+		}
+	}
+	return err
+}
+
+// validateBuildExcludeGlobsMatch returns an error if any build exclude pattern of
+// the modules does not match a .proto file in the readBucket, so that stale patterns
+// are caught.
+//
+// Patterns are relative to the module roots.
+func validateBuildExcludeGlobsMatch(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	moduleConfigs []*ModuleConfig,
+) error {
+	var err error
+	for _, moduleConfig := range moduleConfigs {
+		if moduleConfig.Build == nil || len(moduleConfig.Build.ExcludeGlobs) == 0 {
+			continue
+		}
+		unmatchedExcludeGlobs := make(map[string]struct{}, len(moduleConfig.Build.ExcludeGlobs))
+		for _, excludeGlob := range moduleConfig.Build.ExcludeGlobs {
+			unmatchedExcludeGlobs[excludeGlob] = struct{}{}
+		}
+		prefix := moduleConfig.Path
+		if prefix == "." {
+			prefix = ""
+		}
+		if walkErr := storage.MapReadBucket(readBucket, storage.MatchPathExt(".proto")).Walk(
+			ctx,
+			prefix,
+			func(objectInfo storage.ObjectInfo) error {
+				path, err := normalpath.Rel(moduleConfig.Path, objectInfo.Path())
+				if err != nil {
+					return err
+				}
+				for excludeGlob := range unmatchedExcludeGlobs {
+					if normalpath.MatchGlob(excludeGlob, path) {
+						delete(unmatchedExcludeGlobs, excludeGlob)
+					}
+				}
+				return nil
+			},
+		); walkErr != nil {
+			return walkErr
+		}
+		// iterate over the slice to keep the errors in a deterministic order
+		for _, excludeGlob := range moduleConfig.Build.ExcludeGlobs {
+			if _, ok := unmatchedExcludeGlobs[excludeGlob]; ok {
+				err = multierr.Append(err, fmt.Errorf("exclude %q in module %q did not match any files", excludeGlob, moduleConfig.Path))
+			}
+		}
+	}
+	return err
+}
+
+// validateLintIgnoreOnlyPathsExist returns an error if any ignore_only path of the
+// lint configurations of the modules is neither a file nor a directory in the readBucket,
+// so that rules are not grandfathered for files that no longer exist.
+//
+// Paths are relative to the roots of the modules.
+func validateLintIgnoreOnlyPathsExist(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	moduleConfigs []*ModuleConfig,
+) error {
+	var err error
+	for _, moduleConfig := range moduleConfigs {
+		if moduleConfig.Lint == nil || moduleConfig.Build == nil || len(moduleConfig.Lint.IgnoreIDToRootPaths) == 0 {
+			continue
+		}
+		ids := make([]string, 0, len(moduleConfig.Lint.IgnoreIDToRootPaths))
+		for id := range moduleConfig.Lint.IgnoreIDToRootPaths {
+			ids = append(ids, id)
+		}
+		// sort to keep the errors in a deterministic order
+		sort.Strings(ids)
+		for _, id := range ids {
+			for _, path := range stringutil.MapToSortedSlice(moduleConfig.Lint.IgnoreIDToRootPaths[id]) {
+				exists, existsErr := lintIgnorePathExists(ctx, readBucket, moduleConfig, path)
+				if existsErr != nil {
+					return existsErr
+				}
+				if !exists {
+					err = multierr.Append(err, fmt.Errorf("ignore_only path %q for %s in module %q does not exist", path, id, moduleConfig.Path))
+				}
+			}
+		}
+	}
+	return err
+}
+
+// lintIgnorePathExists returns true if the path is a file or a non-empty directory
+// within any root of the module.
+func lintIgnorePathExists(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	moduleConfig *ModuleConfig,
+	path string,
+) (bool, error) {
+	for root := range moduleConfig.Build.RootToExcludes {
+		fullPath := normalpath.Join(moduleConfig.Path, root, path)
+		// check for a directory first, as some buckets return a system error
+		// when a directory is stat'ed
+		isEmpty, err := storage.IsEmpty(ctx, readBucket, fullPath)
+		if err != nil {
+			return false, err
+		}
+		if !isEmpty {
+			return true, nil
+		}
+		exists, err := storage.Exists(ctx, readBucket, fullPath)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// readConfigData reads the data of the configuration file in the readBucket,
+// along with the external path of the file.
+//
+// If there is no configuration file, this returns nil data.
+func (p *provider) readConfigData(ctx context.Context, readBucket storage.ReadBucket) (_ []byte, _ string, retErr error) {
+	if p.withoutV1Beta1Fallback {
+		if err := validateNoV1Beta1ConfigFile(ctx, readBucket); err != nil {
+			return nil, "", err
+		}
+	}
+	readObjectCloser, err := readBucket.Get(ctx, ExternalConfigFilePath)
+	if err != nil {
+		if !storage.IsNotExist(err) {
+			return nil, "", err
+		}
+		if p.withoutV1Beta1Fallback {
+			return nil, "", nil
+		}
+		// Look for old config file
+		readObjectCloser, err = readBucket.Get(ctx, ExternalConfigV1Beta1FilePath)
+		if err != nil {
+			if storage.IsNotExist(err) {
+				return nil, "", nil
+			}
+			return nil, "", err
+		}
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readObjectCloser.Close())
+	}()
+	data, err := io.ReadAll(readObjectCloser)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, readObjectCloser.ExternalPath(), nil
+}
+
+func detectConfigDataFormat(data []byte) ConfigDataFormat {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return ConfigDataFormatJSON
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if tomlFirstLineRegexp.Match(line) {
+			return ConfigDataFormatTOML
+		}
+		return ConfigDataFormatYAML
+	}
+	return ConfigDataFormatYAML
+}
+
+// unmarshalConfigDataNonStrict unmarshals the JSON, YAML, or TOML data.
+func unmarshalConfigDataNonStrict(data []byte, v interface{}) error {
+	if detectConfigDataFormat(data) == ConfigDataFormatTOML {
+		// The TOML decoder decodes arrays of tables as []map[string]interface{}, so
+		// we convert the data to YAML to get the same types as for JSON and YAML.
+		tomlValue := make(map[string]interface{})
+		if err := encoding.UnmarshalTOMLNonStrict(data, &tomlValue); err != nil {
+			return err
+		}
+		yamlData, err := encoding.MarshalYAML(tomlValue)
+		if err != nil {
+			return err
+		}
+		return encoding.UnmarshalYAMLNonStrict(yamlData, v)
+	}
+	return encoding.UnmarshalJSONOrYAMLNonStrict(data, v)
+}
@@ -0,0 +1,661 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetConfigForDataDefaultVisibility(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.Equal(t, VisibilityPrivate, config.DefaultVisibility)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+default_visibility: public`))
+	require.NoError(t, err)
+	assert.Equal(t, VisibilityPublic, config.DefaultVisibility)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+default_visibility: protected`))
+	require.Error(t, err)
+}
+
+func TestGetConfigForDataDigestAlgorithm(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.Equal(t, bufmodule.DigestAlgorithmB1, config.DigestAlgorithm)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+digest_algorithm: b2`))
+	require.NoError(t, err)
+	assert.Equal(t, bufmodule.DigestAlgorithmB2, config.DigestAlgorithm)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+digest_algorithm: md5`))
+	require.Error(t, err)
+}
+
+func TestGetConfigForDataEnabled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+breaking:
+  enabled: false`))
+	require.NoError(t, err)
+	assert.True(t, config.Breaking.Disabled)
+	assert.False(t, config.Lint.Disabled)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  enabled: false
+breaking:
+  enabled: true`))
+	require.NoError(t, err)
+	assert.False(t, config.Breaking.Disabled)
+	assert.True(t, config.Lint.Disabled)
+}
+
+func TestGetConfigForDataDependencyImportAliases(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/module
+deps:
+  - buf.build/acme/weather
+dep_import_aliases:
+  buf.build/acme/weather:
+    vendor/weather/: ./weather`))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]map[string]string{
+			"buf.build/acme/weather": {
+				"vendor/weather": "weather",
+			},
+		},
+		config.DependencyImportAliases,
+	)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/module
+deps:
+  - buf.build/acme/weather
+dep_import_aliases:
+  buf.build/acme/other:
+    vendor/weather: weather`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not a dependency")
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/module
+deps:
+  - buf.build/acme/weather
+dep_import_aliases:
+  buf.build/acme/weather:
+    vendor/weather: weather
+    third_party/weather: weather`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `target prefix "weather" is the target of both`)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/module
+deps:
+  - buf.build/acme/weather
+dep_import_aliases:
+  buf.build/acme/weather:
+    a: b
+    b: a`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "alias cycle [a b a]")
+}
+
+func TestGetConfigForDataParallelism(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, config.Parallelism)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+parallelism: 2`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, config.Parallelism)
+	assert.Equal(t, 2, config.Lint.Parallelism)
+	assert.Equal(t, 2, config.Breaking.Parallelism)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+parallelism: -1`))
+	require.Error(t, err)
+}
+
+func TestGetConfigForDataOutputFormat(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.Equal(t, bufanalysis.Format(0), config.OutputFormat)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+output_format: sarif`))
+	require.NoError(t, err)
+	assert.Equal(t, bufanalysis.FormatSARIF, config.OutputFormat)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+output_format: xml`))
+	require.Error(t, err)
+}
+
+func TestGetConfigDuplicateAndUnknownKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+lint:
+  use:
+    - BASIC
+`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 5: mapping key "lint" already defined at line 2`)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+  excepts:
+    - ENUM_PASCAL_CASE
+`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 5: field excepts not found")
+	_, err = provider.GetConfigForData(ctx, []byte(`{
+  "version": "v1",
+  "lint": {"use": ["DEFAULT"]},
+  "lint": {"use": ["BASIC"]}
+}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 4: duplicate key "lint"`)
+	_, err = provider.GetConfigForData(ctx, []byte(`{"version": "v1", "foo": "bar"}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "foo"`)
+}
+
+func TestGetConfigBuildExcludeGlobs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+build:
+  exclude:
+    - "**/vendor/**"
+`),
+			"a/vendor/b.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"**/vendor/**"}, config.Build.ExcludeGlobs)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+build:
+  exclude:
+    - "**/vendor/**"
+    - gen/*.proto
+`),
+			"a/vendor/b.proto": []byte(`syntax = "proto3";`),
+			"gen/c.txt":        []byte(`not proto`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `exclude "gen/*.proto" in module "." did not match any files`)
+	assert.NotContains(t, err.Error(), "vendor")
+	// patterns are relative to the module root, not the bucket root
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v2
+modules:
+  - path: proto
+    build:
+      exclude:
+        - gen/*.proto
+`),
+			"proto/gen/a.proto": []byte(`syntax = "proto3";`),
+			"gen/b.proto":       []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v2
+modules:
+  - path: proto
+    build:
+      exclude:
+        - proto/gen/*.proto
+`),
+			"proto/gen/a.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `exclude "proto/gen/*.proto" in module "proto" did not match any files`)
+}
+
+func TestGetConfigV2(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v2
+modules:
+  - path: proto/public
+    name: buf.build/acme/public
+    lint:
+      use:
+        - DEFAULT
+  - path: proto/internal
+    lint:
+      use:
+        - BASIC
+    breaking:
+      use:
+        - WIRE
+`),
+			"proto/public/a.proto":   []byte(`syntax = "proto3";`),
+			"proto/internal/b.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, V2Version, config.Version)
+	require.Len(t, config.ModuleConfigs, 2)
+	assert.Equal(t, "proto/internal", config.ModuleConfigs[0].Path)
+	assert.Nil(t, config.ModuleConfigs[0].ModuleIdentity)
+	assert.Equal(t, "proto/public", config.ModuleConfigs[1].Path)
+	assert.Equal(t, "buf.build/acme/public", config.ModuleConfigs[1].ModuleIdentity.IdentityString())
+	assert.NotEqual(t, len(config.ModuleConfigs[0].Lint.Rules), len(config.ModuleConfigs[1].Lint.Rules))
+	assert.Equal(t, config.ModuleConfigs[0].Lint, config.Lint)
+	assert.Equal(t, config.ModuleConfigs[0].Breaking, config.Breaking)
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v2
+modules:
+  - path: proto/public
+  - path: proto/internal
+`),
+			"proto/public/a.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `module path "proto/internal" does not exist`)
+}
+
+func TestGetConfigForDataV2(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v2`))
+	require.NoError(t, err)
+	require.Len(t, config.ModuleConfigs, 1)
+	assert.Equal(t, ".", config.ModuleConfigs[0].Path)
+	assert.NotNil(t, config.Lint)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v2
+modules:
+  - path: proto
+  - path: proto/internal
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `module paths "proto" and "proto/internal" overlap`)
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v2
+modules:
+  - path: ../proto
+`))
+	require.Error(t, err)
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	require.Len(t, config.ModuleConfigs, 1)
+	assert.Equal(t, ".", config.ModuleConfigs[0].Path)
+	assert.Equal(t, config.Lint, config.ModuleConfigs[0].Lint)
+}
+
+func TestGetConfigLintIgnoreOnly(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+lint:
+  ignore_only:
+    FIELD_LOWER_SNAKE_CASE:
+      - legacy/old.proto
+    ENUM_PASCAL_CASE:
+      - legacy
+`),
+			"legacy/old.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]map[string]struct{}{
+			"FIELD_LOWER_SNAKE_CASE": {"legacy/old.proto": {}},
+			"ENUM_PASCAL_CASE":       {"legacy": {}},
+		},
+		config.Lint.IgnoreIDToRootPaths,
+	)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+lint:
+  ignore_only:
+    FIELD_LOWER_SNAKE_CASE:
+      - legacy/old.proto
+      - legacy/removed.proto
+`),
+			"legacy/old.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `ignore_only path "legacy/removed.proto" for FIELD_LOWER_SNAKE_CASE in module "." does not exist`)
+	assert.NotContains(t, err.Error(), "old.proto")
+	// v1beta1 paths are relative to the roots
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigV1Beta1FilePath: []byte(`version: v1beta1
+build:
+  roots:
+    - proto
+lint:
+  ignore_only:
+    FIELD_LOWER_SNAKE_CASE:
+      - legacy/old.proto
+`),
+			"proto/legacy/old.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+lint:
+  ignore_only:
+    FIELD_LOWER_SNAKE_CAS:
+      - legacy/old.proto
+`),
+			"legacy/old.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, readBucket)
+	require.Error(t, err)
+}
+
+func TestGetConfigAndData(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop(), ProviderWithEnvLookup(newTestEnvLookup(map[string]string{"BUF_OWNER": "acme"})))
+	data := []byte(`version: v1
+name: buf.build/${BUF_OWNER}/weather
+`)
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: data,
+		},
+	)
+	require.NoError(t, err)
+	config, configData, err := provider.GetConfigAndData(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/acme/weather", config.ModuleIdentity.IdentityString())
+	// the data is returned before environment variables are expanded
+	assert.Equal(t, data, configData)
+	assert.Equal(t, ConfigDataFormatYAML, DetectConfigDataFormat(configData))
+
+	data = []byte(` {"version": "v1"}`)
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: data,
+		},
+	)
+	require.NoError(t, err)
+	_, configData, err = provider.GetConfigAndData(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, data, configData)
+	assert.Equal(t, ConfigDataFormatJSON, DetectConfigDataFormat(configData))
+
+	readBucket, err = storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	config, configData, err = provider.GetConfigAndData(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, V1Beta1Version, config.Version)
+	assert.Nil(t, configData)
+}
+
+func TestGetConfigForDataDepConstraint(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/orders
+deps:
+  - buf.build/acme/payments:>=v1.2.0 <v2.0.0
+  - buf.build/acme/shipping`))
+	require.NoError(t, err)
+	require.Len(t, config.Build.DependencyModuleReferences, 2)
+	constraint := config.Build.DependencyModuleReferences[0].Constraint()
+	require.NotNil(t, constraint)
+	assert.Equal(t, ">=v1.2.0 <v2.0.0", constraint.String())
+	assert.Nil(t, config.Build.DependencyModuleReferences[1].Constraint())
+
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/orders
+deps:
+  - buf.build/acme/payments:>=v1.2 <v2.0.0`))
+	assert.Error(t, err)
+}
+
+func TestGetConfigForDataBreakingAgainst(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+breaking:
+  use:
+    - FILE
+  against: https://github.com/acme/protos.git#branch=main,subdir=proto`))
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/acme/protos.git#branch=main,subdir=proto", config.Breaking.Against)
+
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+breaking:
+  against: testdata/image.bin`))
+	require.NoError(t, err)
+	assert.Equal(t, "testdata/image.bin", config.Breaking.Against)
+
+	for _, against := range []string{
+		`"#format=bin"`,
+		`"image.bin#format"`,
+		`"image.bin#format=bin,"`,
+		`"foo://bar"`,
+		`"https://"`,
+	} {
+		_, err := provider.GetConfigForData(ctx, []byte(`version: v1
+breaking:
+  against: `+against))
+		assert.Error(t, err, against)
+	}
+}
+
+func TestGetConfigWithoutV1Beta1Fallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	v1Beta1ReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigV1Beta1FilePath: []byte("version: v1beta1\nname: buf.build/foo/bar\n"),
+		},
+	)
+	require.NoError(t, err)
+	config, err := NewProvider(zap.NewNop()).GetConfig(ctx, v1Beta1ReadBucket)
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+
+	provider := NewProvider(zap.NewNop(), ProviderWithoutV1Beta1Fallback())
+	_, err = provider.GetConfig(ctx, v1Beta1ReadBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found legacy configuration file buf.yaml")
+	_, err = provider.GetConfigForBucketAndOverride(ctx, v1Beta1ReadBucket, []byte("version: v1beta1"))
+	assert.Error(t, err)
+
+	bothReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath:        []byte("version: v1\n"),
+			ExternalConfigV1Beta1FilePath: []byte("version: v1beta1\n"),
+		},
+	)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, bothReadBucket)
+	assert.Error(t, err)
+
+	v1ReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte("version: v1\n"),
+		},
+	)
+	require.NoError(t, err)
+	config, err = provider.GetConfig(ctx, v1ReadBucket)
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, config.Version)
+	emptyReadBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	_, err = provider.GetConfig(ctx, emptyReadBucket)
+	assert.NoError(t, err)
+}
+
+func TestGetConfigForDataTOML(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	tomlData := []byte(`# buf.toml
+version = "v1"
+name = "buf.build/foo/bar"
+deps = ["buf.build/foo/baz"]
+
+[build]
+excludes = ["foo"]
+
+[lint]
+use = ["DEFAULT"]
+except = ["ENUM_ZERO_VALUE_SUFFIX"]
+
+[lint.ignore_only]
+ENUM_PASCAL_CASE = ["bar"]
+
+[breaking]
+use = ["WIRE"]
+`)
+	assert.Equal(t, ConfigDataFormatTOML, DetectConfigDataFormat(tomlData))
+	tomlConfig, err := provider.GetConfigForData(ctx, tomlData)
+	require.NoError(t, err)
+	yamlConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+build:
+  excludes:
+    - foo
+lint:
+  use:
+    - DEFAULT
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX
+  ignore_only:
+    ENUM_PASCAL_CASE:
+      - bar
+breaking:
+  use:
+    - WIRE`))
+	require.NoError(t, err)
+	assert.True(t, ConfigEqual(yamlConfig, tomlConfig))
+
+	// validation is the same regardless of the format
+	_, err = provider.GetConfigForData(ctx, []byte("version = \"v1\"\n[lint]\nuse = [\"NOT_A_RULE\"]"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"NOT_A_RULE" is not a known id or category`)
+	_, err = provider.GetConfigForData(ctx, []byte("version = \"v1\"\nunknown = true"))
+	assert.Error(t, err)
+	_, err = provider.GetConfigForData(ctx, []byte("version = \"v1\"\nversion = \"v1\""))
+	assert.Error(t, err)
+
+	tomlConfig, err = provider.GetConfigForData(ctx, []byte(`version = "v2"
+
+[[modules]]
+path = "a"
+
+[[modules]]
+path = "b"
+[modules.lint]
+use = ["BASIC"]
+`))
+	require.NoError(t, err)
+	yamlConfig, err = provider.GetConfigForData(ctx, []byte(`version: v2
+modules:
+  - path: a
+  - path: b
+    lint:
+      use:
+        - BASIC`))
+	require.NoError(t, err)
+	assert.True(t, ConfigEqual(yamlConfig, tomlConfig))
+}
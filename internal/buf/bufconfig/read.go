@@ -16,11 +16,20 @@ package bufconfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"go.uber.org/multierr"
 )
 
 func readConfig(
@@ -33,25 +42,365 @@ func readConfig(
 	for _, option := range options {
 		option(readConfigOptions)
 	}
+	withoutV1Beta1Fallback := providerWithoutV1Beta1Fallback(provider)
+	provider = newOSEnvLookupProvider(provider)
 	if readConfigOptions.override != "" {
 		var data []byte
 		var err error
-		switch filepath.Ext(readConfigOptions.override) {
-		case ".json", ".yaml", ".yml":
-			data, err = os.ReadFile(readConfigOptions.override)
+		switch {
+		case readConfigOptions.override == StdinOverride:
+			stdin := readConfigOptions.stdin
+			if stdin == nil {
+				stdin = os.Stdin
+			}
+			data, err = io.ReadAll(stdin)
+			if err != nil {
+				return nil, fmt.Errorf("could not read configuration from stdin: %v", err)
+			}
+		case strings.HasPrefix(readConfigOptions.override, "http://"),
+			strings.HasPrefix(readConfigOptions.override, "https://"):
+			data, err = fetchConfigData(ctx, readConfigOptions.httpClient, readConfigOptions.override)
 			if err != nil {
-				return nil, fmt.Errorf("could not read file: %v", err)
+				return nil, err
 			}
 		default:
-			data = []byte(readConfigOptions.override)
+			switch filepath.Ext(readConfigOptions.override) {
+			case ".json", ".yaml", ".yml", ".toml":
+				data, err = os.ReadFile(readConfigOptions.override)
+				if err != nil {
+					return nil, fmt.Errorf("could not read file: %v", err)
+				}
+			default:
+				data = []byte(readConfigOptions.override)
+			}
+		}
+		if readConfigOptions.sections != nil {
+			data, err = filterConfigDataSections(data, readConfigOptions.sections)
+			if err != nil {
+				return nil, err
+			}
+		}
+		config, err := provider.GetConfigForData(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateConfigMinimumVersion(config, readConfigOptions.override, readConfigOptions.minimumVersion); err != nil {
+			return nil, err
+		}
+		return validateReadConfig(config, readConfigOptions)
+	}
+	if readConfigOptions.requireConfig {
+		exists, err := configExists(ctx, readBucket, withoutV1Beta1Fallback)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf(
+				"no configuration file found, checked %s",
+				strings.Join(configFilePaths(withoutV1Beta1Fallback), " and "),
+			)
+		}
+	}
+	config, err := readConfigForBucket(ctx, provider, readBucket, readConfigOptions.sections, withoutV1Beta1Fallback)
+	if err != nil {
+		return nil, err
+	}
+	if readConfigOptions.minimumVersion != "" {
+		_, filePath, err := readConfigFileData(ctx, readBucket, withoutV1Beta1Fallback)
+		if err != nil && !storage.IsNotExist(err) {
+			return nil, err
+		}
+		// if there is no configuration file, the default configuration is used,
+		// which is not subject to the minimum version
+		if filePath != "" {
+			if err := validateConfigMinimumVersion(config, filePath, readConfigOptions.minimumVersion); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return validateReadConfig(config, readConfigOptions)
+}
+
+// fetchConfigData fetches the configuration data at the URL.
+//
+// If httpClient is nil, http.DefaultClient is used.
+func fetchConfigData(ctx context.Context, httpClient *http.Client, url string) (_ []byte, retErr error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %v", url, err)
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %v", url, err)
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, response.Body.Close())
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not fetch %s: unexpected status %s", url, response.Status)
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch %s: %v", url, err)
+	}
+	return data, nil
+}
+
+// readConfigForBucket gets the Config for the bucket, only reading the given sections.
+//
+// If sections is nil, all sections are read.
+func readConfigForBucket(
+	ctx context.Context,
+	provider Provider,
+	readBucket storage.ReadBucket,
+	sections map[ConfigSection]struct{},
+	withoutV1Beta1Fallback bool,
+) (*Config, error) {
+	if sections == nil {
+		return provider.GetConfig(ctx, readBucket)
+	}
+	data, filePath, err := readConfigFileData(ctx, readBucket, withoutV1Beta1Fallback)
+	if err != nil {
+		if storage.IsNotExist(err) {
+			// the default configuration has no sections to filter
+			return provider.GetConfig(ctx, readBucket)
+		}
+		return nil, err
+	}
+	data, err = filterConfigDataSections(data, sections)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	config, err := provider.GetConfigForData(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if config.Version == V2Version {
+		if err := validateModuleConfigPathsExist(ctx, readBucket, config.ModuleConfigs); err != nil {
+			return nil, fmt.Errorf("%s: %w", filePath, err)
 		}
-		return provider.GetConfigForData(ctx, data)
 	}
-	return provider.GetConfig(ctx, readBucket)
+	if err := validateBuildExcludeGlobsMatch(ctx, readBucket, config.ModuleConfigs); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	if err := validateLintIgnoreOnlyPathsExist(ctx, readBucket, config.ModuleConfigs); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	config, err = readLintBaselines(ctx, readBucket, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return config, nil
+}
+
+// validateConfigMinimumVersion returns an error if the version of the Config read
+// from the file is older than the minimum version.
+//
+// If minimumVersion is empty, this always returns nil.
+func validateConfigMinimumVersion(config *Config, filePath string, minimumVersion string) error {
+	if minimumVersion == "" {
+		return nil
+	}
+	minimumVersionIndex, err := versionIndex(minimumVersion)
+	if err != nil {
+		return fmt.Errorf("invalid minimum config version: %w", err)
+	}
+	configVersionIndex, err := versionIndex(config.Version)
+	if err != nil {
+		return err
+	}
+	if configVersionIndex < minimumVersionIndex {
+		return fmt.Errorf("%s has config version %q, but the minimum config version is %q", filePath, config.Version, minimumVersion)
+	}
+	return nil
+}
+
+func readConfigFromFS(
+	ctx context.Context,
+	provider Provider,
+	fsys fs.FS,
+	options ...ReadConfigOption,
+) (*Config, error) {
+	pathToData := make(map[string][]byte)
+	for _, filePath := range []string{ExternalConfigFilePath, ExternalConfigV1Beta1FilePath} {
+		data, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		pathToData[filePath] = data
+	}
+	readBucket, err := storagemem.NewReadBucket(pathToData)
+	if err != nil {
+		return nil, err
+	}
+	return readConfig(ctx, provider, readBucket, options...)
+}
+
+func validateReadConfig(config *Config, readConfigOptions *readConfigOptions) (*Config, error) {
+	if readConfigOptions.identitySuffix != "" && config.ModuleIdentity != nil {
+		// we parse the string representation so that the suffix is validated
+		// the same way as the name within the configuration file
+		moduleIdentity, err := bufmodule.ModuleIdentityForString(
+			config.ModuleIdentity.IdentityString() + readConfigOptions.identitySuffix,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply identity suffix %q: %w", readConfigOptions.identitySuffix, err)
+		}
+		config.ModuleIdentity = moduleIdentity
+		// the config may be a shallow copy of a cached config, so we copy the
+		// ModuleConfigs instead of modifying the shared slice
+		if len(config.ModuleConfigs) > 0 {
+			moduleConfigs := make([]*ModuleConfig, len(config.ModuleConfigs))
+			copy(moduleConfigs, config.ModuleConfigs)
+			rootModuleConfig := *moduleConfigs[0]
+			rootModuleConfig.ModuleIdentity = moduleIdentity
+			moduleConfigs[0] = &rootModuleConfig
+			config.ModuleConfigs = moduleConfigs
+		}
+	}
+	if readConfigOptions.offlineValidation {
+		if err := validateOffline(config); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+func readWorkspaceConfigs(
+	ctx context.Context,
+	provider Provider,
+	workspaceBucket storage.ReadBucket,
+	moduleDirs []string,
+) (map[string]*Config, error) {
+	moduleDirToConfig := make(map[string]*Config, len(moduleDirs))
+	for _, moduleDir := range moduleDirs {
+		normalizedModuleDir, err := normalpath.NormalizeAndValidate(moduleDir)
+		if err != nil {
+			return nil, fmt.Errorf("module directory %q is invalid: %w", moduleDir, err)
+		}
+		if _, ok := moduleDirToConfig[normalizedModuleDir]; ok {
+			return nil, fmt.Errorf("module directory %q was specified more than once", moduleDir)
+		}
+		moduleBucket := storage.MapReadBucket(workspaceBucket, storage.MapOnPrefix(normalizedModuleDir))
+		exists, err := ConfigExists(ctx, moduleBucket)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("module directory %q does not contain a configuration file", moduleDir)
+		}
+		config, err := provider.GetConfig(ctx, moduleBucket)
+		if err != nil {
+			return nil, err
+		}
+		moduleDirToConfig[normalizedModuleDir] = config
+	}
+	return moduleDirToConfig, nil
+}
+
+// readConfigFileData reads the data of the configuration file in the bucket, and
+// returns the data and the path of the file.
+//
+// If ExternalConfigFilePath does not exist, this falls back to ExternalConfigV1Beta1FilePath,
+// unless withoutV1Beta1Fallback is true, in which case only ExternalConfigFilePath is read
+// and an error is returned if ExternalConfigV1Beta1FilePath exists.
+//
+// Returns an error that fulfills storage.IsNotExist if there is no configuration file.
+func readConfigFileData(ctx context.Context, readBucket storage.ReadBucket, withoutV1Beta1Fallback bool) ([]byte, string, error) {
+	if withoutV1Beta1Fallback {
+		if err := validateNoV1Beta1ConfigFile(ctx, readBucket); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, filePath := range configFilePaths(withoutV1Beta1Fallback) {
+		data, err := storage.ReadPath(ctx, readBucket, filePath)
+		if err != nil {
+			if storage.IsNotExist(err) {
+				continue
+			}
+			return nil, "", err
+		}
+		return data, filePath, nil
+	}
+	return nil, "", storage.NewErrNotExist(ExternalConfigFilePath)
+}
+
+// configExists checks if a configuration file exists in the bucket.
+//
+// If withoutV1Beta1Fallback is true, only ExternalConfigFilePath is checked, and an
+// error is returned if ExternalConfigV1Beta1FilePath exists.
+func configExists(ctx context.Context, readBucket storage.ReadBucket, withoutV1Beta1Fallback bool) (bool, error) {
+	if withoutV1Beta1Fallback {
+		if err := validateNoV1Beta1ConfigFile(ctx, readBucket); err != nil {
+			return false, err
+		}
+	}
+	for _, filePath := range configFilePaths(withoutV1Beta1Fallback) {
+		exists, err := storage.Exists(ctx, readBucket, filePath)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// validateNoV1Beta1ConfigFile returns an error if ExternalConfigV1Beta1FilePath exists
+// in the bucket.
+func validateNoV1Beta1ConfigFile(ctx context.Context, readBucket storage.ReadBucket) error {
+	exists, err := storage.Exists(ctx, readBucket, ExternalConfigV1Beta1FilePath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf(
+			"found legacy configuration file %s, move its contents to %s and delete it",
+			ExternalConfigV1Beta1FilePath,
+			ExternalConfigFilePath,
+		)
+	}
+	return nil
+}
+
+// configFilePaths returns the paths of the configuration files to check, in order.
+func configFilePaths(withoutV1Beta1Fallback bool) []string {
+	if withoutV1Beta1Fallback {
+		return []string{ExternalConfigFilePath}
+	}
+	return []string{ExternalConfigFilePath, ExternalConfigV1Beta1FilePath}
+}
+
+// providerWithoutV1Beta1Fallback returns true if the Provider was created with
+// ProviderWithoutV1Beta1Fallback.
+func providerWithoutV1Beta1Fallback(p Provider) bool {
+	switch p := p.(type) {
+	case *provider:
+		return p.withoutV1Beta1Fallback
+	case *envLookupProvider:
+		return p.provider.withoutV1Beta1Fallback
+	default:
+		return false
+	}
 }
 
 type readConfigOptions struct {
-	override string
+	override          string
+	offlineValidation bool
+	identitySuffix    string
+	minimumVersion    string
+	requireConfig     bool
+	httpClient        *http.Client
+	stdin             io.Reader
+	// sections is nil if all sections are read.
+	sections map[ConfigSection]struct{}
 }
 
 func newReadConfigOptions() *readConfigOptions {
@@ -0,0 +1,425 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReadWorkspaceConfigs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"paymentapis/buf.mod": []byte(`version: v1
+name: buf.build/acme/paymentapis`),
+			"petapis/buf.mod": []byte(`version: v1
+name: buf.build/acme/petapis`),
+			"other/foo.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	moduleDirToConfig, err := ReadWorkspaceConfigs(ctx, provider, readBucket, []string{"paymentapis", "./petapis"})
+	require.NoError(t, err)
+	require.Len(t, moduleDirToConfig, 2)
+	require.NotNil(t, moduleDirToConfig["paymentapis"].ModuleIdentity)
+	assert.Equal(t, "buf.build/acme/paymentapis", moduleDirToConfig["paymentapis"].ModuleIdentity.IdentityString())
+	require.NotNil(t, moduleDirToConfig["petapis"].ModuleIdentity)
+	assert.Equal(t, "buf.build/acme/petapis", moduleDirToConfig["petapis"].ModuleIdentity.IdentityString())
+
+	_, err = ReadWorkspaceConfigs(ctx, provider, readBucket, []string{"paymentapis", "other"})
+	assert.Error(t, err)
+}
+
+func TestReadConfigWithOfflineValidation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+  - buf.build/foo/qux:main
+lint:
+  use:
+    - DEFAULT`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := ReadConfig(
+		ctx,
+		NewProvider(zap.NewNop()),
+		readBucket,
+		ReadConfigWithOfflineValidation(),
+	)
+	require.NoError(t, err)
+	require.Len(t, config.Build.DependencyModuleReferences, 2)
+	assert.Equal(t, "buf.build/foo/baz", config.Build.DependencyModuleReferences[0].IdentityString())
+}
+
+func TestReadConfigWithIdentitySuffix(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/acme/weather`),
+		},
+	)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithIdentitySuffix("-staging"))
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/acme/weather-staging", config.ModuleIdentity.IdentityString())
+	require.Len(t, config.ModuleConfigs, 1)
+	assert.Equal(t, "buf.build/acme/weather-staging", config.ModuleConfigs[0].ModuleIdentity.IdentityString())
+	// the suffix is not applied to the cached config
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithIdentitySuffix(""))
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/acme/weather", config.ModuleIdentity.IdentityString())
+	require.Len(t, config.ModuleConfigs, 1)
+	assert.Equal(t, "buf.build/acme/weather", config.ModuleConfigs[0].ModuleIdentity.IdentityString())
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithIdentitySuffix("/staging"))
+	require.Error(t, err)
+}
+
+func TestReadConfigFromFS(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := ReadConfigFromFS(
+		ctx,
+		provider,
+		fstest.MapFS{
+			ExternalConfigFilePath: &fstest.MapFile{
+				Data: []byte(`version: v1
+name: buf.build/foo/bar`),
+			},
+			"foo.proto": &fstest.MapFile{
+				Data: []byte(`syntax = "proto3";`),
+			},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, config.Version)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+
+	config, err = ReadConfigFromFS(ctx, provider, fstest.MapFS{})
+	require.NoError(t, err)
+	assert.Nil(t, config.ModuleIdentity)
+}
+
+func TestReadConfigWithMinimumVersion(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigV1Beta1FilePath: []byte(`version: v1beta1`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithMinimumVersion(V1Version))
+	require.Error(t, err)
+	assert.Equal(t, `buf.yaml has config version "v1beta1", but the minimum config version is "v1"`, err.Error())
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithMinimumVersion(V1Beta1Version))
+	require.NoError(t, err)
+	assert.Equal(t, V1Beta1Version, config.Version)
+	config, err = ReadConfig(ctx, provider, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, V1Beta1Version, config.Version)
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithMinimumVersion("v2"))
+	require.Error(t, err)
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1`),
+		},
+	)
+	require.NoError(t, err)
+	config, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithMinimumVersion(V1Version))
+	require.NoError(t, err)
+	assert.Equal(t, V1Version, config.Version)
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(`version: v1beta1`), ReadConfigWithMinimumVersion(V1Version))
+	require.Error(t, err)
+}
+
+func TestReadConfigWithRequireConfig(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	_, err = ReadConfig(ctx, provider, readBucket)
+	require.NoError(t, err)
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithRequireConfig())
+	require.Error(t, err)
+	assert.Equal(t, "no configuration file found, checked buf.mod and buf.yaml", err.Error())
+	_, err = ReadConfig(ctx, provider, readBucket, ReadConfigWithRequireConfig(), ReadConfigWithOverride(`version: v1`))
+	require.NoError(t, err)
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigV1Beta1FilePath: []byte(`version: v1beta1`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithRequireConfig())
+	require.NoError(t, err)
+	assert.Equal(t, V1Beta1Version, config.Version)
+}
+
+func TestReadConfigWithoutV1Beta1Fallback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop(), ProviderWithoutV1Beta1Fallback())
+	emptyReadBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	_, err = ReadConfig(ctx, provider, emptyReadBucket, ReadConfigWithRequireConfig())
+	require.Error(t, err)
+	assert.Equal(t, "no configuration file found, checked buf.mod", err.Error())
+
+	v1Beta1ReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigV1Beta1FilePath: []byte(`version: v1beta1`),
+		},
+	)
+	require.NoError(t, err)
+	for _, options := range [][]ReadConfigOption{
+		{ReadConfigWithRequireConfig()},
+		{ReadConfigWithMinimumVersion(V1Version)},
+		{ReadConfigWithSections(ConfigSectionBuild)},
+	} {
+		_, err = ReadConfig(ctx, provider, v1Beta1ReadBucket, options...)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "found legacy configuration file buf.yaml")
+	}
+
+	v1ReadBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte("version: v1\nname: buf.build/foo/bar"),
+		},
+	)
+	require.NoError(t, err)
+	config, err := ReadConfig(
+		ctx,
+		provider,
+		v1ReadBucket,
+		ReadConfigWithRequireConfig(),
+		ReadConfigWithMinimumVersion(V1Version),
+		ReadConfigWithSections(ConfigSectionBuild),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+}
+
+func TestReadConfigWithSections(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+name: buf.build/foo/bar
+deps:
+  - buf.build/foo/baz
+build:
+  excludes:
+    - foo
+lint:
+  use:
+    - NOT_A_RULE
+breaking:
+  use:
+    - WIRE`),
+		},
+	)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	_, err = ReadConfig(ctx, provider, readBucket)
+	require.Error(t, err)
+	config, err := ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithSections(ConfigSectionBuild, ConfigSectionDeps),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+	require.Len(t, config.Build.DependencyModuleReferences, 1)
+	assert.Equal(t, "buf.build/foo/baz", config.Build.DependencyModuleReferences[0].IdentityString())
+	assert.Equal(t, map[string][]string{".": {"foo"}}, config.Build.RootToExcludes)
+	defaultConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.Equal(t, lintConfigRuleIDs(defaultConfig.Lint), lintConfigRuleIDs(config.Lint))
+	assert.Equal(t, breakingConfigRuleIDs(defaultConfig.Breaking), breakingConfigRuleIDs(config.Breaking))
+}
+
+func TestReadConfigWithOverrideStdin(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte("version: v1\nname: buf.build/foo/bucket\n"),
+		},
+	)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	for _, data := range []string{
+		"version: v1\nname: buf.build/foo/bar\n",
+		`{"version":"v1","name":"buf.build/foo/bar"}`,
+		"version = \"v1\"\nname = \"buf.build/foo/bar\"\n",
+	} {
+		config, err := ReadConfig(
+			ctx,
+			provider,
+			readBucket,
+			ReadConfigWithOverride(StdinOverride),
+			ReadConfigWithStdin(strings.NewReader(data)),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, config.ModuleIdentity)
+		assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+	}
+	_, err = ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(StdinOverride),
+		ReadConfigWithStdin(strings.NewReader("version: v1\nlint:\n  use:\n    - NOT_A_RULE\n")),
+	)
+	assert.Error(t, err)
+}
+
+func TestReadConfigWithOverrideTOML(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	configFilePath := filepath.Join(t.TempDir(), "buf.toml")
+	require.NoError(t, os.WriteFile(configFilePath, []byte("version = \"v1\"\nname = \"buf.build/foo/bar\"\n"), 0600))
+	config, err := ReadConfig(ctx, provider, readBucket, ReadConfigWithOverride(configFilePath))
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+
+	config, err = ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride("version = \"v1\"\n[lint]\nuse = [\"NOT_A_RULE\"]\n[breaking]\nuse = [\"WIRE\"]"),
+		ReadConfigWithSections(ConfigSectionBreaking),
+	)
+	require.NoError(t, err)
+	// the lint section is not read, so the invalid lint rule is not validated
+	assert.Contains(t, breakingConfigRuleIDs(config.Breaking), "FIELD_SAME_TYPE")
+}
+
+func TestReadConfigWithOverrideURL(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := httptest.NewServer(
+		http.HandlerFunc(
+			func(responseWriter http.ResponseWriter, request *http.Request) {
+				switch request.URL.Path {
+				case "/buf.yaml":
+					_, _ = responseWriter.Write([]byte(`version: v1
+name: buf.build/foo/bar
+lint:
+  use:
+    - BASIC`))
+				case "/invalid.yaml":
+					_, _ = responseWriter.Write([]byte(`version: v1
+lint:
+  use:
+    - NOT_A_RULE`))
+				case "/slow.yaml":
+					time.Sleep(200 * time.Millisecond)
+					_, _ = responseWriter.Write([]byte(`version: v1`))
+				default:
+					http.NotFound(responseWriter, request)
+				}
+			},
+		),
+	)
+	defer server.Close()
+	readBucket, err := storagemem.NewReadBucket(nil)
+	require.NoError(t, err)
+	provider := NewProvider(zap.NewNop())
+	config, err := ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(server.URL+"/buf.yaml"),
+		ReadConfigWithHTTPClient(server.Client()),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, "buf.build/foo/bar", config.ModuleIdentity.IdentityString())
+	basicConfig, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - BASIC`))
+	require.NoError(t, err)
+	assert.Equal(t, lintConfigRuleIDs(basicConfig.Lint), lintConfigRuleIDs(config.Lint))
+
+	_, err = ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(server.URL+"/missing.yaml"),
+		ReadConfigWithHTTPClient(server.Client()),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 404 Not Found")
+
+	_, err = ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(server.URL+"/invalid.yaml"),
+		ReadConfigWithHTTPClient(server.Client()),
+	)
+	require.Error(t, err)
+
+	httpClient := server.Client()
+	httpClient.Timeout = 10 * time.Millisecond
+	_, err = ReadConfig(
+		ctx,
+		provider,
+		readBucket,
+		ReadConfigWithOverride(server.URL+"/slow.yaml"),
+		ReadConfigWithHTTPClient(httpClient),
+	)
+	require.Error(t, err)
+}
@@ -0,0 +1,209 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"go.uber.org/zap"
+)
+
+func assertRoundTrip(data []byte) error {
+	ctx := context.Background()
+	provider := newProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, data)
+	if err != nil {
+		return err
+	}
+	canonicalData, err := marshalConfigDataCanonical(data)
+	if err != nil {
+		return err
+	}
+	roundTripConfig, err := provider.GetConfigForData(ctx, canonicalData)
+	if err != nil {
+		return fmt.Errorf("could not read config after round trip: %w", err)
+	}
+	if err := compareConfigs(config, roundTripConfig); err != nil {
+		return fmt.Errorf("config changed after round trip: %w", err)
+	}
+	return nil
+}
+
+// marshalConfigDataCanonical unmarshals the JSON or YAML data into the external
+// config of its version, and marshals it back to YAML.
+func marshalConfigDataCanonical(data []byte) ([]byte, error) {
+	var externalConfigVersion ExternalConfigVersion
+	if err := encoding.UnmarshalJSONOrYAMLNonStrict(data, &externalConfigVersion); err != nil {
+		return nil, err
+	}
+	var externalConfig interface{}
+	switch externalConfigVersion.Version {
+	case "", V1Beta1Version:
+		externalConfig = &ExternalConfigV1Beta1{}
+	case V1Version:
+		externalConfig = &ExternalConfigV1{}
+	case V2Version:
+		externalConfig = &ExternalConfigV2{}
+	default:
+		return nil, fmt.Errorf("unknown config version: %q", externalConfigVersion.Version)
+	}
+	if err := encoding.UnmarshalJSONOrYAMLStrict(data, externalConfig); err != nil {
+		return nil, err
+	}
+	return encoding.MarshalYAML(externalConfig)
+}
+
+// compareConfigs returns an error describing the first difference between the Configs.
+//
+// Rules are compared by ID.
+func compareConfigs(one *Config, two *Config) error {
+	if one.Version != two.Version {
+		return fmt.Errorf("version %q != %q", one.Version, two.Version)
+	}
+	if err := compareModuleSections(
+		one.ModuleIdentity, one.Build, one.Breaking, one.Lint,
+		two.ModuleIdentity, two.Build, two.Breaking, two.Lint,
+	); err != nil {
+		return err
+	}
+	if len(one.ModuleConfigs) != len(two.ModuleConfigs) {
+		return fmt.Errorf("%d modules != %d modules", len(one.ModuleConfigs), len(two.ModuleConfigs))
+	}
+	for i, moduleConfig := range one.ModuleConfigs {
+		otherModuleConfig := two.ModuleConfigs[i]
+		if moduleConfig.Path != otherModuleConfig.Path {
+			return fmt.Errorf("module path %q != %q", moduleConfig.Path, otherModuleConfig.Path)
+		}
+		if err := compareModuleSections(
+			moduleConfig.ModuleIdentity, moduleConfig.Build, moduleConfig.Breaking, moduleConfig.Lint,
+			otherModuleConfig.ModuleIdentity, otherModuleConfig.Build, otherModuleConfig.Breaking, otherModuleConfig.Lint,
+		); err != nil {
+			return fmt.Errorf("module %q: %w", moduleConfig.Path, err)
+		}
+	}
+	// everything else can be compared directly
+	oneRest := *one
+	twoRest := *two
+	for _, rest := range []*Config{&oneRest, &twoRest} {
+		rest.ModuleIdentity = nil
+		rest.Build = nil
+		rest.Breaking = nil
+		rest.Lint = nil
+		rest.ModuleConfigs = nil
+	}
+	if !reflect.DeepEqual(oneRest, twoRest) {
+		return fmt.Errorf("%+v != %+v", oneRest, twoRest)
+	}
+	return nil
+}
+
+func compareModuleSections(
+	oneModuleIdentity bufmodule.ModuleIdentity,
+	oneBuild *bufmodulebuild.Config,
+	oneBreaking *bufbreaking.Config,
+	oneLint *buflint.Config,
+	twoModuleIdentity bufmodule.ModuleIdentity,
+	twoBuild *bufmodulebuild.Config,
+	twoBreaking *bufbreaking.Config,
+	twoLint *buflint.Config,
+) error {
+	if oneName, twoName := moduleIdentityString(oneModuleIdentity), moduleIdentityString(twoModuleIdentity); oneName != twoName {
+		return fmt.Errorf("name %q != %q", oneName, twoName)
+	}
+	if (oneBuild == nil) != (twoBuild == nil) {
+		return fmt.Errorf("build %v != %v", oneBuild, twoBuild)
+	}
+	if oneBuild != nil {
+		oneDeps := moduleReferenceStrings(oneBuild.DependencyModuleReferences)
+		twoDeps := moduleReferenceStrings(twoBuild.DependencyModuleReferences)
+		if !reflect.DeepEqual(oneDeps, twoDeps) {
+			return fmt.Errorf("deps %v != %v", oneDeps, twoDeps)
+		}
+		oneBuildRest := *oneBuild
+		twoBuildRest := *twoBuild
+		oneBuildRest.DependencyModuleReferences = nil
+		twoBuildRest.DependencyModuleReferences = nil
+		if !reflect.DeepEqual(oneBuildRest, twoBuildRest) {
+			return fmt.Errorf("build %+v != %+v", oneBuildRest, twoBuildRest)
+		}
+	}
+	if (oneBreaking == nil) != (twoBreaking == nil) {
+		return fmt.Errorf("breaking %v != %v", oneBreaking, twoBreaking)
+	}
+	if oneBreaking != nil {
+		oneRuleIDs := breakingConfigRuleIDs(oneBreaking)
+		twoRuleIDs := breakingConfigRuleIDs(twoBreaking)
+		if !reflect.DeepEqual(oneRuleIDs, twoRuleIDs) {
+			return fmt.Errorf("breaking rules %v != %v", oneRuleIDs, twoRuleIDs)
+		}
+		oneBreakingRest := *oneBreaking
+		twoBreakingRest := *twoBreaking
+		oneBreakingRest.Rules = nil
+		twoBreakingRest.Rules = nil
+		if !reflect.DeepEqual(oneBreakingRest, twoBreakingRest) {
+			return fmt.Errorf("breaking %+v != %+v", oneBreakingRest, twoBreakingRest)
+		}
+	}
+	if (oneLint == nil) != (twoLint == nil) {
+		return fmt.Errorf("lint %v != %v", oneLint, twoLint)
+	}
+	if oneLint != nil {
+		oneRuleIDs := lintConfigRuleIDs(oneLint)
+		twoRuleIDs := lintConfigRuleIDs(twoLint)
+		if !reflect.DeepEqual(oneRuleIDs, twoRuleIDs) {
+			return fmt.Errorf("lint rules %v != %v", oneRuleIDs, twoRuleIDs)
+		}
+		oneLintRest := *oneLint
+		twoLintRest := *twoLint
+		oneLintRest.Rules = nil
+		twoLintRest.Rules = nil
+		if !reflect.DeepEqual(oneLintRest, twoLintRest) {
+			return fmt.Errorf("lint %+v != %+v", oneLintRest, twoLintRest)
+		}
+	}
+	return nil
+}
+
+func moduleIdentityString(moduleIdentity bufmodule.ModuleIdentity) string {
+	if moduleIdentity == nil {
+		return ""
+	}
+	return moduleIdentity.IdentityString()
+}
+
+func moduleReferenceStrings(moduleReferences []bufmodule.ModuleReference) []string {
+	moduleReferenceStrings := make([]string, len(moduleReferences))
+	for i, moduleReference := range moduleReferences {
+		moduleReferenceStrings[i] = moduleReference.String()
+	}
+	return moduleReferenceStrings
+}
+
+func breakingConfigRuleIDs(breakingConfig *bufbreaking.Config) []string {
+	ruleIDs := make([]string, 0, len(breakingConfig.Rules))
+	for _, rule := range breakingConfig.Rules {
+		ruleIDs = append(ruleIDs, rule.ID())
+	}
+	sort.Strings(ruleIDs)
+	return ruleIDs
+}
@@ -0,0 +1,122 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAssertRoundTrip(t *testing.T) {
+	t.Parallel()
+	require.NoError(
+		t,
+		AssertRoundTrip([]byte(`version: v1
+name: buf.build/acme/weather
+deps:
+  - buf.build/acme/units
+  - buf.build/acme/geo:main
+build:
+  excludes:
+    - vendor
+  path_rules:
+    acme: "*.proto"
+breaking:
+  use:
+    - FILE
+  except:
+    - FIELD_SAME_NAME
+  ignore:
+    - acme/legacy
+  ignore_only:
+    FIELD_SAME_JSON_NAME:
+      - acme/weather/v1/weather.proto
+  ignore_unstable_packages: true
+  granularity: package
+lint:
+  use:
+    - DEFAULT
+  except:
+    - ENUM_ZERO_VALUE_SUFFIX
+  proto2_except:
+    - FIELD_LOWER_SNAKE_CASE
+  ignore:
+    - acme/legacy
+  ignore_only:
+    ENUM_PASCAL_CASE:
+      - acme/weather/v1/weather.proto
+  enum_zero_value_suffix: _NONE
+  rpc_allow_same_request_response: true
+  service_suffix: API
+  allow_comment_ignores: true
+  message_templates:
+    ENUM_PASCAL_CASE: "{path}:{line} violates {rule}"
+  allowed_field_number_ranges:
+    - 1-100
+default_visibility: public
+digest_algorithm: b2
+dep_import_aliases:
+  buf.build/acme/units:
+    units/: acme/units/
+parallelism: 4
+documentation_exempt: true
+output_format: json
+`)),
+	)
+	require.NoError(
+		t,
+		AssertRoundTrip([]byte(`version: v2
+modules:
+  - path: proto/public
+    name: buf.build/acme/public
+    lint:
+      use:
+        - BASIC
+  - path: proto/internal
+    breaking:
+      use:
+        - WIRE
+`)),
+	)
+	require.NoError(t, AssertRoundTrip(nil))
+	require.NoError(t, AssertRoundTrip([]byte(`version: v1beta1`)))
+	assert.Error(t, AssertRoundTrip([]byte(`version: v1
+unknown: true`)))
+}
+
+func TestCompareConfigs(t *testing.T) {
+	t.Parallel()
+	provider := newProvider(zap.NewNop())
+	one, err := provider.GetConfigForData(context.Background(), []byte(`version: v1
+lint:
+  use:
+    - BASIC`))
+	require.NoError(t, err)
+	two, err := provider.GetConfigForData(context.Background(), []byte(`version: v1
+lint:
+  use:
+    - BASIC
+  except:
+    - ENUM_PASCAL_CASE`))
+	require.NoError(t, err)
+	require.NoError(t, compareConfigs(one, one))
+	err = compareConfigs(one, two)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lint rules")
+}
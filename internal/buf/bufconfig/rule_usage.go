@@ -0,0 +1,44 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"fmt"
+)
+
+func aggregateRuleUsage(configs ...*Config) (map[string]int, error) {
+	ruleUsage := make(map[string]int)
+	for i, config := range configs {
+		if config == nil {
+			return nil, fmt.Errorf("config at index %d is nil", i)
+		}
+		// a rule that is enabled for both lint and breaking is only counted once per Config
+		enabledIDs := make(map[string]struct{})
+		if config.Lint != nil && !config.Lint.Disabled {
+			for _, rule := range config.Lint.Rules {
+				enabledIDs[rule.ID()] = struct{}{}
+			}
+		}
+		if config.Breaking != nil && !config.Breaking.Disabled {
+			for _, rule := range config.Breaking.Rules {
+				enabledIDs[rule.ID()] = struct{}{}
+			}
+		}
+		for id := range enabledIDs {
+			ruleUsage[id]++
+		}
+	}
+	return ruleUsage, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAggregateRuleUsage(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config1, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - ENUM_PASCAL_CASE
+    - FIELD_LOWER_SNAKE_CASE
+breaking:
+  use:
+    - FIELD_NO_DELETE`))
+	require.NoError(t, err)
+	config2, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - ENUM_PASCAL_CASE
+breaking:
+  use:
+    - FIELD_NO_DELETE
+  enabled: false`))
+	require.NoError(t, err)
+	config3, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - ENUM_PASCAL_CASE
+    - SERVICE_PASCAL_CASE
+breaking:
+  use:
+    - FIELD_NO_DELETE`))
+	require.NoError(t, err)
+	ruleUsage, err := AggregateRuleUsage(config1, config2, config3)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string]int{
+			"ENUM_PASCAL_CASE":       3,
+			"FIELD_LOWER_SNAKE_CASE": 1,
+			"SERVICE_PASCAL_CASE":    1,
+			"FIELD_NO_DELETE":        2,
+		},
+		ruleUsage,
+	)
+	_, err = AggregateRuleUsage(config1, nil)
+	require.Error(t, err)
+}
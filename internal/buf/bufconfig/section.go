@@ -0,0 +1,49 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+)
+
+// filterConfigDataSections removes the sections that are not in the given sections
+// from the JSON, YAML, or TOML configuration data, and returns the resulting YAML data.
+//
+// For v2, the sections are removed from each module.
+func filterConfigDataSections(data []byte, sections map[ConfigSection]struct{}) ([]byte, error) {
+	externalConfig := make(map[string]interface{})
+	if err := unmarshalConfigDataNonStrict(data, &externalConfig); err != nil {
+		return nil, err
+	}
+	if version, _ := externalConfig["version"].(string); version == V2Version {
+		modules, _ := externalConfig["modules"].([]interface{})
+		for _, module := range modules {
+			if externalModuleConfig, ok := module.(map[string]interface{}); ok {
+				deleteConfigSections(externalModuleConfig, sections)
+			}
+		}
+	} else {
+		deleteConfigSections(externalConfig, sections)
+	}
+	return encoding.MarshalYAML(externalConfig)
+}
+
+func deleteConfigSections(externalConfig map[string]interface{}, sections map[ConfigSection]struct{}) {
+	for configSection, key := range configSectionToString {
+		if _, ok := sections[configSection]; !ok {
+			delete(externalConfig, key)
+		}
+	}
+}
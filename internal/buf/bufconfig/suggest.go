@@ -0,0 +1,138 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/gen/data/datawkt"
+	"go.uber.org/zap"
+)
+
+// suggestedModuleIdentityString is the placeholder name used for modules without a name.
+const suggestedModuleIdentityString = "buf.build/acme/module"
+
+// importPathPrefixToDependency maps the prefixes of commonly imported paths to
+// the modules that provide them.
+var importPathPrefixToDependency = map[string]string{
+	"google/api/":                   "buf.build/googleapis/googleapis",
+	"google/cloud/":                 "buf.build/googleapis/googleapis",
+	"google/geo/":                   "buf.build/googleapis/googleapis",
+	"google/longrunning/":           "buf.build/googleapis/googleapis",
+	"google/rpc/":                   "buf.build/googleapis/googleapis",
+	"google/type/":                  "buf.build/googleapis/googleapis",
+	"protoc-gen-openapiv2/options/": "buf.build/grpc-ecosystem/grpc-gateway",
+	"validate/":                     "buf.build/envoyproxy/protoc-gen-validate",
+}
+
+func suggestConfigForModule(ctx context.Context, module bufmodule.Module) (*Config, error) {
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := suggestedModuleIdentityString
+	// all source files of a named Module have the same ModuleIdentity
+	if len(sourceFileInfos) > 0 && sourceFileInfos[0].ModuleIdentity() != nil {
+		name = sourceFileInfos[0].ModuleIdentity().IdentityString()
+	}
+	depMap := make(map[string]struct{})
+	externalImports, err := bufmodule.ModuleExternalImports(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	for _, externalImport := range externalImports {
+		if _, err := datawkt.ReadBucket.Stat(ctx, externalImport); err == nil {
+			continue
+		}
+		if dep, ok := getDependencyForImportPath(externalImport); ok {
+			depMap[dep] = struct{}{}
+		}
+	}
+	deps := make([]string, 0, len(depMap))
+	for dep := range depMap {
+		deps = append(deps, dep)
+	}
+	sort.Strings(deps)
+	return newProvider(zap.NewNop()).newConfigV1(
+		ExternalConfigV1{
+			Version: V1Version,
+			Name:    name,
+			Deps:    deps,
+		},
+	)
+}
+
+func suggestMissingDependencies(
+	ctx context.Context,
+	config *Config,
+	module bufmodule.Module,
+	lookup func(importPath string) (bufmodule.ModuleReference, bool, error),
+) ([]bufmodule.ModuleReference, error) {
+	declaredIdentityStrings := make(map[string]struct{})
+	if config != nil && config.Build != nil {
+		for _, dependencyModuleReference := range config.Build.DependencyModuleReferences {
+			declaredIdentityStrings[dependencyModuleReference.IdentityString()] = struct{}{}
+		}
+	}
+	externalImports, err := bufmodule.ModuleExternalImports(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	identityStringToModuleReference := make(map[string]bufmodule.ModuleReference)
+	for _, externalImport := range externalImports {
+		if _, err := datawkt.ReadBucket.Stat(ctx, externalImport); err == nil {
+			continue
+		}
+		moduleReference, ok, err := lookup(externalImport)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		identityString := moduleReference.IdentityString()
+		if _, ok := declaredIdentityStrings[identityString]; ok {
+			continue
+		}
+		if _, ok := identityStringToModuleReference[identityString]; !ok {
+			identityStringToModuleReference[identityString] = moduleReference
+		}
+	}
+	moduleReferences := make([]bufmodule.ModuleReference, 0, len(identityStringToModuleReference))
+	for _, moduleReference := range identityStringToModuleReference {
+		moduleReferences = append(moduleReferences, moduleReference)
+	}
+	sort.Slice(
+		moduleReferences,
+		func(i int, j int) bool {
+			return moduleReferences[i].IdentityString() < moduleReferences[j].IdentityString()
+		},
+	)
+	return moduleReferences, nil
+}
+
+// getDependencyForImportPath returns the module that provides the import path,
+// if the import path has a prefix in importPathPrefixToDependency.
+func getDependencyForImportPath(importPath string) (string, bool) {
+	for importPathPrefix, dep := range importPathPrefixToDependency {
+		if strings.HasPrefix(importPath, importPathPrefix) {
+			return dep, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,149 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSuggestConfigForModule(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "acme/weather/v1/weather.proto",
+					Content: []byte(`syntax = "proto3";
+package acme.weather.v1;
+import "acme/weather/v1/types.proto";
+import "google/api/annotations.proto";
+import "google/protobuf/empty.proto";
+import "unknown/unknown.proto";
+`),
+				},
+				{
+					Path:    "acme/weather/v1/types.proto",
+					Content: []byte(`syntax = "proto3"; package acme.weather.v1;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	config, err := SuggestConfigForModule(ctx, module)
+	require.NoError(t, err)
+	require.NotNil(t, config.ModuleIdentity)
+	assert.Equal(t, suggestedModuleIdentityString, config.ModuleIdentity.IdentityString())
+	require.Len(t, config.Build.DependencyModuleReferences, 1)
+	assert.Equal(t, "buf.build/googleapis/googleapis", config.Build.DependencyModuleReferences[0].IdentityString())
+	require.NotNil(t, config.Lint)
+	assert.NotEmpty(t, config.Lint.Rules)
+	require.NotNil(t, config.Breaking)
+	assert.NotEmpty(t, config.Breaking.Rules)
+
+	readBucketBuilder := storagemem.NewReadBucketBuilder()
+	require.NoError(
+		t,
+		WriteConfig(
+			ctx,
+			readBucketBuilder,
+			WriteConfigWithModuleIdentity(config.ModuleIdentity),
+			WriteConfigWithDependencyModuleReferences(config.Build.DependencyModuleReferences...),
+		),
+	)
+	readBucket, err := readBucketBuilder.ToReadBucket()
+	require.NoError(t, err)
+	writtenConfig, err := NewProvider(zap.NewNop()).GetConfig(ctx, readBucket)
+	require.NoError(t, err)
+	require.NotNil(t, writtenConfig.ModuleIdentity)
+	assert.Equal(t, suggestedModuleIdentityString, writtenConfig.ModuleIdentity.IdentityString())
+	require.Len(t, writtenConfig.Build.DependencyModuleReferences, 1)
+	assert.Equal(t, "buf.build/googleapis/googleapis", writtenConfig.Build.DependencyModuleReferences[0].IdentityString())
+}
+
+func TestSuggestMissingDependencies(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "acme/weather/v1/weather.proto",
+					Content: []byte(`syntax = "proto3";
+package acme.weather.v1;
+import "acme/weather/v1/types.proto";
+import "google/api/annotations.proto";
+import "google/api/http.proto";
+import "google/protobuf/empty.proto";
+import "validate/validate.proto";
+import "unknown/unknown.proto";
+`),
+				},
+				{
+					Path:    "acme/weather/v1/types.proto",
+					Content: []byte(`syntax = "proto3"; package acme.weather.v1;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	config, err := NewProvider(zap.NewNop()).GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/weather
+deps:
+  - buf.build/envoyproxy/protoc-gen-validate
+`))
+	require.NoError(t, err)
+	var lookedUpImportPaths []string
+	moduleReferences, err := SuggestMissingDependencies(
+		ctx,
+		config,
+		module,
+		func(importPath string) (bufmodule.ModuleReference, bool, error) {
+			lookedUpImportPaths = append(lookedUpImportPaths, importPath)
+			dep, ok := getDependencyForImportPath(importPath)
+			if !ok {
+				return nil, false, nil
+			}
+			moduleReference, err := bufmodule.ModuleReferenceForString(dep)
+			if err != nil {
+				return nil, false, err
+			}
+			return moduleReference, true, nil
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, moduleReferences, 1)
+	assert.Equal(t, "buf.build/googleapis/googleapis", moduleReferences[0].IdentityString())
+	assert.Equal(
+		t,
+		[]string{
+			"google/api/annotations.proto",
+			"google/api/http.proto",
+			"unknown/unknown.proto",
+			"validate/validate.proto",
+		},
+		lookedUpImportPaths,
+	)
+}
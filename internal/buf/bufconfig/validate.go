@@ -0,0 +1,319 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmodulebuild"
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"go.uber.org/multierr"
+)
+
+func validateRuleIDsExist(config *Config) error {
+	var validateLintIDs func([]string) error
+	var validateBreakingIDs func([]string) error
+	switch config.Version {
+	case V1Beta1Version:
+		validateLintIDs = buflint.ValidateIDsOrCategoriesV1Beta1
+		validateBreakingIDs = bufbreaking.ValidateIDsOrCategoriesV1Beta1
+	case V1Version, V2Version:
+		validateLintIDs = buflint.ValidateIDsOrCategoriesV1
+		validateBreakingIDs = bufbreaking.ValidateIDsOrCategoriesV1
+	default:
+		return fmt.Errorf("unknown config version: %q", config.Version)
+	}
+	var err error
+	for _, moduleConfig := range config.ModuleConfigs {
+		section := func(name string) string {
+			if config.Version == V2Version {
+				return fmt.Sprintf("module %q: %s", moduleConfig.Path, name)
+			}
+			return name
+		}
+		if moduleConfig.Lint != nil {
+			err = multierr.Append(
+				err,
+				prefixValidateError(
+					section("lint"),
+					validateLintIDs(append(append([]string{}, moduleConfig.Lint.Use...), moduleConfig.Lint.Except...)),
+				),
+			)
+		}
+		if moduleConfig.Breaking != nil {
+			err = multierr.Append(
+				err,
+				prefixValidateError(
+					section("breaking"),
+					validateBreakingIDs(append(append([]string{}, moduleConfig.Breaking.Use...), moduleConfig.Breaking.Except...)),
+				),
+			)
+		}
+	}
+	return err
+}
+
+// validateOffline performs all validation of the Config that can be done
+// without the registry.
+//
+// Dependencies are checked to be well-formed and unique by identity, but
+// are never resolved.
+func validateOffline(config *Config) error {
+	if err := validateRuleIDsExist(config); err != nil {
+		return err
+	}
+	if config.Build == nil {
+		return nil
+	}
+	for _, moduleReference := range config.Build.DependencyModuleReferences {
+		if err := bufmodule.ValidateProtoModuleReference(bufmodule.NewProtoModuleReferenceForModuleReference(moduleReference)); err != nil {
+			return prefixValidateError("deps", err)
+		}
+	}
+	return prefixValidateError(
+		"deps",
+		bufmodule.ValidateModuleReferencesUniqueByIdentity(config.Build.DependencyModuleReferences),
+	)
+}
+
+func validateDependencies(config *Config, exists func(bufmodule.ModuleReference) (bool, error)) error {
+	if config.Build == nil {
+		return nil
+	}
+	var err error
+	for _, moduleReference := range config.Build.DependencyModuleReferences {
+		if validateErr := bufmodule.ValidateProtoModuleReference(
+			bufmodule.NewProtoModuleReferenceForModuleReference(moduleReference),
+		); validateErr != nil {
+			err = multierr.Append(err, prefixValidateError("deps", fmt.Errorf("%q: %w", moduleReference.String(), validateErr)))
+			// there is no point checking the existence of an invalid reference
+			continue
+		}
+		if exists == nil {
+			continue
+		}
+		ok, existsErr := exists(moduleReference)
+		if existsErr != nil {
+			err = multierr.Append(err, prefixValidateError("deps", fmt.Errorf("could not check if %q exists: %w", moduleReference.String(), existsErr)))
+			continue
+		}
+		if !ok {
+			err = multierr.Append(err, prefixValidateError("deps", fmt.Errorf("%q does not exist", moduleReference.String())))
+		}
+	}
+	return err
+}
+
+func validateDepsNormalized(ctx context.Context, readBucket storage.ReadBucket) error {
+	data, filePath, err := readConfigFileData(ctx, readBucket, false)
+	if err != nil {
+		return err
+	}
+	var externalConfigDeps struct {
+		Deps []string `json:"deps,omitempty" yaml:"deps,omitempty" toml:"deps,omitempty"`
+	}
+	if err := encoding.UnmarshalYAMLNonStrict(data, &externalConfigDeps); err != nil {
+		return err
+	}
+	deps := externalConfigDeps.Deps
+	expectedDeps := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		expectedDeps = append(expectedDeps, strings.TrimSpace(dep))
+	}
+	expectedDeps = stringutil.SliceToUniqueSortedSlice(expectedDeps)
+	if stringutil.SliceElementsEqual(deps, expectedDeps) {
+		return nil
+	}
+	return fmt.Errorf(
+		"%s: deps are not sorted and de-duplicated, expected:\n  %s",
+		filePath,
+		strings.Join(expectedDeps, "\n  "),
+	)
+}
+
+const (
+	maxRemoteLength      = 253
+	maxRemoteLabelLength = 63
+	maxOwnerLength       = 32
+	maxRepositoryLength  = 100
+)
+
+func validateModuleIdentityStrict(config *Config) error {
+	if config.ModuleIdentity == nil {
+		return nil
+	}
+	return prefixValidateError(
+		"name",
+		multierr.Combine(
+			validateRemoteStrict(config.ModuleIdentity.Remote()),
+			validateNameComponentStrict("owner", config.ModuleIdentity.Owner(), maxOwnerLength),
+			validateNameComponentStrict("repository", config.ModuleIdentity.Repository(), maxRepositoryLength),
+		),
+	)
+}
+
+// validateRemoteStrict validates that the remote is a lowercase DNS hostname.
+func validateRemoteStrict(remote string) error {
+	if len(remote) > maxRemoteLength {
+		return fmt.Errorf("remote %q must be at most %d characters", remote, maxRemoteLength)
+	}
+	labels := strings.Split(remote, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("remote %q must be a fully-qualified hostname such as buf.build", remote)
+	}
+	for _, label := range labels {
+		if label == "" {
+			return fmt.Errorf("remote %q must not contain empty labels", remote)
+		}
+		if len(label) > maxRemoteLabelLength {
+			return fmt.Errorf("remote %q has label %q which must be at most %d characters", remote, label, maxRemoteLabelLength)
+		}
+		if err := validateDNSLabelCharacters(label); err != nil {
+			return fmt.Errorf("remote %q has label %q which %v", remote, label, err)
+		}
+	}
+	return nil
+}
+
+// validateNameComponentStrict validates that the owner or repository is a DNS-safe name.
+func validateNameComponentStrict(componentName string, value string, maxLength int) error {
+	if len(value) > maxLength {
+		return fmt.Errorf("%s %q must be at most %d characters, but is %d characters", componentName, value, maxLength, len(value))
+	}
+	if err := validateDNSLabelCharacters(value); err != nil {
+		return fmt.Errorf("%s %q %v", componentName, value, err)
+	}
+	return nil
+}
+
+// validateDNSLabelCharacters validates that the value only contains lowercase letters,
+// digits, and hyphens, and does not start or end with a hyphen.
+func validateDNSLabelCharacters(value string) error {
+	for _, c := range value {
+		switch {
+		case 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-':
+		case 'A' <= c && c <= 'Z':
+			return fmt.Errorf("must not contain uppercase character %q", c)
+		default:
+			return fmt.Errorf("must only contain lowercase letters, digits, and hyphens, but contains %q", c)
+		}
+	}
+	if strings.HasPrefix(value, "-") || strings.HasSuffix(value, "-") {
+		return errors.New("must not start or end with a hyphen")
+	}
+	return nil
+}
+
+func prefixValidateError(section string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", section, err)
+}
+
+func validateConfig(config *Config) error {
+	var problems []*ValidationProblem
+	if config.Version == V2Version {
+		for _, moduleConfig := range config.ModuleConfigs {
+			problems = append(
+				problems,
+				getModuleValidationProblems(
+					fmt.Sprintf("modules[%q].", moduleConfig.Path),
+					moduleConfig.ModuleIdentity,
+					moduleConfig.Build,
+				)...,
+			)
+		}
+	} else {
+		problems = getModuleValidationProblems("", config.ModuleIdentity, config.Build)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{
+			Problems: problems,
+		}
+	}
+	return nil
+}
+
+// getModuleValidationProblems returns the problems with the name, deps, and build
+// of a single module, with the field paths prefixed with fieldPathPrefix.
+func getModuleValidationProblems(
+	fieldPathPrefix string,
+	moduleIdentity bufmodule.ModuleIdentity,
+	buildConfig *bufmodulebuild.Config,
+) []*ValidationProblem {
+	if buildConfig == nil {
+		return nil
+	}
+	var problems []*ValidationProblem
+	if moduleIdentity == nil && len(buildConfig.DependencyModuleReferences) > 0 {
+		problems = append(
+			problems,
+			&ValidationProblem{
+				FieldPath: fieldPathPrefix + "name",
+				Message:   "must be set if deps are set",
+			},
+		)
+	}
+	identityStringToIndex := make(map[string]int)
+	for i, moduleReference := range buildConfig.DependencyModuleReferences {
+		identityString := moduleReference.IdentityString()
+		if index, ok := identityStringToIndex[identityString]; ok {
+			problems = append(
+				problems,
+				&ValidationProblem{
+					FieldPath: fmt.Sprintf("%sdeps[%d]", fieldPathPrefix, i),
+					Message:   fmt.Sprintf("module %q is already a dependency at deps[%d]", identityString, index),
+				},
+			)
+			continue
+		}
+		identityStringToIndex[identityString] = i
+	}
+	roots := make([]string, 0, len(buildConfig.RootToExcludes))
+	for root := range buildConfig.RootToExcludes {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	var excludedRoots []string
+	for _, root := range roots {
+		for _, exclude := range buildConfig.RootToExcludes[root] {
+			// excludes are relative to the root they map to
+			if exclude == "." {
+				excludedRoots = append(excludedRoots, root)
+				break
+			}
+		}
+	}
+	if len(roots) > 0 && len(excludedRoots) == len(roots) {
+		problems = append(
+			problems,
+			&ValidationProblem{
+				FieldPath: fieldPathPrefix + "build.excludes",
+				Message:   fmt.Sprintf("excludes every root %v", excludedRoots),
+			},
+		)
+	}
+	return problems
+}
@@ -0,0 +1,265 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestValidateRuleIDsExist(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - DEFAULT
+breaking:
+  use:
+    - FILE`))
+	require.NoError(t, err)
+	assert.NoError(t, ValidateRuleIDsExist(config))
+	assert.Equal(t, []string{"DEFAULT"}, config.Lint.Use)
+	assert.Equal(t, []string{"FILE"}, config.Breaking.Use)
+}
+
+func TestValidateRuleIDsExistUnknownIDsReportedTogether(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v2
+modules:
+  - path: a
+    lint:
+      use:
+        - DEFAULT
+  - path: b
+    breaking:
+      except:
+        - FILE`),
+			"a/a.proto": []byte(`syntax = "proto3";`),
+			"b/b.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	config, err := ReadConfig(ctx, NewProvider(zap.NewNop()), readBucket)
+	require.NoError(t, err)
+	require.NoError(t, ValidateRuleIDsExist(config))
+	require.Len(t, config.ModuleConfigs, 2)
+	// simulate ids that were removed from this version of buf
+	config.ModuleConfigs[0].Lint.Use = append(config.ModuleConfigs[0].Lint.Use, "ENUM_PASCAL_CAS")
+	config.ModuleConfigs[1].Breaking.Except = append(config.ModuleConfigs[1].Breaking.Except, "FIELD_SAME_TYP")
+	err = ValidateRuleIDsExist(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `module "a": lint: "ENUM_PASCAL_CAS" is not a known id or category, did you mean "ENUM_PASCAL_CASE"?`)
+	assert.Contains(t, err.Error(), `module "b": breaking: "FIELD_SAME_TYP" is not a known id or category, did you mean "FIELD_SAME_TYPE"?`)
+}
+
+func TestUnknownRuleIDsReportedTogether(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	_, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - ENUM_PASCAL_CAS
+  except:
+    - FIELD_LOWER_SNAKE_CAS`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"ENUM_PASCAL_CAS" is not a known id or category, did you mean "ENUM_PASCAL_CASE"?`)
+	assert.Contains(t, err.Error(), `"FIELD_LOWER_SNAKE_CAS" is not a known id or category, did you mean "FIELD_LOWER_SNAKE_CASE"?`)
+}
+
+func TestLintCategoriesAndIDsMixed(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - BASIC
+    - COMMENT_ENUM
+  except:
+    - ENUM_PASCAL_CASE`))
+	require.NoError(t, err)
+	ruleIDs := lintConfigRuleIDs(config.Lint)
+	assert.Contains(t, ruleIDs, "COMMENT_ENUM")
+	assert.Contains(t, ruleIDs, "FIELD_LOWER_SNAKE_CASE")
+	assert.NotContains(t, ruleIDs, "ENUM_PASCAL_CASE")
+	assert.NotContains(t, ruleIDs, "COMMENT_MESSAGE")
+
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+lint:
+  use:
+    - BASC
+    - COMMENT_ENUM`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"BASC" is not a known id or category, did you mean "BASIC"?`)
+}
+
+func TestValidateDepsNormalized(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+deps:
+  - buf.build/foo/b
+  - buf.build/foo/a
+  - buf.build/foo/b`),
+		},
+	)
+	require.NoError(t, err)
+	err = ValidateDepsNormalized(ctx, readBucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected:\n  buf.build/foo/a\n  buf.build/foo/b")
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			ExternalConfigFilePath: []byte(`version: v1
+deps:
+  - buf.build/foo/a
+  - buf.build/foo/b`),
+		},
+	)
+	require.NoError(t, err)
+	assert.NoError(t, ValidateDepsNormalized(ctx, readBucket))
+}
+
+func TestValidateModuleIdentityStrict(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/weather-v1`))
+	require.NoError(t, err)
+	assert.NoError(t, ValidateModuleIdentityStrict(config))
+
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1`))
+	require.NoError(t, err)
+	assert.NoError(t, ValidateModuleIdentityStrict(config))
+
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme_corp/weather`))
+	require.NoError(t, err)
+	err = ValidateModuleIdentityStrict(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `owner "acme_corp" must only contain lowercase letters, digits, and hyphens, but contains '_'`)
+
+	config, err = provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/`+strings.Repeat("a", 101)))
+	require.NoError(t, err)
+	err = ValidateModuleIdentityStrict(config)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be at most 100 characters, but is 101 characters")
+}
+
+func TestValidateDependencies(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	config, err := NewProvider(zap.NewNop()).GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/module
+deps:
+  - buf.build/foo/a
+  - buf.build/foo/b:v1
+  - buf.build/foo/c`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateDependencies(config, nil))
+	var checked []string
+	err = ValidateDependencies(
+		config,
+		func(moduleReference bufmodule.ModuleReference) (bool, error) {
+			checked = append(checked, moduleReference.String())
+			switch moduleReference.IdentityString() {
+			case "buf.build/foo/b":
+				return false, nil
+			case "buf.build/foo/c":
+				return false, errors.New("unavailable")
+			default:
+				return true, nil
+			}
+		},
+	)
+	require.Error(t, err)
+	assert.Equal(t, []string{"buf.build/foo/a:main", "buf.build/foo/b:v1", "buf.build/foo/c:main"}, checked)
+	assert.Contains(t, err.Error(), `deps: "buf.build/foo/b:v1" does not exist`)
+	assert.Contains(t, err.Error(), `deps: could not check if "buf.build/foo/c:main" exists: unavailable`)
+	assert.NotContains(t, err.Error(), "buf.build/foo/a")
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/acme/weather
+deps:
+  - buf.build/acme/units
+  - buf.build/acme/geo`))
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v1
+deps:
+  - buf.build/acme/units`))
+	require.Error(t, err)
+	validationError := &ValidationError{}
+	require.True(t, errors.As(err, &validationError))
+	require.Len(t, validationError.Problems, 1)
+	assert.Equal(t, "name", validationError.Problems[0].FieldPath)
+
+	unitsModuleReference, err := bufmodule.ModuleReferenceForString("buf.build/acme/units")
+	require.NoError(t, err)
+	unitsMainModuleReference, err := bufmodule.ModuleReferenceForString("buf.build/acme/units:main")
+	require.NoError(t, err)
+	config.ModuleIdentity = nil
+	config.Build.DependencyModuleReferences = append(
+		config.Build.DependencyModuleReferences,
+		unitsModuleReference,
+		unitsMainModuleReference,
+	)
+	config.Build.RootToExcludes = map[string][]string{
+		".": {"."},
+	}
+	err = config.Validate()
+	require.Error(t, err)
+	require.True(t, errors.As(err, &validationError))
+	fieldPaths := make([]string, 0, len(validationError.Problems))
+	for _, problem := range validationError.Problems {
+		fieldPaths = append(fieldPaths, problem.FieldPath)
+	}
+	assert.Equal(t, []string{"name", "deps[2]", "deps[3]", "build.excludes"}, fieldPaths)
+	assert.Equal(t, `deps[2]: module "buf.build/acme/units" is already a dependency at deps[0]`, validationError.Problems[1].String())
+
+	_, err = provider.GetConfigForData(ctx, []byte(`version: v2
+modules:
+  - path: proto
+    deps:
+      - buf.build/acme/units`))
+	require.Error(t, err)
+	require.True(t, errors.As(err, &validationError))
+	require.Len(t, validationError.Problems, 1)
+	assert.Equal(t, `modules["proto"].name`, validationError.Problems[0].FieldPath)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+)
+
+func configWithoutLint(config *Config) *Config {
+	lintConfig := newEmptyLintConfig(config.Parallelism)
+	return configWithModuleConfigs(config, func(moduleConfig *ModuleConfig) {
+		moduleConfig.Lint = lintConfig
+	})
+}
+
+func configWithoutBreaking(config *Config) *Config {
+	breakingConfig := newEmptyBreakingConfig(config.Parallelism)
+	return configWithModuleConfigs(config, func(moduleConfig *ModuleConfig) {
+		moduleConfig.Breaking = breakingConfig
+	})
+}
+
+// configWithModuleConfigs returns a shallow copy of the Config where f has been
+// called on a copy of every ModuleConfig.
+//
+// The ModuleIdentity, Build, Breaking, and Lint of the returned Config are
+// then set from the first ModuleConfig, as they are for every Config.
+func configWithModuleConfigs(config *Config, f func(*ModuleConfig)) *Config {
+	configCopy := *config
+	// Configs created outside of this package may not have any ModuleConfigs
+	if len(config.ModuleConfigs) == 0 {
+		moduleConfig := &ModuleConfig{
+			Path:           ".",
+			ModuleIdentity: config.ModuleIdentity,
+			Build:          config.Build,
+			Breaking:       config.Breaking,
+			Lint:           config.Lint,
+		}
+		f(moduleConfig)
+		configCopy.Breaking = moduleConfig.Breaking
+		configCopy.Lint = moduleConfig.Lint
+		return &configCopy
+	}
+	configCopy.ModuleConfigs = make([]*ModuleConfig, len(config.ModuleConfigs))
+	for i, moduleConfig := range config.ModuleConfigs {
+		moduleConfigCopy := *moduleConfig
+		f(&moduleConfigCopy)
+		configCopy.ModuleConfigs[i] = &moduleConfigCopy
+	}
+	firstModuleConfig := configCopy.ModuleConfigs[0]
+	configCopy.ModuleIdentity = firstModuleConfig.ModuleIdentity
+	configCopy.Build = firstModuleConfig.Build
+	configCopy.Breaking = firstModuleConfig.Breaking
+	configCopy.Lint = firstModuleConfig.Lint
+	return &configCopy
+}
+
+func newEmptyLintConfig(parallelism int) *buflint.Config {
+	return &buflint.Config{
+		Disabled:    true,
+		Parallelism: parallelism,
+	}
+}
+
+func newEmptyBreakingConfig(parallelism int) *bufbreaking.Config {
+	return &bufbreaking.Config{
+		Disabled:    true,
+		Granularity: bufbreaking.GranularityFile,
+		Parallelism: parallelism,
+	}
+}
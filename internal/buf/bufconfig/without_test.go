@@ -0,0 +1,70 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConfigWithoutLintAndBreaking(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	provider := NewProvider(zap.NewNop())
+	config, err := provider.GetConfigForData(ctx, []byte(`version: v1
+name: buf.build/foo/bar
+lint:
+  use:
+    - DEFAULT
+breaking:
+  use:
+    - FILE`))
+	require.NoError(t, err)
+	originalLint := config.Lint
+	originalBreaking := config.Breaking
+
+	withoutLint := config.WithoutLint()
+	require.NotNil(t, withoutLint.Lint)
+	assert.Empty(t, withoutLint.Lint.Rules)
+	assert.True(t, withoutLint.Lint.Disabled)
+	assert.Equal(t, originalBreaking, withoutLint.Breaking)
+	assert.Equal(t, config.Build, withoutLint.Build)
+	assert.Equal(t, config.ModuleIdentity, withoutLint.ModuleIdentity)
+	require.Len(t, withoutLint.ModuleConfigs, 1)
+	assert.Equal(t, withoutLint.Lint, withoutLint.ModuleConfigs[0].Lint)
+
+	withoutBreaking := config.WithoutBreaking()
+	require.NotNil(t, withoutBreaking.Breaking)
+	assert.Empty(t, withoutBreaking.Breaking.Rules)
+	assert.True(t, withoutBreaking.Breaking.Disabled)
+	assert.Equal(t, originalLint, withoutBreaking.Lint)
+	require.Len(t, withoutBreaking.ModuleConfigs, 1)
+	assert.Equal(t, withoutBreaking.Breaking, withoutBreaking.ModuleConfigs[0].Breaking)
+
+	buildOnly := config.WithoutLint().WithoutBreaking()
+	assert.True(t, buildOnly.Lint.Disabled)
+	assert.True(t, buildOnly.Breaking.Disabled)
+
+	// the original Config is untouched
+	assert.True(t, originalLint == config.Lint)
+	assert.True(t, originalBreaking == config.Breaking)
+	assert.True(t, originalLint == config.ModuleConfigs[0].Lint)
+	assert.NotEmpty(t, config.Lint.Rules)
+	assert.NotEmpty(t, config.Breaking.Rules)
+}
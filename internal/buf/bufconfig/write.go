@@ -18,17 +18,20 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"sort"
 	"text/template"
 
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
 	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
 )
 
 // If this is updated, make sure to update docs.buf.build TODO automate this
 
 const (
 	exampleName          = "buf.build/acme/weather"
-	tmplUndocumentedData = `{{$top := .}}version: v1
+	tmplUndocumentedData = `{{$top := .}}version: {{.Version}}
 {{if not .NameUnset}}name: {{.Name}}
 {{end}}{{if not .DepsUnset}}deps:
 {{range $dep := .Deps}}  - {{$dep}}
@@ -47,7 +50,7 @@ const (
 #
 # The only valid versions are "v1beta1", "v1".
 # This key is required.
-version: v1
+version: {{.Version}}
 
 # name is the module name.
 {{if .NameUnset}}#{{end}}name: {{.Name}}
@@ -64,7 +67,29 @@ version: v1
 # If you want to build all files in your repository, this section can be
 # omitted.
 build:
+{{if .V1Beta1}}
+  # roots is the list of directories to use as root directories.
+  #
+  # All directory paths in roots must be relative to the directory of
+  # your buf.yaml. Roots cannot overlap.
+  #
+  # The default is ["."].
+  {{if not .Uncomment}}#{{end}}roots:
+  {{if not .Uncomment}}#{{end}}  - proto
+  {{if not .Uncomment}}#{{end}}  - vendor
 
+  # excludes is the list of directories to exclude.
+  #
+  # These directories will not be built or checked. If a directory is excluded,
+  # buf treats the directory as if it does not exist.
+  #
+  # All directory paths in excludes must be relative to a root directory.
+  # Only directories can be specified, and all specified directories must
+  # within a root directory.
+  {{if not .Uncomment}}#{{end}}excludes:
+  {{if not .Uncomment}}#{{end}}  - proto/foo
+  {{if not .Uncomment}}#{{end}}  - vendor/bar/baz
+{{else}}
   # excludes is the list of directories to exclude.
   #
   # These directories will not be built or checked. If a directory is excluded,
@@ -76,7 +101,7 @@ build:
   {{if not .Uncomment}}#{{end}}excludes:
   {{if not .Uncomment}}#{{end}}  - foo
   {{if not .Uncomment}}#{{end}}  - bar/baz
-
+{{end}}
 # lint contains the options for lint rules.
 lint:
 
@@ -125,6 +150,13 @@ lint:
   {{if not .Uncomment}}#{{end}}  FIELD_LOWER_SNAKE_CASE:
   {{if not .Uncomment}}#{{end}}    - foo
 
+  # enum_value_prefix affects the behavior of the ENUM_VALUE_PREFIX rule.
+  #
+  # This will result in this prefix being used instead of the default
+  # "{ENUM_NAME}_" prefix, where "{ENUM_NAME}" is replaced with the
+  # UPPER_SNAKE_CASE name of the enum.
+  {{if not .Uncomment}}#{{end}}enum_value_prefix: "{ENUM_NAME}_"
+
   # enum_zero_value_suffix affects the behavior of the ENUM_ZERO_VALUE_SUFFIX
   # rule.
   #
@@ -279,19 +311,48 @@ func writeConfig(
 	if !writeConfigOptions.documentationComments && writeConfigOptions.uncomment {
 		return errors.New("cannot set uncomment without documentationComments for WriteConfig")
 	}
-	externalConfigV1 := ExternalConfigV1{
-		Version: V1Version,
+	version := writeConfigOptions.version
+	if version == "" {
+		version = V1Version
 	}
-	externalConfigV1.Lint.Use = defaultLintIDs
-	externalConfigV1.Breaking.Use = defaultBreakingIDs
+	var name string
 	if writeConfigOptions.moduleIdentity != nil {
-		externalConfigV1.Name = writeConfigOptions.moduleIdentity.IdentityString()
+		name = writeConfigOptions.moduleIdentity.IdentityString()
 	}
+	var deps []string
 	for _, dependencyModuleReference := range writeConfigOptions.dependencyModuleReferences {
-		externalConfigV1.Deps = append(
-			externalConfigV1.Deps,
-			dependencyModuleReference.String(),
-		)
+		deps = append(deps, dependencyModuleReference.String())
+	}
+	if writeConfigOptions.stableFormat {
+		sort.Strings(deps)
+	}
+	var tmplParam *tmplParam
+	var filePath string
+	switch version {
+	case V1Beta1Version:
+		externalConfigV1Beta1 := ExternalConfigV1Beta1{
+			Version: V1Beta1Version,
+			Name:    name,
+			Deps:    deps,
+		}
+		externalConfigV1Beta1.Lint.Use = defaultLintIDs
+		externalConfigV1Beta1.Breaking.Use = defaultBreakingIDs
+		tmplParam = newTmplParamV1Beta1(externalConfigV1Beta1, writeConfigOptions.uncomment, writeConfigOptions.stableFormat)
+		filePath = ExternalConfigV1Beta1FilePath
+	case V1Version:
+		externalConfigV1 := ExternalConfigV1{
+			Version: V1Version,
+			Name:    name,
+			Deps:    deps,
+		}
+		externalConfigV1.Lint.Use = defaultLintIDs
+		externalConfigV1.Breaking.Use = defaultBreakingIDs
+		tmplParam = newTmplParamV1(externalConfigV1, writeConfigOptions.uncomment, writeConfigOptions.stableFormat)
+		filePath = ExternalConfigFilePath
+	case V2Version:
+		return fmt.Errorf("WriteConfig does not support writing configuration files at version %q", version)
+	default:
+		return fmt.Errorf("unknown configuration file version for WriteConfig: %q", version)
 	}
 	tmplData := tmplUndocumentedData
 	if writeConfigOptions.documentationComments {
@@ -302,19 +363,18 @@ func writeConfig(
 		return err
 	}
 	buffer := bytes.NewBuffer(nil)
-	if err := tmpl.Execute(
-		buffer,
-		newTmplParam(
-			externalConfigV1,
-			writeConfigOptions.uncomment,
-		),
-	); err != nil {
+	if err := tmpl.Execute(buffer, tmplParam); err != nil {
 		return err
 	}
-	return storage.PutPath(ctx, writeBucket, ExternalConfigFilePath, buffer.Bytes())
+	if writeConfigOptions.stableFormat && !bytes.HasSuffix(buffer.Bytes(), []byte("\n")) {
+		buffer.WriteString("\n")
+	}
+	return storage.PutPath(ctx, writeBucket, filePath, buffer.Bytes())
 }
 
 type tmplParam struct {
+	Version     string
+	V1Beta1     bool
 	Name        string
 	NameUnset   bool
 	Deps        []string
@@ -324,14 +384,52 @@ type tmplParam struct {
 	Uncomment   bool
 }
 
-func newTmplParam(externalConfigV1 ExternalConfigV1, uncomment bool) *tmplParam {
+func newTmplParamV1Beta1(externalConfigV1Beta1 ExternalConfigV1Beta1, uncomment bool, stableFormat bool) *tmplParam {
+	return newTmplParam(
+		externalConfigV1Beta1.Version,
+		externalConfigV1Beta1.Name,
+		externalConfigV1Beta1.Deps,
+		externalConfigV1Beta1.Lint.Use,
+		externalConfigV1Beta1.Breaking.Use,
+		uncomment,
+		stableFormat,
+	)
+}
+
+func newTmplParamV1(externalConfigV1 ExternalConfigV1, uncomment bool, stableFormat bool) *tmplParam {
+	return newTmplParam(
+		externalConfigV1.Version,
+		externalConfigV1.Name,
+		externalConfigV1.Deps,
+		externalConfigV1.Lint.Use,
+		externalConfigV1.Breaking.Use,
+		uncomment,
+		stableFormat,
+	)
+}
+
+func newTmplParam(
+	version string,
+	name string,
+	deps []string,
+	lintIDs []string,
+	breakingIDs []string,
+	uncomment bool,
+	stableFormat bool,
+) *tmplParam {
 	tmplParam := &tmplParam{
-		Name:        externalConfigV1.Name,
-		Deps:        externalConfigV1.Deps,
-		LintIDs:     externalConfigV1.Lint.Use,
-		BreakingIDs: externalConfigV1.Breaking.Use,
+		Version:     version,
+		V1Beta1:     version == V1Beta1Version,
+		Name:        name,
+		Deps:        deps,
+		LintIDs:     lintIDs,
+		BreakingIDs: breakingIDs,
 		Uncomment:   uncomment,
 	}
+	if stableFormat {
+		tmplParam.LintIDs = stringutil.SliceToUniqueSortedSlice(lintIDs)
+		tmplParam.BreakingIDs = stringutil.SliceToUniqueSortedSlice(breakingIDs)
+	}
 	if tmplParam.Name == "" {
 		tmplParam.Name = exampleName
 		tmplParam.NameUnset = true
@@ -348,6 +446,8 @@ type writeConfigOptions struct {
 	dependencyModuleReferences []bufmodule.ModuleReference
 	documentationComments      bool
 	uncomment                  bool
+	version                    string
+	stableFormat               bool
 }
 
 func newWriteConfigOptions() *writeConfigOptions {
@@ -0,0 +1,126 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufconfig
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestWriteConfigWithVersion(t *testing.T) {
+	t.Parallel()
+	testWriteConfigWithVersion(t, V1Beta1Version, ExternalConfigV1Beta1FilePath)
+	testWriteConfigWithVersion(t, V1Version, ExternalConfigFilePath)
+	testWriteConfigWithVersion(t, "", ExternalConfigFilePath)
+}
+
+func TestWriteConfigWithVersionUnsupported(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	for _, version := range []string{V2Version, "v3", "foo"} {
+		readBucketBuilder := storagemem.NewReadBucketBuilder()
+		require.Error(t, WriteConfig(ctx, readBucketBuilder, WriteConfigWithVersion(version)))
+		readBucket, err := readBucketBuilder.ToReadBucket()
+		require.NoError(t, err)
+		isEmpty, err := storage.IsEmpty(ctx, readBucket, "")
+		require.NoError(t, err)
+		assert.True(t, isEmpty, version)
+	}
+}
+
+func TestWriteConfigWithStableFormat(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	moduleIdentity, err := bufmodule.ModuleIdentityForString("buf.build/acme/weather")
+	require.NoError(t, err)
+	var dependencyModuleReferences []bufmodule.ModuleReference
+	for _, dep := range []string{"buf.build/acme/units", "buf.build/acme/date", "buf.build/acme/geo:v1"} {
+		dependencyModuleReference, err := bufmodule.ModuleReferenceForString(dep)
+		require.NoError(t, err)
+		dependencyModuleReferences = append(dependencyModuleReferences, dependencyModuleReference)
+	}
+	for _, options := range [][]WriteConfigOption{
+		nil,
+		{WriteConfigWithDocumentationComments()},
+		{WriteConfigWithVersion(V1Beta1Version)},
+	} {
+		options = append(
+			options,
+			WriteConfigWithModuleIdentity(moduleIdentity),
+			WriteConfigWithDependencyModuleReferences(dependencyModuleReferences...),
+			WriteConfigWithStableFormat(),
+		)
+		var previousData []byte
+		for i := 0; i < 2; i++ {
+			readBucketBuilder := storagemem.NewReadBucketBuilder()
+			require.NoError(t, WriteConfig(ctx, readBucketBuilder, options...))
+			readBucket, err := readBucketBuilder.ToReadBucket()
+			require.NoError(t, err)
+			data, _, err := readConfigFileData(ctx, readBucket, false)
+			require.NoError(t, err)
+			assert.True(t, bytes.HasSuffix(data, []byte("\n")))
+			if previousData != nil {
+				assert.Equal(t, string(previousData), string(data))
+			}
+			previousData = data
+			config, err := NewProvider(zap.NewNop()).GetConfig(ctx, readBucket)
+			require.NoError(t, err)
+			assert.Equal(
+				t,
+				[]string{"buf.build/acme/date:main", "buf.build/acme/geo:v1", "buf.build/acme/units:main"},
+				moduleReferenceStrings(config.Build.DependencyModuleReferences),
+			)
+		}
+	}
+}
+
+func testWriteConfigWithVersion(t *testing.T, version string, expectedFilePath string) {
+	ctx := context.Background()
+	expectedVersion := version
+	if expectedVersion == "" {
+		expectedVersion = V1Version
+	}
+	for _, options := range [][]WriteConfigOption{
+		nil,
+		{WriteConfigWithDocumentationComments()},
+		{WriteConfigWithDocumentationComments(), WriteConfigWithUncomment()},
+	} {
+		if version != "" {
+			options = append(options, WriteConfigWithVersion(version))
+		}
+		readBucketBuilder := storagemem.NewReadBucketBuilder()
+		require.NoError(t, WriteConfig(ctx, readBucketBuilder, options...))
+		// the uncommented ignore_only paths must exist within a root for the config to be read
+		for _, path := range []string{"foo/foo.proto", "bar/bar.proto", "proto/foo/foo.proto", "proto/bar/bar.proto"} {
+			require.NoError(t, storage.PutPath(ctx, readBucketBuilder, path, nil))
+		}
+		readBucket, err := readBucketBuilder.ToReadBucket()
+		require.NoError(t, err)
+		exists, err := storage.Exists(ctx, readBucket, expectedFilePath)
+		require.NoError(t, err)
+		require.True(t, exists)
+		config, err := NewProvider(zap.NewNop()).GetConfig(ctx, readBucket)
+		require.NoError(t, err)
+		assert.Equal(t, expectedVersion, config.Version)
+	}
+}
@@ -51,13 +51,39 @@ type Dependency struct {
 
 // ReadConfig reads the lock file at ExternalConfigFilePath relative
 // to the root of the bucket.
-func ReadConfig(ctx context.Context, readBucket storage.ReadBucket) (*Config, error) {
-	return readConfig(ctx, readBucket)
+func ReadConfig(ctx context.Context, readBucket storage.ReadBucket, options ...ReadConfigOption) (*Config, error) {
+	return readConfig(ctx, readBucket, options...)
+}
+
+// ReadConfigOption is an option for ReadConfig.
+type ReadConfigOption func(*readConfigOptions)
+
+// ReadConfigWithFilePath returns a new ReadConfigOption that reads the lock file
+// at the given path relative to the root of the bucket.
+//
+// The default is to read ExternalConfigFilePath.
+func ReadConfigWithFilePath(filePath string) ReadConfigOption {
+	return func(readConfigOptions *readConfigOptions) {
+		readConfigOptions.filePath = filePath
+	}
 }
 
 // WriteConfig writes the lock file to the WriteBucket at ExternalConfigFilePath.
-func WriteConfig(ctx context.Context, writeBucket storage.WriteBucket, config *Config) error {
-	return writeConfig(ctx, writeBucket, config)
+func WriteConfig(ctx context.Context, writeBucket storage.WriteBucket, config *Config, options ...WriteConfigOption) error {
+	return writeConfig(ctx, writeBucket, config, options...)
+}
+
+// WriteConfigOption is an option for WriteConfig.
+type WriteConfigOption func(*writeConfigOptions)
+
+// WriteConfigWithFilePath returns a new WriteConfigOption that writes the lock file
+// to the given path relative to the root of the bucket.
+//
+// The default is to write to ExternalConfigFilePath.
+func WriteConfigWithFilePath(filePath string) WriteConfigOption {
+	return func(writeConfigOptions *writeConfigOptions) {
+		writeConfigOptions.filePath = filePath
+	}
 }
 
 // ExternalConfigV1 represents the v1 lock file.
@@ -19,11 +19,20 @@ import (
 	"fmt"
 
 	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/storage"
 )
 
-func readConfig(ctx context.Context, readBucket storage.ReadBucket) (_ *Config, retErr error) {
-	configBytes, err := storage.ReadPath(ctx, readBucket, ExternalConfigFilePath)
+func readConfig(ctx context.Context, readBucket storage.ReadBucket, options ...ReadConfigOption) (_ *Config, retErr error) {
+	readConfigOptions := newReadConfigOptions()
+	for _, option := range options {
+		option(readConfigOptions)
+	}
+	filePath, err := normalpath.NormalizeAndValidate(readConfigOptions.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock file path: %w", err)
+	}
+	configBytes, err := storage.ReadPath(ctx, readBucket, filePath)
 	if err != nil {
 		if storage.IsNotExist(err) {
 			// If the lock file doesn't exist, just return no dependencies.
@@ -61,7 +70,15 @@ func readConfig(ctx context.Context, readBucket storage.ReadBucket) (_ *Config,
 	}
 }
 
-func writeConfig(ctx context.Context, writeBucket storage.WriteBucket, config *Config) error {
+func writeConfig(ctx context.Context, writeBucket storage.WriteBucket, config *Config, options ...WriteConfigOption) error {
+	writeConfigOptions := newWriteConfigOptions()
+	for _, option := range options {
+		option(writeConfigOptions)
+	}
+	filePath, err := normalpath.NormalizeAndValidate(writeConfigOptions.filePath)
+	if err != nil {
+		return fmt.Errorf("invalid lock file path: %w", err)
+	}
 	externalConfig := ExternalConfigV1{
 		Version: V1Version,
 		Deps:    make([]ExternalConfigDependencyV1, 0, len(config.Dependencies)),
@@ -76,10 +93,30 @@ func writeConfig(ctx context.Context, writeBucket storage.WriteBucket, config *C
 	if err := storage.PutPath(
 		ctx,
 		writeBucket,
-		ExternalConfigFilePath,
+		filePath,
 		append([]byte(Header), configBytes...),
 	); err != nil {
 		return fmt.Errorf("failed to write lock file: %w", err)
 	}
 	return nil
 }
+
+type readConfigOptions struct {
+	filePath string
+}
+
+func newReadConfigOptions() *readConfigOptions {
+	return &readConfigOptions{
+		filePath: ExternalConfigFilePath,
+	}
+}
+
+type writeConfigOptions struct {
+	filePath string
+}
+
+func newWriteConfigOptions() *writeConfigOptions {
+	return &writeConfigOptions{
+		filePath: ExternalConfigFilePath,
+	}
+}
@@ -164,8 +164,16 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 			Build: bufmodulebuild.ExternalConfigV1{
 				Excludes: excludes,
 			},
-			Breaking: bufbreaking.ExternalConfigV1(v1beta1Config.Breaking),
-			Lint:     buflint.ExternalConfigV1(v1beta1Config.Lint),
+			Breaking: bufbreaking.ExternalConfigV1{
+				Use:                    v1beta1Config.Breaking.Use,
+				Except:                 v1beta1Config.Breaking.Except,
+				Ignore:                 v1beta1Config.Breaking.Ignore,
+				IgnoreOnly:             v1beta1Config.Breaking.IgnoreOnly,
+				IgnoreUnstablePackages: v1beta1Config.Breaking.IgnoreUnstablePackages,
+				Enabled:                v1beta1Config.Breaking.Enabled,
+				Granularity:            v1beta1Config.Breaking.Granularity,
+			},
+			Lint: buflint.ExternalConfigV1(v1beta1Config.Lint),
 		}
 		newConfigPath := filepath.Join(dirPath, bufconfig.ExternalConfigFilePath)
 		if err := m.writeV1Config(newConfigPath, v1Config, ".", v1beta1Config.Name); err != nil {
@@ -204,6 +212,8 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 				Use:                    v1beta1Config.Breaking.Use,
 				Except:                 v1beta1Config.Breaking.Except,
 				IgnoreUnstablePackages: v1beta1Config.Breaking.IgnoreUnstablePackages,
+				Enabled:                v1beta1Config.Breaking.Enabled,
+				Granularity:            v1beta1Config.Breaking.Granularity,
 			},
 			Lint: buflint.ExternalConfigV1{
 				Use:                                  v1beta1Config.Lint.Use,
@@ -214,6 +224,11 @@ func (m *v1beta1Migrator) maybeMigrateConfig(dirPath string) (bool, error) {
 				RPCAllowGoogleProtobufEmptyRequests:  v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyRequests,
 				RPCAllowGoogleProtobufEmptyResponses: v1beta1Config.Lint.RPCAllowGoogleProtobufEmptyResponses,
 				AllowCommentIgnores:                  v1beta1Config.Lint.AllowCommentIgnores,
+				Proto2Except:                         v1beta1Config.Lint.Proto2Except,
+				Proto3Except:                         v1beta1Config.Lint.Proto3Except,
+				Enabled:                              v1beta1Config.Lint.Enabled,
+				MessageTemplates:                     v1beta1Config.Lint.MessageTemplates,
+				AllowedFieldNumberRanges:             v1beta1Config.Lint.AllowedFieldNumberRanges,
 			},
 		}
 
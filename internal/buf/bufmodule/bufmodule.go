@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +34,10 @@ import (
 const (
 	// DocumentationFilePath defines the path to the documentation file, relative to the root of the module.
 	DocumentationFilePath = "buf.md"
+	// IgnoreFilePath defines the path to the ignore file, relative to the root of the module.
+	//
+	// The files matching the patterns of the ignore file are omitted from the module.
+	IgnoreFilePath = ".bufignore"
 	// MainBranch is the name of the branch created for every repository.
 	// This is the default branch used if no branch or commit is specified.
 	MainBranch = "main"
@@ -47,8 +52,89 @@ const (
 	//
 	// It is used by the CLI cache and intended to eventually replace b2.
 	b2DigestPrefix = "b2"
+
+	// shake256DigestPrefix is the digest prefix for the digest returned by Module.Digest.
+	shake256DigestPrefix = "shake256"
+)
+
+const (
+	// DigestAlgorithmB1 is the b1 digest algorithm computed by ModuleDigestB1.
+	DigestAlgorithmB1 DigestAlgorithm = iota + 1
+	// DigestAlgorithmB2 is the b2 digest algorithm computed by ModuleDigestB2.
+	DigestAlgorithmB2
 )
 
+const (
+	// SymlinkPolicyResolve follows symlinks.
+	//
+	// Files that resolve to the same target are only included once, at the first
+	// path the target is reached at while walking.
+	SymlinkPolicyResolve SymlinkPolicy = iota + 1
+	// SymlinkPolicyReject returns an error for any file reached through a symlink.
+	SymlinkPolicyReject
+)
+
+var (
+	// AllDigestAlgorithmStrings is all digest algorithm strings.
+	AllDigestAlgorithmStrings = []string{
+		b1DigestPrefix,
+		b2DigestPrefix,
+	}
+
+	stringToDigestAlgorithm = map[string]DigestAlgorithm{
+		b1DigestPrefix: DigestAlgorithmB1,
+		b2DigestPrefix: DigestAlgorithmB2,
+	}
+	digestAlgorithmToString = map[DigestAlgorithm]string{
+		DigestAlgorithmB1: b1DigestPrefix,
+		DigestAlgorithmB2: b2DigestPrefix,
+	}
+
+	symlinkPolicyToString = map[SymlinkPolicy]string{
+		SymlinkPolicyResolve: "resolve",
+		SymlinkPolicyReject:  "reject",
+	}
+)
+
+// DigestAlgorithm is an algorithm used to compute a module digest.
+type DigestAlgorithm int
+
+// String implements fmt.Stringer.
+func (d DigestAlgorithm) String() string {
+	s, ok := digestAlgorithmToString[d]
+	if !ok {
+		return strconv.Itoa(int(d))
+	}
+	return s
+}
+
+// ParseDigestAlgorithm parses the DigestAlgorithm.
+//
+// The empty string defaults to DigestAlgorithmB1.
+func ParseDigestAlgorithm(s string) (DigestAlgorithm, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return DigestAlgorithmB1, nil
+	}
+	d, ok := stringToDigestAlgorithm[s]
+	if ok {
+		return d, nil
+	}
+	return 0, fmt.Errorf("unknown digest algorithm: %q", s)
+}
+
+// SymlinkPolicy is the policy for module files reached through symlinks.
+type SymlinkPolicy int
+
+// String implements fmt.Stringer.
+func (s SymlinkPolicy) String() string {
+	str, ok := symlinkPolicyToString[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	return str
+}
+
 // FileInfo contains module file info.
 type FileInfo interface {
 	// Path is the path of the file relative to the root it is contained within.
@@ -81,6 +167,11 @@ type FileInfo interface {
 	// even if ModuleIdentity is set, that is commit is optional information
 	// even if we know what module this file came from.
 	Commit() string
+	// IsSymlink returns true if the path of the file was reached through a symlink.
+	//
+	// This is only set for the source files of Modules constructed with
+	// ModuleWithSymlinkPolicy, and is false otherwise.
+	IsSymlink() bool
 	// WithIsImport returns this FileInfo with the given IsImport value.
 	WithIsImport(isImport bool) FileInfo
 
@@ -181,7 +272,9 @@ func NewModuleIdentity(
 
 // ModuleIdentityForString returns a new ModuleIdentity for the given string.
 //
-// This parses the path in the form remote/owner/repository
+// This parses the path in the form remote/owner/repository. The remote may include
+// a port, such as bsr.internal.acme.com:8443, which must be between 1 and 65535.
+// The port is part of the Remote, and is included in the IdentityString.
 //
 // TODO: we may want to add a special error if we detect / or @ as this may be a common mistake.
 func ModuleIdentityForString(path string) (ModuleIdentity, error) {
@@ -192,9 +285,34 @@ func ModuleIdentityForString(path string) (ModuleIdentity, error) {
 	return NewModuleIdentity(remote, owner, repository)
 }
 
+// ModuleIdentityMatcher matches ModuleIdentities against a pattern.
+type ModuleIdentityMatcher interface {
+	// String returns the pattern.
+	fmt.Stringer
+
+	// Matches returns true if the IdentityString of the ModuleIdentity matches the pattern.
+	Matches(moduleIdentity ModuleIdentity) bool
+
+	isModuleIdentityMatcher()
+}
+
+// NewModuleIdentityMatcher returns a new ModuleIdentityMatcher for the pattern.
+//
+// The pattern is of the form remote/owner/repository, where any component can use
+// wildcards with the syntax of path.Match, so "*" matches any sequence of characters
+// within a single component. A "**" component matches zero or more components, for
+// example "bsr.acme.com/**" matches every module of the remote bsr.acme.com, while
+// "bsr.acme.com/acme/*" only matches the repositories of the owner acme.
+//
+// A pattern without a "**" component must have three components. The pattern is
+// validated with normalpath.ValidateGlob.
+func NewModuleIdentityMatcher(pattern string) (ModuleIdentityMatcher, error) {
+	return newModuleIdentityMatcher(pattern)
+}
+
 // ModuleReference is a module reference.
 //
-// It references either a branch, tag, or a commit.
+// It references either a branch, tag, a commit, or a version constraint.
 // Note that since commits belong to branches, we can deduce
 // the branch from the commit when resolving.
 type ModuleReference interface {
@@ -203,12 +321,50 @@ type ModuleReference interface {
 	// Prints either remote/owner/repository:{branch,commit}
 	fmt.Stringer
 
-	// Either branch, tag, or commit
+	// Either branch, tag, commit, or version constraint
 	Reference() string
+	// Constraint returns the version constraint of the reference, such as
+	// ">=v1.2.0 <v2.0.0".
+	//
+	// Returns nil if the reference is a branch, tag, or commit.
+	Constraint() VersionConstraint
 
 	isModuleReference()
 }
 
+// VersionConstraint is a constraint on the semantic version of a module.
+//
+// A VersionConstraint is a whitespace-separated list of comparisons, all of which
+// must be satisfied, such as ">=v1.2.0 <v2.0.0". The comparison operators are
+// >=, <=, >, <, and =, and versions are of the form vMAJOR.MINOR.PATCH with
+// an optional pre-release suffix.
+type VersionConstraint interface {
+	// Prints the normalized constraint, ie ">=v1.2.0 <v2.0.0".
+	fmt.Stringer
+
+	// Matches returns true if the version satisfies every comparison.
+	//
+	// Returns false if the version is not a semantic version.
+	Matches(version string) bool
+
+	isVersionConstraint()
+}
+
+// ParseVersionConstraint parses the VersionConstraint.
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	return newVersionConstraint(s)
+}
+
+// ResolveModuleReference returns the ModuleReference within available with the highest
+// version that satisfies the Constraint of the given ModuleReference.
+//
+// Only the ModuleReferences with the same identity as the constraint whose references
+// are semantic versions are considered. Returns an error if the given ModuleReference
+// has no Constraint, or if no ModuleReference satisfies it.
+func ResolveModuleReference(available []ModuleReference, constraint ModuleReference) (ModuleReference, error) {
+	return resolveModuleReference(available, constraint)
+}
+
 // NewModuleReference returns a new validated ModuleReference.
 func NewModuleReference(
 	remote string,
@@ -260,7 +416,9 @@ func NewProtoModuleReferencesForModuleReferences(moduleReferences ...ModuleRefer
 // ModuleReferenceForString returns a new ModuleReference for the given string.
 // If a branch or commit is not provided, the "main" branch is used.
 //
-// This parses the path in the form remote/owner/repository{:branch,:commit}.
+// This parses the path in the form remote/owner/repository{:branch,:commit,:constraint}.
+// A reference that starts with a comparison operator, such as ">=v1.2.0 <v2.0.0",
+// is parsed as a VersionConstraint, and an invalid constraint is an error.
 func ModuleReferenceForString(path string) (ModuleReference, error) {
 	remote, owner, repository, reference, err := parseModuleReferenceComponents(path)
 	if err != nil {
@@ -273,6 +431,40 @@ func ModuleReferenceForString(path string) (ModuleReference, error) {
 	return NewModuleReference(remote, owner, repository, reference)
 }
 
+// NormalizeReferenceString validates the module reference string and returns its canonical form.
+//
+// The canonical form is remote/owner/repository:reference. The remote, owner, and repository
+// are lowercased, as is the reference if it is a commit. Branches and tags are case-sensitive
+// and are left as-is. If no reference is given, the MainBranch is used.
+//
+// Returns an error describing what is wrong if the string is malformed.
+func NormalizeReferenceString(s string, options ...NormalizeOption) (string, error) {
+	return normalizeReferenceString(s, options...)
+}
+
+// NormalizeOption is an option for NormalizeReferenceString.
+type NormalizeOption func(*normalizeOptions)
+
+// NormalizeWithDefaultRemote returns a new NormalizeOption that allows references of the
+// shorthand form owner/repository:reference, which use the given remote.
+//
+// The default is to require the remote.
+func NormalizeWithDefaultRemote(remote string) NormalizeOption {
+	return func(normalizeOptions *normalizeOptions) {
+		normalizeOptions.defaultRemote = remote
+	}
+}
+
+// NormalizeWithoutMainBranch returns a new NormalizeOption that omits the reference from
+// the canonical form if it is the MainBranch, resulting in remote/owner/repository.
+//
+// The default is to always include the reference.
+func NormalizeWithoutMainBranch() NormalizeOption {
+	return func(normalizeOptions *normalizeOptions) {
+		normalizeOptions.withoutMainBranch = true
+	}
+}
+
 // IsCommitModuleReference returns true if the ModuleReference references a commit.
 //
 // If false, this means the ModuleReference references a branch or tag.
@@ -387,10 +579,27 @@ type Module interface {
 	//
 	// The returned SourceFileInfos are sorted by path.
 	SourceFileInfos(ctx context.Context) ([]FileInfo, error)
+	// WalkSourceFileInfos calls f for every FileInfo belonging to the module.
+	//
+	// It does not include dependencies.
+	//
+	// Unlike SourceFileInfos, the FileInfos are not accumulated, and are passed to f
+	// in an unspecified order, unless WalkSourceFileInfosWithSorted is used. If f
+	// returns an error, the walk stops and the error is returned.
+	WalkSourceFileInfos(ctx context.Context, f func(FileInfo) error, options ...WalkSourceFileInfosOption) error
 	// GetModuleFile gets the source file for the given path.
 	//
 	// Returns storage.IsNotExist error if the file does not exist.
 	GetModuleFile(ctx context.Context, path string) (ModuleFile, error)
+	// AllPaths returns the paths of the source files, sorted.
+	//
+	// These are the paths of SourceFileInfos, without constructing the FileInfos.
+	AllPaths(ctx context.Context) ([]string, error)
+	// ContainsPath returns true if the Module has a source file at the path.
+	//
+	// Returns an error if the path is not a valid module file path, and false if
+	// the path is valid but there is no source file at the path.
+	ContainsPath(ctx context.Context, path string) (bool, error)
 	// DependencyModulePins gets the dependency ModulePins.
 	//
 	// The returned ModulePins are sorted by remote, owner, repository, branch, commit, and then digest.
@@ -401,6 +610,23 @@ type Module interface {
 	// Documentation gets the contents of the module documentation file, buf.md and returns the string representation.
 	// This may return an empty string if the documentation file does not exist.
 	Documentation() string
+	// DocumentationForPath gets the contents of the documentation file in the given
+	// directory, relative to the root of the module.
+	//
+	// For the root directory ".", this is Documentation. The documentation files of
+	// other directories are only read if the Module was constructed with
+	// ModuleWithDirectoryDocumentation or ModuleWithDocumentationFileNames. This returns
+	// an empty string if the directory has no documentation file.
+	DocumentationForPath(dirPath string) string
+	// Digest returns a digest of the logical content of the module.
+	//
+	// The digest covers the contents of every .proto file keyed by path, the
+	// DependencyModulePins, and the Documentation. It does not depend on the
+	// order files are walked in, or on external paths, so two modules with the
+	// same content that were read from different locations have the same digest.
+	//
+	// The returned digest is of the form "shake256:<hex>".
+	Digest(ctx context.Context) (string, error)
 
 	getSourceReadBucket() storage.ReadBucket
 	// Note this *can* be nil if we did not build from a named module.
@@ -416,9 +642,27 @@ type Module interface {
 	getModuleIdentity() ModuleIdentity
 	// Note this can be empty.
 	getCommit() string
+	// isImportPath returns true if the path is within one of the import paths
+	// the Module was constructed with.
+	isImportPath(path string) bool
 	isModule()
 }
 
+// WalkSourceFileInfosOption is an option for WalkSourceFileInfos.
+type WalkSourceFileInfosOption func(*walkSourceFileInfosOptions)
+
+// WalkSourceFileInfosWithSorted returns a new WalkSourceFileInfosOption that passes
+// the FileInfos to f sorted by path.
+//
+// This requires the FileInfos to be accumulated before f is called.
+//
+// The default is to pass the FileInfos in an unspecified order.
+func WalkSourceFileInfosWithSorted() WalkSourceFileInfosOption {
+	return func(walkSourceFileInfosOptions *walkSourceFileInfosOptions) {
+		walkSourceFileInfosOptions.sorted = true
+	}
+}
+
 // ModuleOption is used to construct Modules.
 type ModuleOption func(*module)
 
@@ -437,6 +681,149 @@ func ModuleWithModuleIdentityAndCommit(moduleIdentity ModuleIdentity, commit str
 	}
 }
 
+// ModuleWithLockFilePath is used to construct a Module that reads its dependencies
+// from the lock file at the given path relative to the root of the read bucket.
+//
+// This only affects NewModuleForBucket. If no lock file exists at the path, the
+// Module has no dependencies.
+//
+// The default is buflock.ExternalConfigFilePath.
+func ModuleWithLockFilePath(lockFilePath string) ModuleOption {
+	return func(module *module) {
+		module.lockFilePath = lockFilePath
+	}
+}
+
+// ModuleWithExcludeGlobs is used to construct a Module that omits the .proto files
+// matching any of the given glob patterns.
+//
+// The patterns are matched against paths relative to the root of the read bucket,
+// using the syntax of normalpath.MatchGlob, and are expected to have been validated
+// with normalpath.ValidateGlob.
+//
+// The default is to not exclude any files.
+func ModuleWithExcludeGlobs(excludeGlobs []string) ModuleOption {
+	return func(module *module) {
+		module.excludeGlobs = excludeGlobs
+	}
+}
+
+// IgnoreMatcher matches the paths ignored by the patterns of an ignore file.
+type IgnoreMatcher interface {
+	// Matches returns true if the path is ignored.
+	//
+	// The path is relative to the root of the module.
+	Matches(path string) bool
+
+	isIgnoreMatcher()
+}
+
+// NewIgnoreMatcher returns a new IgnoreMatcher for the data of an ignore file.
+//
+// The patterns use the syntax of gitignore files. Each line is a pattern, and blank
+// lines and lines starting with "#" are skipped. A pattern starting with "!"
+// re-includes the paths matched by an earlier pattern, unless a parent directory
+// of the path is ignored. A pattern ending with "/" only matches directories. A pattern
+// with a "/" at the beginning or in the middle is relative to the root of the module,
+// and all other patterns match at any depth. Path components are matched with the
+// syntax of normalpath.MatchGlob.
+//
+// Modules created from a bucket with an ignore file at IgnoreFilePath omit the
+// files matched by the ignore file.
+func NewIgnoreMatcher(data []byte) (IgnoreMatcher, error) {
+	return newIgnoreMatcher(data)
+}
+
+// ModuleWithDocumentation is used to construct a Module with the given documentation.
+//
+// This takes precedence over the documentation file at DocumentationFilePath, which
+// is ignored if this option is set, even if the documentation is empty.
+//
+// The default is to read the documentation from DocumentationFilePath.
+func ModuleWithDocumentation(documentation string) ModuleOption {
+	return func(module *module) {
+		module.documentationOverride = &documentation
+	}
+}
+
+// ModuleWithDirectoryDocumentation is used to construct a Module that also reads the
+// documentation files named DocumentationFilePath in the subdirectories of the read bucket,
+// such as per-package documentation.
+//
+// This is equivalent to ModuleWithDocumentationFileNames(DocumentationFilePath).
+//
+// The default is to only read the documentation file at the root of the read bucket.
+func ModuleWithDirectoryDocumentation() ModuleOption {
+	return ModuleWithDocumentationFileNames(DocumentationFilePath)
+}
+
+// ModuleWithDocumentationFileNames is used to construct a Module that also reads the
+// documentation files in the subdirectories of the read bucket whose base names match
+// any of the given file names, such as "README.md" or "*.md".
+//
+// The file names use the syntax of path.Match. If more than one file in a directory
+// matches, the file matching the earliest file name is used, and then the file that
+// sorts first. The documentation of a subdirectory is returned by DocumentationForPath.
+// Documentation and the Digest are not affected.
+//
+// The default is to only read the documentation file at the root of the read bucket.
+func ModuleWithDocumentationFileNames(fileNames ...string) ModuleOption {
+	return func(module *module) {
+		module.documentationFileNames = fileNames
+	}
+}
+
+// ModuleWithTargetFilePaths is used to construct a Module whose TargetFileInfos only
+// contain the files at the given paths.
+//
+// The paths are relative to the root of the read bucket, and are normalized and validated.
+// Every path must be a file that exists in the Module, otherwise construction fails with
+// an error that satisfies storage.IsNotExist. SourceFileInfos and GetModuleFile are not
+// affected, so all files remain available for import resolution.
+//
+// Unlike ModuleWithTargetPaths, which wraps an existing Module and also accepts directories,
+// this makes the target files part of the constructed Module.
+//
+// The default is for TargetFileInfos to contain all the files of the Module.
+func ModuleWithTargetFilePaths(targetFilePaths []string) ModuleOption {
+	return func(module *module) {
+		module.targetFilePaths = targetFilePaths
+		module.targetFilePathsSet = true
+	}
+}
+
+// ModuleWithSymlinkPolicy is used to construct a Module that applies the SymlinkPolicy
+// to its source files that are reached through symlinks.
+//
+// The policy is applied by SourceFileInfos, WalkSourceFileInfos, TargetFileInfos, and
+// GetModuleFile, and the resulting FileInfos record whether they were reached through
+// a symlink. Symlinks are only visible to the Module if the read bucket follows them and
+// returns storage.ResolvedObjectInfos, such as storageos buckets with symlinks enabled.
+// The files of buckets that do not follow symlinks are never reached through a symlink.
+//
+// The default is to use the files of the read bucket as-is.
+func ModuleWithSymlinkPolicy(symlinkPolicy SymlinkPolicy) ModuleOption {
+	return func(module *module) {
+		module.symlinkPolicy = symlinkPolicy
+	}
+}
+
+// ModuleWithImportPaths is used to construct a Module whose source files within the
+// given paths are imports, such as copies of the well-known types that are vendored
+// into the Module.
+//
+// The paths are relative to the root of the read bucket, and are normalized and validated.
+// A path can be a file or a directory. The FileInfos of the matching files returned
+// from SourceFileInfos, TargetFileInfos, and GetModuleFile have IsImport set to true.
+// The files are still part of the Module.
+//
+// The default is for no source files to be imports.
+func ModuleWithImportPaths(importPaths []string) ModuleOption {
+	return func(module *module) {
+		module.importPaths = importPaths
+	}
+}
+
 // NewModuleForBucket returns a new Module. It attempts reads dependencies
 // from a lock file in the read bucket.
 func NewModuleForBucket(
@@ -447,6 +834,18 @@ func NewModuleForBucket(
 	return newModuleForBucket(ctx, readBucket, options...)
 }
 
+// NewModuleForFiles returns a new Module for the given map from path to file content.
+//
+// The files are put in an in-memory bucket, and the Module is then created the same
+// way as with NewModuleForBucket. Every path is validated with ValidateModuleFilePath.
+func NewModuleForFiles(
+	ctx context.Context,
+	pathToData map[string][]byte,
+	options ...ModuleOption,
+) (Module, error) {
+	return newModuleForFiles(ctx, pathToData, options...)
+}
+
 // NewModuleForBucketWithDependencyModulePins explicitly specifies the dependencies
 // that should be used when creating the Module. The module names must be resolved
 // and unique.
@@ -552,6 +951,10 @@ type Workspace interface {
 // ModuleToProtoModule converts the Module to a proto Module.
 //
 // This takes all Sources and puts them in the Module, not just Targets.
+// The documentation and dependency pins are included as well.
+//
+// This is the inverse of NewModuleForProto, and round-tripping a proto Module
+// through NewModuleForProto and ModuleToProtoModule results in an equal proto Module.
 func ModuleToProtoModule(ctx context.Context, module Module) (*modulev1alpha1.Module, error) {
 	// these are returned sorted, so there is no need to sort
 	// the resulting protoModuleFiles afterwards
@@ -703,6 +1106,31 @@ func ModuleDigestB2(ctx context.Context, module Module) (string, error) {
 	return fmt.Sprintf("%s-%s", b2DigestPrefix, base64.URLEncoding.EncodeToString(hash.Sum(nil))), nil
 }
 
+// ModuleDigest returns the digest for the Module computed with the given DigestAlgorithm.
+func ModuleDigest(ctx context.Context, module Module, digestAlgorithm DigestAlgorithm) (string, error) {
+	switch digestAlgorithm {
+	case DigestAlgorithmB1:
+		return ModuleDigestB1(ctx, module)
+	case DigestAlgorithmB2:
+		return ModuleDigestB2(ctx, module)
+	default:
+		return "", fmt.Errorf("unknown DigestAlgorithm: %v", digestAlgorithm)
+	}
+}
+
+// ModuleDigestEqual returns true if the two Modules have the same Digest.
+func ModuleDigestEqual(ctx context.Context, a Module, b Module) (bool, error) {
+	aDigest, err := a.Digest(ctx)
+	if err != nil {
+		return false, err
+	}
+	bDigest, err := b.Digest(ctx)
+	if err != nil {
+		return false, err
+	}
+	return aDigest == bDigest, nil
+}
+
 // ModuleToBucket writes the given Module to the WriteBucket.
 //
 // This writes the sources and the buf.lock file.
@@ -812,8 +1240,43 @@ func ModulePinEqual(a ModulePin, b ModulePin) bool {
 }
 
 // PutModuleDependencyModulePinsToBucket writes the module dependencies to the write bucket in the form of a lock file.
-func PutModuleDependencyModulePinsToBucket(ctx context.Context, writeBucket storage.WriteBucket, pins []ModulePin) error {
-	return putModulePinsToBucket(ctx, writeBucket, pins)
+func PutModuleDependencyModulePinsToBucket(
+	ctx context.Context,
+	writeBucket storage.WriteBucket,
+	pins []ModulePin,
+	options ...PutModuleDependencyModulePinsOption,
+) error {
+	return putModulePinsToBucket(ctx, writeBucket, pins, options...)
+}
+
+// PutModuleDependencyModulePinsOption is an option for PutModuleDependencyModulePinsToBucket.
+type PutModuleDependencyModulePinsOption func(*putModuleDependencyModulePinsOptions)
+
+// PutModuleDependencyModulePinsWithLockFilePath returns a new PutModuleDependencyModulePinsOption
+// that writes the lock file to the given path relative to the root of the write bucket.
+//
+// The default is buflock.ExternalConfigFilePath.
+func PutModuleDependencyModulePinsWithLockFilePath(lockFilePath string) PutModuleDependencyModulePinsOption {
+	return func(putModuleDependencyModulePinsOptions *putModuleDependencyModulePinsOptions) {
+		putModuleDependencyModulePinsOptions.lockFilePath = lockFilePath
+	}
+}
+
+// PutModuleDependencyModulePinsWithDigestAlgorithm returns a new PutModuleDependencyModulePinsOption
+// that writes pins with digests computed with the given DigestAlgorithm.
+//
+// Pins whose digest was not computed with the DigestAlgorithm are digested again from the
+// Module read with the ModuleReader. Pins that already use the DigestAlgorithm are written as-is.
+//
+// The default is to write the digests of the pins as-is.
+func PutModuleDependencyModulePinsWithDigestAlgorithm(
+	digestAlgorithm DigestAlgorithm,
+	moduleReader ModuleReader,
+) PutModuleDependencyModulePinsOption {
+	return func(putModuleDependencyModulePinsOptions *putModuleDependencyModulePinsOptions) {
+		putModuleDependencyModulePinsOptions.digestAlgorithm = digestAlgorithm
+		putModuleDependencyModulePinsOptions.moduleReader = moduleReader
+	}
 }
 
 // SortModulePins sorts the ModulePins.
@@ -822,3 +1285,475 @@ func SortModulePins(modulePins []ModulePin) {
 		return modulePinLess(modulePins[i], modulePins[j])
 	})
 }
+
+// CompareModuleReferences compares the ModuleReferences by remote, then owner, then
+// repository, then reference.
+//
+// Returns -1 if a is less than b, 1 if a is greater than b, and 0 if they are equal.
+// A nil ModuleReference is less than any other ModuleReference.
+func CompareModuleReferences(a ModuleReference, b ModuleReference) int {
+	return moduleReferenceCompareTo(a, b)
+}
+
+// SortModuleReferences sorts the ModuleReferences with CompareModuleReferences.
+//
+// The sort is stable, so equal ModuleReferences keep their relative order.
+func SortModuleReferences(moduleReferences []ModuleReference) {
+	sort.SliceStable(moduleReferences, func(i, j int) bool {
+		return moduleReferenceCompareTo(moduleReferences[i], moduleReferences[j]) < 0
+	})
+}
+
+// FileForSymbol returns the FileInfo of the source file that declares the given symbol.
+//
+// The symbol must be the fully-qualified name of a message, enum, or service, including
+// the package. A leading dot is allowed.
+//
+// Returns an error that fufills storage.IsNotExist if the symbol is not declared in the Module.
+func FileForSymbol(ctx context.Context, module Module, symbol string) (FileInfo, error) {
+	return fileForSymbol(ctx, module, symbol)
+}
+
+// MessageFieldStats are the message and field counts of a Module.
+//
+// This is informational only.
+type MessageFieldStats struct {
+	// TotalMessages is the number of messages, including nested messages.
+	//
+	// The synthetic map entry messages generated for map fields are not counted.
+	TotalMessages int
+	// TotalFields is the number of fields across all messages.
+	//
+	// Extensions are not counted.
+	TotalFields int
+	// AverageFieldsPerMessage is TotalFields divided by TotalMessages.
+	//
+	// This is 0 if there are no messages.
+	AverageFieldsPerMessage float64
+}
+
+// ModuleMessageFieldStats returns the MessageFieldStats for the source files of the Module.
+func ModuleMessageFieldStats(ctx context.Context, module Module) (MessageFieldStats, error) {
+	return moduleMessageFieldStats(ctx, module)
+}
+
+// RemapModulePaths returns a new Module with the path of every source file of the
+// given Module replaced by the result of mapper, and the contents unchanged.
+//
+// The mapped paths will be normalized and validated, must be .proto files, and must be
+// unique. The documentation and dependency pins of the Module are preserved. The
+// ModuleIdentity and commit are not, as the new Module no longer matches them.
+func RemapModulePaths(ctx context.Context, module Module, mapper func(string) (string, error)) (Module, error) {
+	return remapModulePaths(ctx, module, mapper)
+}
+
+// Enum is an enum defined in a source file of a Module.
+type Enum struct {
+	// FullName is the fully-qualified name of the enum, without a leading dot.
+	//
+	// Enums nested within messages are qualified by their parent messages.
+	FullName string
+	// FileInfo is the file the enum is defined in.
+	FileInfo FileInfo
+	// Values are the values of the enum in the order they are declared.
+	Values []EnumValue
+}
+
+// EnumValue is a value of an Enum.
+type EnumValue struct {
+	Name   string
+	Number int32
+}
+
+// ModuleEnums returns the Enums defined in the source files of the Module,
+// including nested enums.
+//
+// The returned Enums are sorted by full name.
+func ModuleEnums(ctx context.Context, module Module) ([]Enum, error) {
+	return moduleEnums(ctx, module)
+}
+
+// Extension is an extension declared in a source file of a Module.
+type Extension struct {
+	// FullName is the fully-qualified name of the extension, without a leading dot.
+	//
+	// Extensions declared within messages are qualified by their parent messages.
+	FullName string
+	// Extendee is the name of the extended message as written in the source file,
+	// without a leading dot.
+	//
+	// Source files are not linked, so this may be relative to the scope of the extension.
+	Extendee string
+	// Number is the field number of the extension.
+	Number int32
+	// FileInfo is the file the extension is declared in.
+	FileInfo FileInfo
+}
+
+// ModuleExtensions returns the Extensions declared in the source files of the Module.
+//
+// The returned Extensions are sorted by file path, and then by the order they are declared
+// in, with extensions declared at the top level of a file first.
+func ModuleExtensions(ctx context.Context, module Module) ([]Extension, error) {
+	return moduleExtensions(ctx, module)
+}
+
+// ModulePrimarySyntax returns the syntax declared by the most source files of the Module,
+// for example "proto3".
+//
+// Files without a syntax declaration are not counted. Ties are broken in favor of the
+// lexicographically greatest syntax. Returns an error if no source file declares a syntax.
+//
+// Editions are not supported by the parser yet, and files using them fail to parse.
+func ModulePrimarySyntax(ctx context.Context, module Module) (string, error) {
+	return modulePrimarySyntax(ctx, module)
+}
+
+// MaxImportDepth returns the length of the longest import chain between the source files
+// of the Module, along with the paths of the files in that chain in import order.
+//
+// For example, if a.proto imports b.proto, which imports c.proto, the depth is 3 and the
+// chain is a.proto, b.proto, c.proto. Imports of files outside of the Module, such as
+// dependencies and the Well-Known Types, are not part of any chain. Returns an error if
+// there is an import cycle.
+func MaxImportDepth(ctx context.Context, module Module) (int, []string, error) {
+	return maxImportDepth(ctx, module)
+}
+
+// ReverseImportIndex returns a map from the path of each file imported by or contained in the
+// source files of the Module to the sorted paths of the source files that import it.
+//
+// This is the transpose of the import graph. Every source file is a key, and source files that
+// no source file imports map to an empty slice. Files outside of the Module, such as dependencies
+// and the Well-Known Types, are keys if they are imported.
+func ReverseImportIndex(ctx context.Context, module Module) (map[string][]string, error) {
+	return reverseImportIndex(ctx, module)
+}
+
+// ImportCounts are the import counts of a file.
+type ImportCounts struct {
+	// FanOut is the number of distinct files the file imports.
+	FanOut int
+	// FanIn is the number of source files of the Module that import the file.
+	FanIn int
+}
+
+// FileImportCounts returns a map from the path of each source file of the Module to
+// its ImportCounts.
+//
+// Imports of files outside of the Module, such as dependencies and the Well-Known Types,
+// count towards the FanOut of the importing file, but these files are not keys.
+func FileImportCounts(ctx context.Context, module Module) (map[string]ImportCounts, error) {
+	return fileImportCounts(ctx, module)
+}
+
+// CustomOptionRef is a reference to a custom option from a source file of a Module.
+type CustomOptionRef struct {
+	// Name is the name of the option as written in the source file, such as "(foo.bar)"
+	// or "(foo.bar).baz".
+	//
+	// Source files are not linked, so extension names may be relative to the scope
+	// they are used in.
+	Name string
+	// FileInfo is the file that references the custom option.
+	FileInfo FileInfo
+}
+
+// CustomOptionsReferenced returns a CustomOptionRef for every custom option referenced
+// by the source files of the Module.
+//
+// Each custom option is returned once per file. The returned CustomOptionRefs are sorted
+// by file path, and then by name.
+func CustomOptionsReferenced(ctx context.Context, module Module) ([]CustomOptionRef, error) {
+	return customOptionsReferenced(ctx, module)
+}
+
+// Inconsistency is an inconsistency found in a source file of a Module.
+type Inconsistency struct {
+	// FileInfo is the file the inconsistency was found in.
+	FileInfo FileInfo
+	// Message describes the inconsistency.
+	Message string
+}
+
+// ValidatePackageDirectoryConsistency returns an Inconsistency for every source file of the
+// Module whose package does not match its directory.
+//
+// For example, a file in the directory foo/bar/v1 must have the package foo.bar.v1, and a file
+// in the root directory must have no package.
+func ValidatePackageDirectoryConsistency(ctx context.Context, module Module) ([]Inconsistency, error) {
+	return validatePackageDirectoryConsistency(ctx, module)
+}
+
+// ValidateFieldPresenceConsistency returns an Inconsistency for every message in a proto3
+// source file of the Module that mixes fields declared optional with singular scalar
+// fields that are not.
+//
+// Repeated fields, fields in a oneof, and fields that reference a message or an enum
+// are not considered.
+func ValidateFieldPresenceConsistency(ctx context.Context, module Module) ([]Inconsistency, error) {
+	return validateFieldPresenceConsistency(ctx, module)
+}
+
+// ModulePackages returns the sorted unique packages declared by the source files of the Module.
+//
+// Files without a package are not included.
+func ModulePackages(ctx context.Context, module Module) ([]string, error) {
+	return modulePackages(ctx, module)
+}
+
+// ValidateRequiredPackages returns the sorted unique packages of required that are not
+// declared by any source file of the Module.
+//
+// The returned slice is empty if the Module declares every required package.
+func ValidateRequiredPackages(ctx context.Context, module Module, required []string) ([]string, error) {
+	return validateRequiredPackages(ctx, module, required)
+}
+
+// Violation is a policy violation caused by an import of a source file of a Module.
+type Violation struct {
+	// FileInfo is the file that contains the import.
+	FileInfo FileInfo
+	// ImportPath is the path of the imported file.
+	ImportPath string
+	// Message describes the violation.
+	Message string
+}
+
+// ValidateVersionImportBoundaries returns a Violation for every import within the Module
+// where a file in a versioned package imports a file in a package with a newer major version,
+// for example a file in foo.v1 importing a file in foo.v2.
+//
+// Only imports of source files within the Module are checked, and files in packages without
+// a package version are ignored.
+func ValidateVersionImportBoundaries(ctx context.Context, module Module) ([]Violation, error) {
+	return validateVersionImportBoundaries(ctx, module)
+}
+
+// FilesExceedingLineLength returns a map from the path of every source file of the Module
+// that has lines longer than maxLen characters, to the 1-based line numbers of those lines.
+//
+// Line length is measured in runes, excluding the line terminator. Files with no
+// lines longer than maxLen are not included. Returns an error if maxLen is less than 1.
+func FilesExceedingLineLength(ctx context.Context, module Module, maxLen int) (map[string][]int, error) {
+	return filesExceedingLineLength(ctx, module, maxLen)
+}
+
+const (
+	// VersionBumpMajor is the version bump recommended for removals.
+	VersionBumpMajor = "major"
+	// VersionBumpMinor is the version bump recommended for additions.
+	VersionBumpMinor = "minor"
+	// VersionBumpPatch is the version bump recommended if there are no additions or removals.
+	VersionBumpPatch = "patch"
+)
+
+// RecommendVersionBump returns the semantic version bump recommended for the changes
+// from the baseline Module to the current Module.
+//
+// This is a lightweight heuristic based on the messages, fields, enums, enum values,
+// services, and methods declared in the source files of each Module, and not a full
+// breaking change analysis. If any declaration of the baseline was removed or renamed,
+// this returns VersionBumpMajor. Otherwise, if any declaration was added, this returns
+// VersionBumpMinor. Otherwise, this returns VersionBumpPatch.
+func RecommendVersionBump(ctx context.Context, current Module, baseline Module) (string, error) {
+	return recommendVersionBump(ctx, current, baseline)
+}
+
+// ValidateDocumentationMarkdown validates that the documentation of the Module is
+// structurally valid Markdown.
+//
+// This is lenient, and only returns an error for structural problems that break
+// rendering, such as code fences that are never closed and links that are never
+// terminated. Style is not checked. Empty documentation is valid.
+func ValidateDocumentationMarkdown(module Module) error {
+	return validateDocumentationMarkdown(module)
+}
+
+// ModuleExternalImports returns the sorted unique paths imported by the source files
+// of the Module that are not source files of the Module.
+//
+// This includes imports of the Well-Known Types and of files provided by dependencies.
+func ModuleExternalImports(ctx context.Context, module Module) ([]string, error) {
+	return moduleExternalImports(ctx, module)
+}
+
+// WorkspaceExternalImports returns the sorted unique paths imported by the source files
+// of the Modules that are not source files of any of the Modules.
+//
+// Imports between Modules of the workspace are treated as internal.
+func WorkspaceExternalImports(ctx context.Context, modules ...Module) ([]string, error) {
+	return workspaceExternalImports(ctx, modules...)
+}
+
+// FieldRef is a reference to a field declared in a source file of a Module.
+type FieldRef struct {
+	// Name is the fully-qualified name of the field, such as "foo.v1.Bar.baz_qux".
+	Name string
+	// FileInfo is the file that declares the field.
+	FileInfo FileInfo
+}
+
+// FieldsMissingJSONName returns a FieldRef for every message field declared in the source
+// files of the Module that requires an explicit json_name option but does not set one.
+//
+// A field requires an explicit json_name if its default JSON name, as computed by protoc,
+// differs from its name, such as "baz_qux" whose default JSON name is "bazQux". Extensions
+// and the synthetic map entry messages are not checked. The returned FieldRefs are sorted
+// by file path, and then by declaration order.
+func FieldsMissingJSONName(ctx context.Context, module Module) ([]FieldRef, error) {
+	return fieldsMissingJSONName(ctx, module)
+}
+
+// UnusedImports returns a map from the path of each source file of the Module to the
+// sorted imports it declares but does not use.
+//
+// An import is used if a message, enum, or extension it declares is referenced by the
+// file, either as a type or as a custom option. The types declared by the files the
+// import publicly imports count as its own. Only imports of source files of the Module
+// and of the Well-Known Types are checked, as the contents of dependencies are not
+// available. Public imports are never reported. Files with no unused imports are not
+// included.
+func UnusedImports(ctx context.Context, module Module) (map[string][]string, error) {
+	return unusedImports(ctx, module)
+}
+
+// MapField is a map field declared in a source file of a Module.
+type MapField struct {
+	// Message is the fully-qualified name of the message that contains the field,
+	// without a leading dot.
+	Message string
+	// Name is the name of the field.
+	Name string
+	// KeyType is the type of the keys, such as "string" or "int64".
+	KeyType string
+	// ValueType is the type of the values, such as "int32" or "foo.Bar".
+	//
+	// Source files are not linked, so message and enum types are as written in
+	// the source file, and may be relative to the scope they are used in.
+	ValueType string
+	// FileInfo is the file that declares the field.
+	FileInfo FileInfo
+}
+
+// ModuleMapFields returns a MapField for every map field declared in the source files
+// of the Module, including map fields of nested messages.
+//
+// The returned MapFields are sorted by file path, and then by declaration order.
+func ModuleMapFields(ctx context.Context, module Module) ([]MapField, error) {
+	return moduleMapFields(ctx, module)
+}
+
+// DuplicateField is a field number used by more than one field of a message declared
+// in a source file of a Module.
+type DuplicateField struct {
+	// Message is the fully-qualified name of the message that contains the fields,
+	// without a leading dot.
+	Message string
+	// Number is the field number.
+	Number int32
+	// FieldNames are the names of the fields with the number, in declaration order.
+	FieldNames []string
+	// FileInfo is the file that declares the message.
+	FileInfo FileInfo
+}
+
+// DetectDuplicateFieldNumbers returns a DuplicateField for every field number that is
+// used by more than one field of a message declared in the source files of the Module,
+// including fields within oneofs and fields of nested messages.
+//
+// The returned DuplicateFields are sorted by file path, and then by the declaration order
+// of the first field with the number.
+func DetectDuplicateFieldNumbers(ctx context.Context, module Module) ([]DuplicateField, error) {
+	return detectDuplicateFieldNumbers(ctx, module)
+}
+
+// MessageRef is a reference to a message declared in a source file of a Module.
+type MessageRef struct {
+	// Name is the fully-qualified name of the message, without a leading dot.
+	Name string
+	// FileInfo is the file that declares the message.
+	FileInfo FileInfo
+}
+
+// EmptyMessages returns a MessageRef for every message declared in the source files
+// of the Module that has no fields, including nested messages.
+//
+// Messages without fields have no oneofs or map fields either. Empty messages may be
+// intentional, such as the request of an RPC without parameters, so callers decide
+// how to treat them. The returned MessageRefs are sorted by file path, and then by
+// declaration order.
+func EmptyMessages(ctx context.Context, module Module) ([]MessageRef, error) {
+	return emptyMessages(ctx, module)
+}
+
+// ChangeSet is the set of changes between two versions of a Module.
+//
+// All slices are sorted. ChangeSet is JSON-serializable.
+type ChangeSet struct {
+	// AddedFiles are the paths of the source files only in the new Module.
+	AddedFiles []string `json:"added_files,omitempty"`
+	// RemovedFiles are the paths of the source files only in the old Module.
+	RemovedFiles []string `json:"removed_files,omitempty"`
+	// ModifiedFiles are the paths of the source files in both Modules with different contents.
+	ModifiedFiles []string `json:"modified_files,omitempty"`
+	// AddedSymbols are the fully-qualified names of the messages, enums, and services
+	// only declared in the new Module.
+	AddedSymbols []string `json:"added_symbols,omitempty"`
+	// RemovedSymbols are the fully-qualified names of the messages, enums, and services
+	// only declared in the old Module.
+	RemovedSymbols []string `json:"removed_symbols,omitempty"`
+	// DependencyChanges are the changes to the dependency ModulePins, sorted by identity.
+	DependencyChanges []DependencyChange `json:"dependency_changes,omitempty"`
+}
+
+// DependencyChange is a change to a dependency ModulePin between two versions of a Module.
+type DependencyChange struct {
+	// Identity is the identity string of the dependency, i.e. remote/owner/repository.
+	Identity string `json:"identity"`
+	// OldCommit is the commit in the old Module, and is empty if the dependency was added.
+	OldCommit string `json:"old_commit,omitempty"`
+	// NewCommit is the commit in the new Module, and is empty if the dependency was removed.
+	NewCommit string `json:"new_commit,omitempty"`
+}
+
+// ModuleChangeSet returns the changes from the old Module to the new Module.
+//
+// Files are compared by path and contents, symbols by fully-qualified name, and
+// dependencies by identity and commit. A ChangeSet with no changes has all fields empty.
+func ModuleChangeSet(ctx context.Context, oldModule Module, newModule Module) (*ChangeSet, error) {
+	return moduleChangeSet(ctx, oldModule, newModule)
+}
+
+// ModuleDiff is the set of source files that changed between two versions of a Module.
+//
+// All slices are sorted.
+type ModuleDiff struct {
+	// AddedPaths are the paths of the source files only in the new Module.
+	AddedPaths []string
+	// RemovedPaths are the paths of the source files only in the old Module.
+	RemovedPaths []string
+	// ModifiedPaths are the paths of the source files in both Modules with different contents.
+	ModifiedPaths []string
+}
+
+// DiffModules returns the source files that changed from the old Module to the new Module.
+//
+// Files are compared by path and by the digest of their contents. Unlike ModuleChangeSet,
+// the files are not parsed, so this can be used for Modules with files that do not compile,
+// such as to decide which files to recompile.
+func DiffModules(ctx context.Context, oldModule Module, newModule Module) (*ModuleDiff, error) {
+	return diffModules(ctx, oldModule, newModule)
+}
+
+// GetModuleFiles gets the ModuleFiles for the given paths from the Module.
+//
+// The ModuleFiles are fetched in parallel, with at most concurrency fetches at once.
+// If concurrency is less than 1, thread.Parallelism() is used. The returned ModuleFiles
+// are in the same order as the paths, and must be closed by the caller.
+//
+// If any fetch fails, the remaining fetches are cancelled, every ModuleFile that
+// was already fetched is closed, and the first error is returned.
+func GetModuleFiles(ctx context.Context, module Module, paths []string, concurrency int) ([]ModuleFile, error) {
+	return getModuleFiles(ctx, module, paths, concurrency)
+}
@@ -50,6 +50,21 @@ func WithWorkspace(workspace bufmodule.Workspace) BuildModuleFileSetOption {
 	}
 }
 
+// WithDependencyImportAliases returns a new BuildModuleFileSetOption that rewrites the paths
+// of the dependencies so that their files can be imported with an alias prefix.
+//
+// This is a map from the identity string of a dependency, to the prefix that imports use,
+// to the prefix of the files within the dependency, as parsed from the dep_import_aliases of
+// a configuration file. The longest matching target prefix is used for each file. Dependencies
+// provided by the workspace are not rewritten.
+//
+// The default is to not rewrite any paths.
+func WithDependencyImportAliases(dependencyImportAliases map[string]map[string]string) BuildModuleFileSetOption {
+	return func(buildModuleFileSetOptions *buildModuleFileSetOptions) {
+		buildModuleFileSetOptions.dependencyImportAliases = dependencyImportAliases
+	}
+}
+
 // ModuleBucketBuilder builds modules for buckets.
 type ModuleBucketBuilder interface {
 	// BuildForBucket builds a module for the given bucket.
@@ -160,8 +175,31 @@ type Config struct {
 	// The excludes in this map will be relative to the root they map to!
 	//
 	// If RootToExcludes is empty, the default is "." with no excludes.
-	RootToExcludes             map[string][]string
+	RootToExcludes map[string][]string
+	// DependencyModuleReferences are the references of the deps.
+	//
+	// A dep may have a version constraint as its reference, such as
+	// buf.build/acme/payments:>=v1.2.0 <v2.0.0, in which case its Constraint is set.
+	// Use bufmodule.ResolveModuleReference to select the version to use.
 	DependencyModuleReferences []bufmodule.ModuleReference
+	// PathRules contains a map from directory prefix to the filename pattern
+	// that all files under that prefix must match.
+	//
+	// Prefixes are relative to the roots and will be normalized and validated.
+	// Patterns use the syntax of path.Match and are matched against the base
+	// name of each file.
+	//
+	// If a file is contained within multiple prefixes, it must match the pattern
+	// of every prefix. Files that do not match are reported as an error when
+	// building a module with a ModuleBucketBuilder.
+	PathRules map[string]string
+	// ExcludeGlobs are glob patterns of the .proto files to exclude from the module.
+	//
+	// Patterns are relative to the module root and use the syntax of normalpath.MatchGlob,
+	// for example "**/vendor/**". All patterns are validated and unique.
+	//
+	// This is only set for v1.
+	ExcludeGlobs []string
 }
 
 // NewConfigV1Beta1 returns a new, validated Config for the ExternalConfig.
@@ -176,11 +214,14 @@ func NewConfigV1(externalConfig ExternalConfigV1, deps ...string) (*Config, erro
 
 // ExternalConfigV1Beta1 is an external config.
 type ExternalConfigV1Beta1 struct {
-	Roots    []string `json:"roots,omitempty" yaml:"roots,omitempty"`
-	Excludes []string `json:"excludes,omitempty" yaml:"excludes,omitempty"`
+	Roots    []string `json:"roots,omitempty" yaml:"roots,omitempty" toml:"roots,omitempty"`
+	Excludes []string `json:"excludes,omitempty" yaml:"excludes,omitempty" toml:"excludes,omitempty"`
 }
 
 // ExternalConfigV1 is an external config.
 type ExternalConfigV1 struct {
-	Excludes []string `json:"excludes,omitempty" yaml:"excludes,omitempty"`
+	Excludes  []string          `json:"excludes,omitempty" yaml:"excludes,omitempty" toml:"excludes,omitempty"`
+	PathRules map[string]string `json:"path_rules,omitempty" yaml:"path_rules,omitempty" toml:"path_rules,omitempty"`
+	// Exclude are glob patterns of the files to exclude, relative to the module root.
+	Exclude []string `json:"exclude,omitempty" yaml:"exclude,omitempty" toml:"exclude,omitempty"`
 }
@@ -16,6 +16,7 @@ package bufmodulebuild
 
 import (
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
@@ -134,12 +135,70 @@ func newConfigV1(externalConfig ExternalConfigV1, deps ...string) (*Config, erro
 	rootToExcludes := map[string][]string{
 		".": excludes, // all excludes are relative to the root
 	}
+	pathRules, err := normalizeAndCheckPathRules(externalConfig.PathRules)
+	if err != nil {
+		return nil, err
+	}
+	excludeGlobs, err := checkExcludeGlobs(externalConfig.Exclude)
+	if err != nil {
+		return nil, err
+	}
 	return &Config{
 		RootToExcludes:             rootToExcludes,
 		DependencyModuleReferences: dependencyModuleReferences,
+		PathRules:                  pathRules,
+		ExcludeGlobs:               excludeGlobs,
 	}, nil
 }
 
+func checkExcludeGlobs(externalExcludeGlobs []string) ([]string, error) {
+	if len(externalExcludeGlobs) == 0 {
+		return nil, nil
+	}
+	excludeGlobs := make([]string, 0, len(externalExcludeGlobs))
+	seen := make(map[string]struct{}, len(externalExcludeGlobs))
+	for _, excludeGlob := range externalExcludeGlobs {
+		excludeGlob = strings.TrimSpace(excludeGlob)
+		if err := normalpath.ValidateGlob(excludeGlob); err != nil {
+			return nil, fmt.Errorf("invalid exclude: %w", err)
+		}
+		if _, ok := seen[excludeGlob]; ok {
+			return nil, fmt.Errorf("duplicate exclude %q", excludeGlob)
+		}
+		seen[excludeGlob] = struct{}{}
+		excludeGlobs = append(excludeGlobs, excludeGlob)
+	}
+	return excludeGlobs, nil
+}
+
+func normalizeAndCheckPathRules(externalPathRules map[string]string) (map[string]string, error) {
+	if len(externalPathRules) == 0 {
+		return nil, nil
+	}
+	pathRules := make(map[string]string, len(externalPathRules))
+	for prefix, pattern := range externalPathRules {
+		normalizedPrefix, err := normalpath.NormalizeAndValidate(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("path rule prefix %q: %w", prefix, err)
+		}
+		if _, ok := pathRules[normalizedPrefix]; ok {
+			return nil, fmt.Errorf("duplicate path rule prefix %q", normalizedPrefix)
+		}
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			return nil, fmt.Errorf("path rule for prefix %q has an empty pattern", normalizedPrefix)
+		}
+		if strings.Contains(pattern, "/") {
+			return nil, fmt.Errorf("path rule pattern %q for prefix %q must match a filename and cannot contain \"/\"", pattern, normalizedPrefix)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("path rule pattern %q for prefix %q is invalid: %w", pattern, normalizedPrefix, err)
+		}
+		pathRules[normalizedPrefix] = pattern
+	}
+	return pathRules, nil
+}
+
 func parseDependencyModuleReferences(deps ...string) ([]bufmodule.ModuleReference, error) {
 	if len(deps) == 0 {
 		return nil, nil
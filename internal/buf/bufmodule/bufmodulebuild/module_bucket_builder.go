@@ -118,10 +118,15 @@ func (b *moduleBucketBuilder) buildForBucket(
 		ctx,
 		storage.MultiReadBucket(rootBuckets...),
 		bufmodule.ModuleWithModuleIdentity(moduleIdentity /* This may be nil */),
+		// v1 has a single root at ".", so the root-relative paths are relative to the module root
+		bufmodule.ModuleWithExcludeGlobs(config.ExcludeGlobs),
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkPathRules(ctx, module, config.PathRules); err != nil {
+		return nil, err
+	}
 	return applyModulePaths(
 		module,
 		roots,
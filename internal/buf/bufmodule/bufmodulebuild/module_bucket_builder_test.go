@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
 	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -443,3 +444,92 @@ func testDocumentationBucket(
 		fileInfos,
 	)
 }
+
+func TestBucketPathRules(t *testing.T) {
+	t.Parallel()
+	config, err := NewConfigV1(
+		ExternalConfigV1{
+			PathRules: map[string]string{
+				"proto/a": "*_service.proto",
+			},
+		},
+	)
+	require.NoError(t, err)
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"proto/a/foo_service.proto": []byte(`syntax = "proto3";`),
+			"proto/a/c/bar.proto":       []byte(`syntax = "proto3";`),
+			"proto/b/baz.proto":         []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	_, err = NewModuleBucketBuilder(zap.NewNop()).BuildForBucket(
+		context.Background(),
+		readBucket,
+		config,
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `proto/a/c/bar.proto: file does not match pattern "*_service.proto" required for files in "proto/a"`)
+	assert.NotContains(t, err.Error(), "foo_service.proto")
+	assert.NotContains(t, err.Error(), "baz.proto")
+}
+
+func TestBucketPathRulesInvalidPattern(t *testing.T) {
+	t.Parallel()
+	_, err := NewConfigV1(
+		ExternalConfigV1{
+			PathRules: map[string]string{
+				"proto/a": "[",
+			},
+		},
+	)
+	require.Error(t, err)
+}
+
+func TestBucketExcludeGlobs(t *testing.T) {
+	t.Parallel()
+	config, err := NewConfigV1(
+		ExternalConfigV1{
+			Exclude: []string{
+				"**/vendor/**",
+				"proto/*_gen.proto",
+			},
+		},
+	)
+	require.NoError(t, err)
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"proto/a.proto":              []byte(`syntax = "proto3";`),
+			"proto/a_gen.proto":          []byte(`syntax = "proto3";`),
+			"proto/b/b_gen.proto":        []byte(`syntax = "proto3";`),
+			"proto/vendor/c.proto":       []byte(`syntax = "proto3";`),
+			"third_party/vendor/d.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	module, err := NewModuleBucketBuilder(zap.NewNop()).BuildForBucket(
+		context.Background(),
+		readBucket,
+		config,
+	)
+	require.NoError(t, err)
+	fileInfos, err := module.SourceFileInfos(context.Background())
+	require.NoError(t, err)
+	paths := make([]string, len(fileInfos))
+	for i, fileInfo := range fileInfos {
+		paths[i] = fileInfo.Path()
+	}
+	assert.Equal(t, []string{"proto/a.proto", "proto/b/b_gen.proto"}, paths)
+}
+
+func TestBucketExcludeGlobsInvalid(t *testing.T) {
+	t.Parallel()
+	for _, exclude := range []string{"", "/abs/**", "../a/*.proto", "a/**.proto", "[", "a/*.proto\n"} {
+		_, err := NewConfigV1(
+			ExternalConfigV1{
+				Exclude: []string{exclude, "a/*.proto"},
+			},
+		)
+		require.Error(t, err, "exclude %q", exclude)
+	}
+}
@@ -16,8 +16,10 @@ package bufmodulebuild
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"go.uber.org/zap"
 )
 
@@ -48,6 +50,7 @@ func (m *moduleFileSetBuilder) Build(
 		ctx,
 		module,
 		buildModuleFileSetOptions.workspace,
+		buildModuleFileSetOptions.dependencyImportAliases,
 	)
 }
 
@@ -55,6 +58,7 @@ func (m *moduleFileSetBuilder) build(
 	ctx context.Context,
 	module bufmodule.Module,
 	workspace bufmodule.Workspace,
+	dependencyImportAliases map[string]map[string]string,
 ) (bufmodule.ModuleFileSet, error) {
 	var dependencyModules []bufmodule.Module
 	if workspace != nil {
@@ -83,7 +87,49 @@ func (m *moduleFileSetBuilder) build(
 		if err != nil {
 			return nil, err
 		}
+		if aliases, ok := dependencyImportAliases[dependencyModulePin.IdentityString()]; ok {
+			dependencyModule, err = aliasDependencyModule(ctx, dependencyModule, aliases)
+			if err != nil {
+				return nil, fmt.Errorf("dep_import_aliases for %q: %w", dependencyModulePin.IdentityString(), err)
+			}
+		}
 		dependencyModules = append(dependencyModules, dependencyModule)
 	}
 	return bufmodule.NewModuleFileSet(module, dependencyModules), nil
 }
+
+// aliasDependencyModule returns a new Module with the files of the dependency within
+// each target prefix moved to the corresponding alias prefix.
+//
+// aliases is a map from alias prefix to target prefix.
+func aliasDependencyModule(
+	ctx context.Context,
+	dependencyModule bufmodule.Module,
+	aliases map[string]string,
+) (bufmodule.Module, error) {
+	return bufmodule.RemapModulePaths(
+		ctx,
+		dependencyModule,
+		func(path string) (string, error) {
+			var matchingAliasPrefix string
+			var matchingTargetPrefix string
+			for aliasPrefix, targetPrefix := range aliases {
+				if !normalpath.EqualsOrContainsPath(targetPrefix, path, normalpath.Relative) {
+					continue
+				}
+				if matchingTargetPrefix == "" || len(targetPrefix) > len(matchingTargetPrefix) {
+					matchingAliasPrefix = aliasPrefix
+					matchingTargetPrefix = targetPrefix
+				}
+			}
+			if matchingTargetPrefix == "" {
+				return path, nil
+			}
+			relPath, err := normalpath.Rel(matchingTargetPrefix, path)
+			if err != nil {
+				return "", err
+			}
+			return normalpath.Join(matchingAliasPrefix, relPath), nil
+		},
+	)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodulebuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestModuleFileSetBuilderDependencyImportAliases(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"acme",
+		"weather",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a/a.proto",
+					Content: []byte(`syntax = "proto3"; package a; import "vendor/weather/v1/weather.proto";`),
+				},
+			},
+			Dependencies: bufmodule.NewProtoModulePinsForModulePins(modulePin),
+		},
+	)
+	require.NoError(t, err)
+	dependencyModule, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "weather/v1/weather.proto",
+					Content: []byte(`syntax = "proto3"; package weather.v1;`),
+				},
+				{
+					Path:    "other/other.proto",
+					Content: []byte(`syntax = "proto3"; package other;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	moduleFileSet, err := NewModuleFileSetBuilder(
+		zap.NewNop(),
+		&testModuleReader{module: dependencyModule},
+	).Build(
+		ctx,
+		module,
+		WithDependencyImportAliases(
+			map[string]map[string]string{
+				"buf.build/acme/weather": {
+					"vendor/weather": "weather",
+				},
+			},
+		),
+	)
+	require.NoError(t, err)
+	moduleFile, err := moduleFileSet.GetModuleFile(ctx, "vendor/weather/v1/weather.proto")
+	require.NoError(t, err)
+	require.NoError(t, moduleFile.Close())
+	_, err = moduleFileSet.GetModuleFile(ctx, "weather/v1/weather.proto")
+	assert.True(t, storage.IsNotExist(err))
+	moduleFile, err = moduleFileSet.GetModuleFile(ctx, "other/other.proto")
+	require.NoError(t, err)
+	require.NoError(t, moduleFile.Close())
+}
+
+type testModuleReader struct {
+	module bufmodule.Module
+}
+
+func (r *testModuleReader) GetModule(context.Context, bufmodule.ModulePin) (bufmodule.Module, error) {
+	return r.module, nil
+}
@@ -15,9 +15,12 @@
 package bufmodulebuild
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path"
 	"sort"
+	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
@@ -163,5 +166,49 @@ type buildOptions struct {
 }
 
 type buildModuleFileSetOptions struct {
-	workspace bufmodule.Workspace
+	workspace               bufmodule.Workspace
+	dependencyImportAliases map[string]map[string]string
+}
+
+// checkPathRules returns an error listing every source file of the module
+// that is contained within a path rule prefix but whose base name does not
+// match the pattern for that prefix.
+func checkPathRules(ctx context.Context, module bufmodule.Module, pathRules map[string]string) error {
+	if len(pathRules) == 0 {
+		return nil
+	}
+	prefixes := make([]string, 0, len(pathRules))
+	for prefix := range pathRules {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	fileInfos, err := module.SourceFileInfos(ctx)
+	if err != nil {
+		return err
+	}
+	var violations []string
+	for _, fileInfo := range fileInfos {
+		filePath := fileInfo.Path()
+		for _, prefix := range prefixes {
+			if !normalpath.ContainsPath(prefix, filePath, normalpath.Relative) {
+				continue
+			}
+			pattern := pathRules[prefix]
+			matched, err := path.Match(pattern, normalpath.Base(filePath))
+			if err != nil {
+				// this should never happen as patterns are validated on config creation
+				return err
+			}
+			if !matched {
+				violations = append(
+					violations,
+					fmt.Sprintf("%s: file does not match pattern %q required for files in %q", filePath, pattern, prefix),
+				)
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("files do not conform to path_rules:\n%s", strings.Join(violations, "\n"))
+	}
+	return nil
 }
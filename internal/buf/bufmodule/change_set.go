@@ -0,0 +1,139 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+func moduleChangeSet(ctx context.Context, oldModule Module, newModule Module) (*ChangeSet, error) {
+	changeSet := &ChangeSet{}
+	if err := addFileChanges(ctx, changeSet, oldModule, newModule); err != nil {
+		return nil, err
+	}
+	if err := addSymbolChanges(ctx, changeSet, oldModule, newModule); err != nil {
+		return nil, err
+	}
+	changeSet.DependencyChanges = getDependencyChanges(oldModule.DependencyModulePins(), newModule.DependencyModulePins())
+	return changeSet, nil
+}
+
+func addFileChanges(ctx context.Context, changeSet *ChangeSet, oldModule Module, newModule Module) error {
+	moduleDiff, err := diffModules(ctx, oldModule, newModule)
+	if err != nil {
+		return err
+	}
+	changeSet.AddedFiles = moduleDiff.AddedPaths
+	changeSet.RemovedFiles = moduleDiff.RemovedPaths
+	changeSet.ModifiedFiles = moduleDiff.ModifiedPaths
+	return nil
+}
+
+func addSymbolChanges(ctx context.Context, changeSet *ChangeSet, oldModule Module, newModule Module) error {
+	oldSymbols, err := getModuleSymbolSet(ctx, oldModule)
+	if err != nil {
+		return err
+	}
+	newSymbols, err := getModuleSymbolSet(ctx, newModule)
+	if err != nil {
+		return err
+	}
+	for _, symbol := range stringutil.MapToSortedSlice(newSymbols) {
+		if _, ok := oldSymbols[symbol]; !ok {
+			changeSet.AddedSymbols = append(changeSet.AddedSymbols, symbol)
+		}
+	}
+	for _, symbol := range stringutil.MapToSortedSlice(oldSymbols) {
+		if _, ok := newSymbols[symbol]; !ok {
+			changeSet.RemovedSymbols = append(changeSet.RemovedSymbols, symbol)
+		}
+	}
+	return nil
+}
+
+func getDependencyChanges(oldModulePins []ModulePin, newModulePins []ModulePin) []DependencyChange {
+	identityToOldCommit := make(map[string]string, len(oldModulePins))
+	for _, oldModulePin := range oldModulePins {
+		identityToOldCommit[oldModulePin.IdentityString()] = oldModulePin.Commit()
+	}
+	identityToNewCommit := make(map[string]string, len(newModulePins))
+	for _, newModulePin := range newModulePins {
+		identityToNewCommit[newModulePin.IdentityString()] = newModulePin.Commit()
+	}
+	var dependencyChanges []DependencyChange
+	for identity, newCommit := range identityToNewCommit {
+		oldCommit, ok := identityToOldCommit[identity]
+		if ok && oldCommit == newCommit {
+			continue
+		}
+		dependencyChanges = append(
+			dependencyChanges,
+			DependencyChange{
+				Identity:  identity,
+				OldCommit: oldCommit,
+				NewCommit: newCommit,
+			},
+		)
+	}
+	for identity, oldCommit := range identityToOldCommit {
+		if _, ok := identityToNewCommit[identity]; !ok {
+			dependencyChanges = append(
+				dependencyChanges,
+				DependencyChange{
+					Identity:  identity,
+					OldCommit: oldCommit,
+				},
+			)
+		}
+	}
+	sort.Slice(
+		dependencyChanges,
+		func(i int, j int) bool {
+			return dependencyChanges[i].Identity < dependencyChanges[j].Identity
+		},
+	)
+	return dependencyChanges
+}
+
+func getSourceFilePathSet(ctx context.Context, module Module) (map[string]struct{}, error) {
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := make(map[string]struct{}, len(sourceFileInfos))
+	for _, sourceFileInfo := range sourceFileInfos {
+		paths[sourceFileInfo.Path()] = struct{}{}
+	}
+	return paths, nil
+}
+
+// getModuleSymbolSet returns the fully-qualified names of the messages, enums,
+// and services declared in the source files of the Module.
+func getModuleSymbolSet(ctx context.Context, module Module) (map[string]struct{}, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string]struct{})
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachSymbol(parsedSourceFile.fileDescriptorProto, func(fullName string) {
+			symbols[fullName] = struct{}{}
+		})
+	}
+	return symbols, nil
+}
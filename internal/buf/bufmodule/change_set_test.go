@@ -0,0 +1,131 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleChangeSet(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	oldModule, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a.proto",
+					Content: []byte(`syntax = "proto3"; package a; message Foo {} enum Old { OLD_UNSPECIFIED = 0; }`),
+				},
+				{
+					Path:    "b.proto",
+					Content: []byte(`syntax = "proto3"; package b; message Bar {}`),
+				},
+				{
+					Path:    "removed.proto",
+					Content: []byte(`syntax = "proto3"; package removed; service RemovedService {}`),
+				},
+			},
+			Dependencies: []*modulev1alpha1.ModulePin{
+				testNewProtoModulePin(t, "changed", bufmoduletesting.TestCommit),
+				testNewProtoModulePin(t, "removed", bufmoduletesting.TestCommit),
+				testNewProtoModulePin(t, "same", bufmoduletesting.TestCommit),
+			},
+		},
+	)
+	require.NoError(t, err)
+	newModule, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a.proto",
+					Content: []byte(`syntax = "proto3"; package a; message Foo { message Nested {} }`),
+				},
+				{
+					Path:    "added.proto",
+					Content: []byte(`syntax = "proto3"; package added; service AddedService {}`),
+				},
+				{
+					Path:    "b.proto",
+					Content: []byte(`syntax = "proto3"; package b; message Bar {}`),
+				},
+			},
+			Dependencies: []*modulev1alpha1.ModulePin{
+				testNewProtoModulePin(t, "added", bufmoduletesting.TestCommit),
+				testNewProtoModulePin(t, "changed", "ffffffffffffffffffffffffffffffff"),
+				testNewProtoModulePin(t, "same", bufmoduletesting.TestCommit),
+			},
+		},
+	)
+	require.NoError(t, err)
+	changeSet, err := bufmodule.ModuleChangeSet(ctx, oldModule, newModule)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		&bufmodule.ChangeSet{
+			AddedFiles:     []string{"added.proto"},
+			RemovedFiles:   []string{"removed.proto"},
+			ModifiedFiles:  []string{"a.proto"},
+			AddedSymbols:   []string{"a.Foo.Nested", "added.AddedService"},
+			RemovedSymbols: []string{"a.Old", "removed.RemovedService"},
+			DependencyChanges: []bufmodule.DependencyChange{
+				{
+					Identity:  "buf.build/foob/added",
+					NewCommit: bufmoduletesting.TestCommit,
+				},
+				{
+					Identity:  "buf.build/foob/changed",
+					OldCommit: bufmoduletesting.TestCommit,
+					NewCommit: "ffffffffffffffffffffffffffffffff",
+				},
+				{
+					Identity:  "buf.build/foob/removed",
+					OldCommit: bufmoduletesting.TestCommit,
+				},
+			},
+		},
+		changeSet,
+	)
+	data, err := json.Marshal(changeSet)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"modified_files":["a.proto"]`)
+	changeSet, err = bufmodule.ModuleChangeSet(ctx, oldModule, oldModule)
+	require.NoError(t, err)
+	assert.Equal(t, &bufmodule.ChangeSet{}, changeSet)
+}
+
+func testNewProtoModulePin(t *testing.T, repository string, commit string) *modulev1alpha1.ModulePin {
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		repository,
+		"main",
+		commit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	return bufmodule.NewProtoModulePinForModulePin(modulePin)
+}
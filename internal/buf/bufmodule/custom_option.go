@@ -0,0 +1,118 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func customOptionsReferenced(ctx context.Context, module Module) ([]CustomOptionRef, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var customOptionRefs []CustomOptionRef
+	for _, parsedSourceFile := range parsedSourceFiles {
+		names := make(map[string]struct{})
+		for _, uninterpretedOption := range getFileUninterpretedOptions(parsedSourceFile.fileDescriptorProto) {
+			if name, ok := customOptionName(uninterpretedOption); ok {
+				names[name] = struct{}{}
+			}
+		}
+		sortedNames := make([]string, 0, len(names))
+		for name := range names {
+			sortedNames = append(sortedNames, name)
+		}
+		sort.Strings(sortedNames)
+		for _, name := range sortedNames {
+			customOptionRefs = append(
+				customOptionRefs,
+				CustomOptionRef{
+					Name:     name,
+					FileInfo: parsedSourceFile.fileInfo,
+				},
+			)
+		}
+	}
+	return customOptionRefs, nil
+}
+
+// customOptionName returns the name of the option as written in the source file,
+// such as "(foo.bar).baz", and false if the option is not a custom option.
+func customOptionName(uninterpretedOption *descriptorpb.UninterpretedOption) (string, bool) {
+	isCustom := false
+	nameParts := make([]string, 0, len(uninterpretedOption.GetName()))
+	for _, namePart := range uninterpretedOption.GetName() {
+		if namePart.GetIsExtension() {
+			isCustom = true
+			nameParts = append(nameParts, "("+strings.TrimPrefix(namePart.GetNamePart(), ".")+")")
+		} else {
+			nameParts = append(nameParts, namePart.GetNamePart())
+		}
+	}
+	return strings.Join(nameParts, "."), isCustom
+}
+
+// getFileUninterpretedOptions returns the uninterpreted options of every element within the file.
+//
+// Files are parsed without linking, so all custom options remain uninterpreted.
+func getFileUninterpretedOptions(fileDescriptorProto *descriptorpb.FileDescriptorProto) []*descriptorpb.UninterpretedOption {
+	var uninterpretedOptions []*descriptorpb.UninterpretedOption
+	uninterpretedOptions = append(uninterpretedOptions, fileDescriptorProto.GetOptions().GetUninterpretedOption()...)
+	uninterpretedOptions = append(uninterpretedOptions, getFieldsUninterpretedOptions(fileDescriptorProto.GetExtension())...)
+	uninterpretedOptions = append(uninterpretedOptions, getEnumsUninterpretedOptions(fileDescriptorProto.GetEnumType())...)
+	for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+		uninterpretedOptions = append(uninterpretedOptions, serviceDescriptorProto.GetOptions().GetUninterpretedOption()...)
+		for _, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+			uninterpretedOptions = append(uninterpretedOptions, methodDescriptorProto.GetOptions().GetUninterpretedOption()...)
+		}
+	}
+	forEachMessage(fileDescriptorProto, func(_ string, descriptorProto *descriptorpb.DescriptorProto) {
+		uninterpretedOptions = append(uninterpretedOptions, descriptorProto.GetOptions().GetUninterpretedOption()...)
+		uninterpretedOptions = append(uninterpretedOptions, getFieldsUninterpretedOptions(descriptorProto.GetField())...)
+		uninterpretedOptions = append(uninterpretedOptions, getFieldsUninterpretedOptions(descriptorProto.GetExtension())...)
+		uninterpretedOptions = append(uninterpretedOptions, getEnumsUninterpretedOptions(descriptorProto.GetEnumType())...)
+		for _, oneofDescriptorProto := range descriptorProto.GetOneofDecl() {
+			uninterpretedOptions = append(uninterpretedOptions, oneofDescriptorProto.GetOptions().GetUninterpretedOption()...)
+		}
+		for _, extensionRange := range descriptorProto.GetExtensionRange() {
+			uninterpretedOptions = append(uninterpretedOptions, extensionRange.GetOptions().GetUninterpretedOption()...)
+		}
+	})
+	return uninterpretedOptions
+}
+
+func getFieldsUninterpretedOptions(fieldDescriptorProtos []*descriptorpb.FieldDescriptorProto) []*descriptorpb.UninterpretedOption {
+	var uninterpretedOptions []*descriptorpb.UninterpretedOption
+	for _, fieldDescriptorProto := range fieldDescriptorProtos {
+		uninterpretedOptions = append(uninterpretedOptions, fieldDescriptorProto.GetOptions().GetUninterpretedOption()...)
+	}
+	return uninterpretedOptions
+}
+
+func getEnumsUninterpretedOptions(enumDescriptorProtos []*descriptorpb.EnumDescriptorProto) []*descriptorpb.UninterpretedOption {
+	var uninterpretedOptions []*descriptorpb.UninterpretedOption
+	for _, enumDescriptorProto := range enumDescriptorProtos {
+		uninterpretedOptions = append(uninterpretedOptions, enumDescriptorProto.GetOptions().GetUninterpretedOption()...)
+		for _, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
+			uninterpretedOptions = append(uninterpretedOptions, enumValueDescriptorProto.GetOptions().GetUninterpretedOption()...)
+		}
+	}
+	return uninterpretedOptions
+}
@@ -0,0 +1,61 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomOptionsReferenced(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+import "acme/options.proto";
+option java_package = "com.a";
+message Foo {
+  option (acme.message_policy).owner = "team";
+  string bar = 1 [(acme.sensitive) = true, deprecated = true];
+  string baz = 2 [(acme.sensitive) = true];
+}`),
+				},
+				{
+					Path:    "b/b.proto",
+					Content: []byte(`syntax = "proto3"; package b; message Bar {}`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	customOptionRefs, err := bufmodule.CustomOptionsReferenced(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, customOptionRefs, 2)
+	assert.Equal(t, "(acme.message_policy).owner", customOptionRefs[0].Name)
+	assert.Equal(t, "a/a.proto", customOptionRefs[0].FileInfo.Path())
+	assert.Equal(t, "(acme.sensitive)", customOptionRefs[1].Name)
+	assert.Equal(t, "a/a.proto", customOptionRefs[1].FileInfo.Path())
+}
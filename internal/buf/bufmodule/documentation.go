@@ -0,0 +1,116 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+func validateDocumentationMarkdown(module Module) error {
+	documentation := module.Documentation()
+	if documentation == "" {
+		return nil
+	}
+	var err error
+	var openFence string
+	var openFenceLineNumber int
+	for i, line := range strings.Split(documentation, "\n") {
+		lineNumber := i + 1
+		line = strings.TrimSuffix(line, "\r")
+		if fence := getMarkdownCodeFence(line); fence != "" {
+			if openFence == "" {
+				openFence = fence
+				openFenceLineNumber = lineNumber
+				continue
+			}
+			// a closing fence uses the same character, is at least as long as the
+			// opening fence, and has no info string
+			if fence[0] == openFence[0] && len(fence) >= len(openFence) &&
+				strings.TrimSpace(line) == fence {
+				openFence = ""
+				continue
+			}
+		}
+		if openFence != "" {
+			continue
+		}
+		if column := getUnterminatedMarkdownLinkColumn(line); column > 0 {
+			err = multierr.Append(
+				err,
+				fmt.Errorf("%s:%d:%d: unterminated link", DocumentationFilePath, lineNumber, column),
+			)
+		}
+	}
+	if openFence != "" {
+		err = multierr.Append(
+			err,
+			fmt.Errorf("%s:%d: code fence %q is never closed", DocumentationFilePath, openFenceLineNumber, openFence),
+		)
+	}
+	return err
+}
+
+// getMarkdownCodeFence returns the fence of the line if the line starts a
+// fenced code block, that is three or more backticks or tildes indented by
+// at most three spaces.
+//
+// Returns the empty string if the line is not a code fence.
+func getMarkdownCodeFence(line string) string {
+	trimmedLine := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmedLine) > 3 || trimmedLine == "" {
+		return ""
+	}
+	fenceChar := trimmedLine[0]
+	if fenceChar != '`' && fenceChar != '~' {
+		return ""
+	}
+	fenceLength := 0
+	for fenceLength < len(trimmedLine) && trimmedLine[fenceLength] == fenceChar {
+		fenceLength++
+	}
+	if fenceLength < 3 {
+		return ""
+	}
+	return trimmedLine[:fenceLength]
+}
+
+// getUnterminatedMarkdownLinkColumn returns the 1-based column of the first link
+// destination in the line that is opened with "](" but never closed with ")".
+// Code spans are not considered.
+//
+// Returns 0 if there is no unterminated link.
+func getUnterminatedMarkdownLinkColumn(line string) int {
+	inCodeSpan := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '`':
+			inCodeSpan = !inCodeSpan
+		case inCodeSpan:
+		case line[i] == '\\':
+			// skip the escaped character
+			i++
+		case strings.HasPrefix(line[i:], "]("):
+			end := strings.IndexByte(line[i+2:], ')')
+			if end < 0 {
+				return i + 1
+			}
+			i += 2 + end
+		}
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func detectDuplicateFieldNumbers(ctx context.Context, module Module) ([]DuplicateField, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var duplicateFields []DuplicateField
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachMessage(parsedSourceFile.fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			// fields within oneofs are fields of the containing message
+			var numbers []int32
+			numberToFieldNames := make(map[int32][]string)
+			for _, fieldDescriptorProto := range descriptorProto.GetField() {
+				number := fieldDescriptorProto.GetNumber()
+				if _, ok := numberToFieldNames[number]; !ok {
+					numbers = append(numbers, number)
+				}
+				numberToFieldNames[number] = append(numberToFieldNames[number], fieldDescriptorProto.GetName())
+			}
+			for _, number := range numbers {
+				if fieldNames := numberToFieldNames[number]; len(fieldNames) > 1 {
+					duplicateFields = append(
+						duplicateFields,
+						DuplicateField{
+							Message:    fullName,
+							Number:     number,
+							FieldNames: fieldNames,
+							FileInfo:   parsedSourceFile.fileInfo,
+						},
+					)
+				}
+			}
+		})
+	}
+	return duplicateFields, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDuplicateFieldNumbers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+message Foo {
+  string one = 1;
+  oneof choice {
+    string two = 2;
+    int32 also_one = 1;
+  }
+  message Bar {
+    string three = 3;
+    string four = 3;
+    string five = 5;
+  }
+}
+message Valid {
+  string one = 1;
+  string two = 2;
+}
+`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	duplicateFields, err := bufmodule.DetectDuplicateFieldNumbers(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, duplicateFields, 2)
+	assert.Equal(t, "a.Foo", duplicateFields[0].Message)
+	assert.Equal(t, int32(1), duplicateFields[0].Number)
+	assert.Equal(t, []string{"one", "also_one"}, duplicateFields[0].FieldNames)
+	assert.Equal(t, "a.proto", duplicateFields[0].FileInfo.Path())
+	assert.Equal(t, "a.Foo.Bar", duplicateFields[1].Message)
+	assert.Equal(t, int32(3), duplicateFields[1].Number)
+	assert.Equal(t, []string{"three", "four"}, duplicateFields[1].FieldNames)
+}
@@ -0,0 +1,45 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func emptyMessages(ctx context.Context, module Module) ([]MessageRef, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var messageRefs []MessageRef
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachMessage(parsedSourceFile.fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			// fields within oneofs and map fields are fields of the containing message,
+			// so there are no oneofs or map fields if there are no fields
+			if len(descriptorProto.GetField()) == 0 {
+				messageRefs = append(
+					messageRefs,
+					MessageRef{
+						Name:     fullName,
+						FileInfo: parsedSourceFile.fileInfo,
+					},
+				)
+			}
+		})
+	}
+	return messageRefs, nil
+}
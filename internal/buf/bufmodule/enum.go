@@ -0,0 +1,60 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sort"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func moduleEnums(ctx context.Context, module Module) ([]Enum, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var enums []Enum
+	for _, parsedSourceFile := range parsedSourceFiles {
+		fileInfo := parsedSourceFile.fileInfo
+		forEachEnum(parsedSourceFile.fileDescriptorProto, func(fullName string, enumDescriptorProto *descriptorpb.EnumDescriptorProto) {
+			values := make([]EnumValue, 0, len(enumDescriptorProto.GetValue()))
+			for _, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
+				values = append(
+					values,
+					EnumValue{
+						Name:   enumValueDescriptorProto.GetName(),
+						Number: enumValueDescriptorProto.GetNumber(),
+					},
+				)
+			}
+			enums = append(
+				enums,
+				Enum{
+					FullName: fullName,
+					FileInfo: fileInfo,
+					Values:   values,
+				},
+			)
+		})
+	}
+	sort.Slice(
+		enums,
+		func(i int, j int) bool {
+			return enums[i].FullName < enums[j].FullName
+		},
+	)
+	return enums, nil
+}
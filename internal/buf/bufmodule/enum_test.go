@@ -0,0 +1,75 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleEnums(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+}
+message Foo {
+  enum Kind {
+    KIND_UNSPECIFIED = 0;
+    KIND_BAR = 2;
+  }
+}`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	enums, err := bufmodule.ModuleEnums(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, enums, 2)
+	assert.Equal(t, "a.Foo.Kind", enums[0].FullName)
+	assert.Equal(t, "a/a.proto", enums[0].FileInfo.Path())
+	assert.Equal(
+		t,
+		[]bufmodule.EnumValue{
+			{Name: "KIND_UNSPECIFIED", Number: 0},
+			{Name: "KIND_BAR", Number: 2},
+		},
+		enums[0].Values,
+	)
+	assert.Equal(t, "a.Status", enums[1].FullName)
+	assert.Equal(
+		t,
+		[]bufmodule.EnumValue{
+			{Name: "STATUS_UNSPECIFIED", Number: 0},
+			{Name: "STATUS_ACTIVE", Number: 1},
+		},
+		enums[1].Values,
+	)
+}
@@ -0,0 +1,53 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func moduleExtensions(ctx context.Context, module Module) ([]Extension, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var extensions []Extension
+	for _, parsedSourceFile := range parsedSourceFiles {
+		fileInfo := parsedSourceFile.fileInfo
+		fileDescriptorProto := parsedSourceFile.fileDescriptorProto
+		addExtensions := func(scope string, fieldDescriptorProtos []*descriptorpb.FieldDescriptorProto) {
+			for _, fieldDescriptorProto := range fieldDescriptorProtos {
+				extensions = append(
+					extensions,
+					Extension{
+						FullName: joinSymbolName(scope, fieldDescriptorProto.GetName()),
+						// the files are not linked, so this is the extendee as written
+						Extendee: strings.TrimPrefix(fieldDescriptorProto.GetExtendee(), "."),
+						Number:   fieldDescriptorProto.GetNumber(),
+						FileInfo: fileInfo,
+					},
+				)
+			}
+		}
+		addExtensions(fileDescriptorProto.GetPackage(), fileDescriptorProto.GetExtension())
+		forEachMessage(fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			addExtensions(fullName, descriptorProto.GetExtension())
+		})
+	}
+	return extensions, nil
+}
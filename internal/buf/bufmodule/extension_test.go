@@ -0,0 +1,56 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleExtensions(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto2";
+package a;
+message Foo {
+  extensions 100 to 200;
+}
+extend Foo {
+  optional string bar = 100;
+}`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	extensions, err := bufmodule.ModuleExtensions(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, extensions, 1)
+	assert.Equal(t, "a.bar", extensions[0].FullName)
+	assert.Equal(t, "Foo", extensions[0].Extendee)
+	assert.Equal(t, int32(100), extensions[0].Number)
+	assert.Equal(t, "a/a.proto", extensions[0].FileInfo.Path())
+}
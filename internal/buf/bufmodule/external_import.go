@@ -0,0 +1,53 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sort"
+)
+
+func moduleExternalImports(ctx context.Context, module Module) ([]string, error) {
+	return workspaceExternalImports(ctx, module)
+}
+
+func workspaceExternalImports(ctx context.Context, modules ...Module) ([]string, error) {
+	var parsedSourceFiles []*parsedSourceFile
+	for _, module := range modules {
+		moduleParsedSourceFiles, err := parseSourceFiles(ctx, module)
+		if err != nil {
+			return nil, err
+		}
+		parsedSourceFiles = append(parsedSourceFiles, moduleParsedSourceFiles...)
+	}
+	sourcePaths := make(map[string]struct{}, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		sourcePaths[parsedSourceFile.fileInfo.Path()] = struct{}{}
+	}
+	externalImportMap := make(map[string]struct{})
+	for _, parsedSourceFile := range parsedSourceFiles {
+		for _, importPath := range parsedSourceFile.fileDescriptorProto.GetDependency() {
+			if _, ok := sourcePaths[importPath]; !ok {
+				externalImportMap[importPath] = struct{}{}
+			}
+		}
+	}
+	externalImports := make([]string, 0, len(externalImportMap))
+	for externalImport := range externalImportMap {
+		externalImports = append(externalImports, externalImport)
+	}
+	sort.Strings(externalImports)
+	return externalImports, nil
+}
@@ -26,6 +26,7 @@ type fileInfo struct {
 	isImport       bool
 	moduleIdentity ModuleIdentity
 	commit         string
+	isSymlink      bool
 }
 
 func newFileInfo(
@@ -86,14 +87,20 @@ func (f *fileInfo) Commit() string {
 	return f.commit
 }
 
+func (f *fileInfo) IsSymlink() bool {
+	return f.isSymlink
+}
+
 func (f *fileInfo) WithIsImport(isImport bool) FileInfo {
-	return newFileInfoNoValidate(
+	fileInfo := newFileInfoNoValidate(
 		f.path,
 		f.externalPath,
 		isImport,
 		f.moduleIdentity,
 		f.commit,
 	)
+	fileInfo.isSymlink = f.isSymlink
+	return fileInfo
 }
 
 func (*fileInfo) isFileInfo() {}
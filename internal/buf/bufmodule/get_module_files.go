@@ -0,0 +1,87 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bufbuild/buf/internal/pkg/thread"
+	"go.uber.org/multierr"
+)
+
+func getModuleFiles(ctx context.Context, module Module, paths []string, concurrency int) (_ []ModuleFile, retErr error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	if concurrency < 1 {
+		concurrency = thread.Parallelism()
+	}
+	if concurrency > len(paths) {
+		concurrency = len(paths)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	moduleFiles := make([]ModuleFile, len(paths))
+	defer func() {
+		if retErr != nil {
+			for _, moduleFile := range moduleFiles {
+				if moduleFile != nil {
+					retErr = multierr.Append(retErr, moduleFile.Close())
+				}
+			}
+		}
+	}()
+	indexC := make(chan int, len(paths))
+	for i := range paths {
+		indexC <- i
+	}
+	close(indexC)
+	var firstErr error
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexC {
+				if ctx.Err() != nil {
+					return
+				}
+				// each worker writes to distinct indexes, so no lock is needed for moduleFiles
+				moduleFile, err := module.GetModuleFile(ctx, paths[index])
+				if err != nil {
+					lock.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					lock.Unlock()
+					cancel()
+					return
+				}
+				moduleFiles[index] = moduleFile
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// the parent context may have been cancelled
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return moduleFiles, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetModuleFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pathToData := make(map[string][]byte)
+	var paths []string
+	for i := 0; i < 20; i++ {
+		path := fmt.Sprintf("foo/%d.proto", i)
+		pathToData[path] = []byte(fmt.Sprintf("// %d", i))
+		paths = append(paths, path)
+	}
+	module, err := bufmodule.NewModuleForFiles(ctx, pathToData)
+	require.NoError(t, err)
+	// reverse the order to check that the output follows the input
+	for i, j := 0, len(paths)-1; i < j; i, j = i+1, j-1 {
+		paths[i], paths[j] = paths[j], paths[i]
+	}
+	for _, concurrency := range []int{0, 1, 3, 100} {
+		moduleFiles, err := bufmodule.GetModuleFiles(ctx, module, paths, concurrency)
+		require.NoError(t, err)
+		require.Len(t, moduleFiles, len(paths))
+		for i, moduleFile := range moduleFiles {
+			assert.Equal(t, paths[i], moduleFile.Path())
+			data, err := io.ReadAll(moduleFile)
+			require.NoError(t, err)
+			assert.Equal(t, pathToData[paths[i]], data)
+			assert.NoError(t, moduleFile.Close())
+		}
+	}
+
+	_, err = bufmodule.GetModuleFiles(ctx, module, append(paths, "missing.proto"), 4)
+	assert.True(t, storage.IsNotExist(err))
+
+	moduleFiles, err := bufmodule.GetModuleFiles(ctx, module, nil, 4)
+	require.NoError(t, err)
+	assert.Empty(t, moduleFiles)
+}
@@ -0,0 +1,114 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+)
+
+type ignoreMatcher struct {
+	patterns []*ignorePattern
+}
+
+func newIgnoreMatcher(data []byte) (*ignoreMatcher, error) {
+	var patterns []*ignorePattern
+	for i, line := range strings.Split(string(data), "\n") {
+		pattern, ok, err := parseIgnorePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", IgnoreFilePath, i+1, err)
+		}
+		if ok {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return &ignoreMatcher{
+		patterns: patterns,
+	}, nil
+}
+
+func (i *ignoreMatcher) Matches(path string) bool {
+	path = normalpath.Normalize(path)
+	if path == "." || len(i.patterns) == 0 {
+		return false
+	}
+	components := strings.Split(path, "/")
+	// a file cannot be re-included if one of its parent directories is ignored
+	for j := 1; j < len(components); j++ {
+		if i.matchesPath(strings.Join(components[:j], "/"), true) {
+			return true
+		}
+	}
+	return i.matchesPath(path, false)
+}
+
+func (*ignoreMatcher) isIgnoreMatcher() {}
+
+// matchesPath returns true if the last pattern that matches the path
+// is not a negation.
+func (i *ignoreMatcher) matchesPath(path string, isDir bool) bool {
+	ignored := false
+	for _, pattern := range i.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		if normalpath.MatchGlob(pattern.glob, path) {
+			ignored = !pattern.negate
+		}
+	}
+	return ignored
+}
+
+type ignorePattern struct {
+	glob    string
+	negate  bool
+	dirOnly bool
+}
+
+// parseIgnorePattern parses a single line of an ignore file.
+//
+// Returns false if the line is blank or a comment.
+func parseIgnorePattern(line string) (*ignorePattern, bool, error) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, false, nil
+	}
+	pattern := &ignorePattern{}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		pattern.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		pattern.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	// patterns with a slash at the beginning or in the middle are relative to the
+	// module root, all others match at any depth
+	if strings.HasPrefix(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	if err := normalpath.ValidateGlob(line); err != nil {
+		return nil, false, err
+	}
+	pattern.glob = line
+	return pattern, true, nil
+}
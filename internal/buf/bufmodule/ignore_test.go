@@ -0,0 +1,62 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	t.Parallel()
+	ignoreMatcher, err := bufmodule.NewIgnoreMatcher([]byte(`# examples are never built
+examples/
+*_test.proto
+!keep_test.proto
+/root.proto
+a/b/*.proto
+
+vendor/**
+!vendor/keep.proto
+`))
+	require.NoError(t, err)
+	for path, expected := range map[string]bool{
+		"examples/foo.proto":        true,
+		"foo/examples/bar.proto":    true,
+		"examples.proto":            false,
+		"foo_test.proto":            true,
+		"foo/bar_test.proto":        true,
+		"keep_test.proto":           false,
+		"foo/keep_test.proto":       false,
+		"root.proto":                true,
+		"foo/root.proto":            false,
+		"a/b/c.proto":               true,
+		"x/a/b/c.proto":             false,
+		"a/b/c/d.proto":             false,
+		"vendor/foo.proto":          true,
+		"vendor/keep.proto":         true,
+		"foo.proto":                 false,
+		"foo/examples_other.proto":  false,
+		"examples/nested/foo.proto": true,
+	} {
+		assert.Equal(t, expected, ignoreMatcher.Matches(path), path)
+	}
+
+	_, err = bufmodule.NewIgnoreMatcher([]byte("foo/../bar.proto"))
+	assert.Error(t, err)
+}
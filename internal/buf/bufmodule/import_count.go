@@ -0,0 +1,40 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+)
+
+func fileImportCounts(ctx context.Context, module Module) (map[string]ImportCounts, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	pathToImporters := reverseImportIndexForParsedSourceFiles(parsedSourceFiles)
+	pathToImportCounts := make(map[string]ImportCounts, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		importPaths := make(map[string]struct{})
+		for _, importPath := range parsedSourceFile.fileDescriptorProto.GetDependency() {
+			importPaths[importPath] = struct{}{}
+		}
+		path := parsedSourceFile.fileInfo.Path()
+		pathToImportCounts[path] = ImportCounts{
+			FanOut: len(importPaths),
+			FanIn:  len(pathToImporters[path]),
+		}
+	}
+	return pathToImportCounts, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func maxImportDepth(ctx context.Context, module Module) (int, []string, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return 0, nil, err
+	}
+	pathToImports := make(map[string][]string, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		pathToImports[parsedSourceFile.fileInfo.Path()] = parsedSourceFile.fileDescriptorProto.GetDependency()
+	}
+	importGraph := &importGraph{
+		pathToImports:      pathToImports,
+		pathToLongestChain: make(map[string][]string, len(pathToImports)),
+		visiting:           make(map[string]struct{}),
+	}
+	var longestChain []string
+	// parsedSourceFiles are sorted by path, so ties are broken by the first path
+	for _, parsedSourceFile := range parsedSourceFiles {
+		chain, err := importGraph.longestChain(parsedSourceFile.fileInfo.Path(), nil)
+		if err != nil {
+			return 0, nil, err
+		}
+		if len(chain) > len(longestChain) {
+			longestChain = chain
+		}
+	}
+	return len(longestChain), longestChain, nil
+}
+
+type importGraph struct {
+	// only contains source files of the module
+	pathToImports      map[string][]string
+	pathToLongestChain map[string][]string
+	visiting           map[string]struct{}
+}
+
+// longestChain returns the longest import chain starting at path, including path.
+//
+// stack is the current chain of paths being visited, and is only used for error messages.
+func (g *importGraph) longestChain(path string, stack []string) ([]string, error) {
+	if chain, ok := g.pathToLongestChain[path]; ok {
+		return chain, nil
+	}
+	stack = append(stack, path)
+	if _, ok := g.visiting[path]; ok {
+		return nil, fmt.Errorf("import cycle: %s", strings.Join(stack, " -> "))
+	}
+	g.visiting[path] = struct{}{}
+	var longestImportChain []string
+	for _, importPath := range g.pathToImports[path] {
+		// imports of files outside of the module, such as dependencies
+		// and the Well-Known Types, are terminal
+		if _, ok := g.pathToImports[importPath]; !ok {
+			continue
+		}
+		importChain, err := g.longestChain(importPath, stack)
+		if err != nil {
+			return nil, err
+		}
+		if len(importChain) > len(longestImportChain) {
+			longestImportChain = importChain
+		}
+	}
+	delete(g.visiting, path)
+	chain := append([]string{path}, longestImportChain...)
+	g.pathToLongestChain[path] = chain
+	return chain, nil
+}
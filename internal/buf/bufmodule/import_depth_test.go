@@ -0,0 +1,58 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxImportDepth(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a.proto",
+					Content: []byte(`syntax = "proto3"; import "b.proto"; import "d.proto";`),
+				},
+				{
+					Path:    "b.proto",
+					Content: []byte(`syntax = "proto3"; import "c.proto";`),
+				},
+				{
+					Path:    "c.proto",
+					Content: []byte(`syntax = "proto3"; import "google/protobuf/empty.proto";`),
+				},
+				{
+					Path:    "d.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	depth, chain, err := bufmodule.MaxImportDepth(ctx, module)
+	require.NoError(t, err)
+	assert.Equal(t, 3, depth)
+	assert.Equal(t, []string{"a.proto", "b.proto", "c.proto"}, chain)
+}
@@ -0,0 +1,84 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func fieldsMissingJSONName(ctx context.Context, module Module) ([]FieldRef, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var fieldRefs []FieldRef
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachMessage(parsedSourceFile.fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			for _, fieldDescriptorProto := range descriptorProto.GetField() {
+				if fieldDescriptorProto.GetName() == defaultJSONName(fieldDescriptorProto.GetName()) {
+					continue
+				}
+				if fieldHasJSONNameOption(fieldDescriptorProto) {
+					continue
+				}
+				fieldRefs = append(
+					fieldRefs,
+					FieldRef{
+						Name:     joinSymbolName(fullName, fieldDescriptorProto.GetName()),
+						FileInfo: parsedSourceFile.fileInfo,
+					},
+				)
+			}
+		})
+	}
+	return fieldRefs, nil
+}
+
+// fieldHasJSONNameOption returns true if the field sets the json_name option.
+//
+// Files are parsed without linking, so json_name remains an uninterpreted option.
+// The JsonName of the FieldDescriptorProto cannot be used on its own, as the parser
+// always populates it with the default JSON name.
+func fieldHasJSONNameOption(fieldDescriptorProto *descriptorpb.FieldDescriptorProto) bool {
+	for _, uninterpretedOption := range fieldDescriptorProto.GetOptions().GetUninterpretedOption() {
+		nameParts := uninterpretedOption.GetName()
+		if len(nameParts) == 1 && !nameParts[0].GetIsExtension() && nameParts[0].GetNamePart() == "json_name" {
+			return true
+		}
+	}
+	return fieldDescriptorProto.GetJsonName() != "" &&
+		fieldDescriptorProto.GetJsonName() != defaultJSONName(fieldDescriptorProto.GetName())
+}
+
+// defaultJSONName returns the default JSON name of a field, as computed by protoc.
+func defaultJSONName(name string) string {
+	result := make([]byte, 0, len(name))
+	upperNext := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' {
+			upperNext = true
+			continue
+		}
+		if upperNext && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upperNext = false
+		result = append(result, c)
+	}
+	return string(result)
+}
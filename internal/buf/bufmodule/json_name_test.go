@@ -0,0 +1,63 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsMissingJSONName(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+message Foo {
+  string bar = 1;
+  string bar_baz = 2;
+  string bar_qux = 3 [json_name = "barQux"];
+  map<string, string> map_values = 4 [json_name = "mapValues"];
+  message Nested {
+    int64 create_time = 1;
+  }
+}`),
+				},
+				{
+					Path:    "b/b.proto",
+					Content: []byte(`syntax = "proto3"; package b; message Bar { string one_two = 1 [json_name = "one_two"]; }`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	fieldRefs, err := bufmodule.FieldsMissingJSONName(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, fieldRefs, 2)
+	assert.Equal(t, "a.Foo.bar_baz", fieldRefs[0].Name)
+	assert.Equal(t, "a/a.proto", fieldRefs[0].FileInfo.Path())
+	assert.Equal(t, "a.Foo.Nested.create_time", fieldRefs[1].Name)
+	assert.Equal(t, "a/a.proto", fieldRefs[1].FileInfo.Path())
+}
@@ -0,0 +1,73 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/multierr"
+)
+
+func filesExceedingLineLength(ctx context.Context, module Module, maxLen int) (map[string][]int, error) {
+	if maxLen < 1 {
+		return nil, fmt.Errorf("max line length must be at least 1, got %d", maxLen)
+	}
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pathToLineNumbers := make(map[string][]int)
+	for _, sourceFileInfo := range sourceFileInfos {
+		lineNumbers, err := getLineNumbersExceedingLineLength(ctx, module, sourceFileInfo.Path(), maxLen)
+		if err != nil {
+			return nil, err
+		}
+		if len(lineNumbers) > 0 {
+			pathToLineNumbers[sourceFileInfo.Path()] = lineNumbers
+		}
+	}
+	return pathToLineNumbers, nil
+}
+
+func getLineNumbersExceedingLineLength(ctx context.Context, module Module, path string, maxLen int) (_ []int, retErr error) {
+	moduleFile, err := module.GetModuleFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, moduleFile.Close())
+	}()
+	// we do not use a bufio.Scanner as it has a maximum line length
+	reader := bufio.NewReader(moduleFile)
+	var lineNumbers []int
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		if utf8.RuneCountInString(line) > maxLen {
+			lineNumbers = append(lineNumbers, lineNumber)
+		}
+		if err == io.EOF {
+			return lineNumbers, nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func moduleMapFields(ctx context.Context, module Module) ([]MapField, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var mapFields []MapField
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachMessage(parsedSourceFile.fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			mapEntryNameToDescriptorProto := make(map[string]*descriptorpb.DescriptorProto)
+			for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+				if nestedDescriptorProto.GetOptions().GetMapEntry() {
+					mapEntryNameToDescriptorProto[nestedDescriptorProto.GetName()] = nestedDescriptorProto
+				}
+			}
+			if len(mapEntryNameToDescriptorProto) == 0 {
+				return
+			}
+			for _, fieldDescriptorProto := range descriptorProto.GetField() {
+				if fieldDescriptorProto.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+					continue
+				}
+				// files are not linked, so the type name of a map field is the name
+				// of the map entry message relative to the containing message
+				mapEntryDescriptorProto, ok := mapEntryNameToDescriptorProto[fieldDescriptorProto.GetTypeName()]
+				if !ok {
+					continue
+				}
+				keyType, valueType := getMapEntryTypes(mapEntryDescriptorProto)
+				mapFields = append(
+					mapFields,
+					MapField{
+						Message:   fullName,
+						Name:      fieldDescriptorProto.GetName(),
+						KeyType:   keyType,
+						ValueType: valueType,
+						FileInfo:  parsedSourceFile.fileInfo,
+					},
+				)
+			}
+		})
+	}
+	return mapFields, nil
+}
+
+// getMapEntryTypes returns the key and value types of the map entry message.
+func getMapEntryTypes(mapEntryDescriptorProto *descriptorpb.DescriptorProto) (string, string) {
+	var keyType string
+	var valueType string
+	for _, fieldDescriptorProto := range mapEntryDescriptorProto.GetField() {
+		switch fieldDescriptorProto.GetNumber() {
+		case 1:
+			keyType = getFieldTypeString(fieldDescriptorProto)
+		case 2:
+			valueType = getFieldTypeString(fieldDescriptorProto)
+		}
+	}
+	return keyType, valueType
+}
+
+// getFieldTypeString returns the type of the field as written in the source file,
+// such as "int32" or "foo.Bar".
+func getFieldTypeString(fieldDescriptorProto *descriptorpb.FieldDescriptorProto) string {
+	if typeName := fieldDescriptorProto.GetTypeName(); typeName != "" {
+		return typeName
+	}
+	return strings.ToLower(strings.TrimPrefix(fieldDescriptorProto.GetType().String(), "TYPE_"))
+}
@@ -0,0 +1,62 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleMapFields(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+message Foo {
+  map<string, int32> counts = 1;
+  repeated string names = 2;
+  message Bar {
+    map<int64, Foo> foos = 1;
+  }
+}`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	mapFields, err := bufmodule.ModuleMapFields(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, mapFields, 2)
+	assert.Equal(t, "a.Foo", mapFields[0].Message)
+	assert.Equal(t, "counts", mapFields[0].Name)
+	assert.Equal(t, "string", mapFields[0].KeyType)
+	assert.Equal(t, "int32", mapFields[0].ValueType)
+	assert.Equal(t, "a/a.proto", mapFields[0].FileInfo.Path())
+	assert.Equal(t, "a.Foo.Bar", mapFields[1].Message)
+	assert.Equal(t, "foos", mapFields[1].Name)
+	assert.Equal(t, "int64", mapFields[1].KeyType)
+	assert.Equal(t, "Foo", mapFields[1].ValueType)
+}
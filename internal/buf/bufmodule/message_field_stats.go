@@ -0,0 +1,39 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func moduleMessageFieldStats(ctx context.Context, module Module) (MessageFieldStats, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return MessageFieldStats{}, err
+	}
+	var messageFieldStats MessageFieldStats
+	for _, parsedSourceFile := range parsedSourceFiles {
+		forEachMessage(parsedSourceFile.fileDescriptorProto, func(_ string, descriptorProto *descriptorpb.DescriptorProto) {
+			messageFieldStats.TotalMessages++
+			messageFieldStats.TotalFields += len(descriptorProto.GetField())
+		})
+	}
+	if messageFieldStats.TotalMessages > 0 {
+		messageFieldStats.AverageFieldsPerMessage = float64(messageFieldStats.TotalFields) / float64(messageFieldStats.TotalMessages)
+	}
+	return messageFieldStats, nil
+}
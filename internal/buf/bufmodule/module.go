@@ -18,10 +18,14 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"path"
+	"sort"
 
 	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/storage"
 	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"go.uber.org/multierr"
 )
 
 type module struct {
@@ -30,6 +34,20 @@ type module struct {
 	moduleIdentity       ModuleIdentity
 	commit               string
 	documentation        string
+	// dirPathToDocumentation does not contain the root directory.
+	dirPathToDocumentation map[string]string
+	// targetFilePaths are only used if targetFilePathsSet is true.
+	targetFilePaths    []string
+	targetFilePathsSet bool
+	// importPathMap contains the normalized import paths.
+	importPathMap map[string]struct{}
+	// only used during construction
+	lockFilePath           string
+	excludeGlobs           []string
+	documentationOverride  *string
+	symlinkPolicy          SymlinkPolicy
+	importPaths            []string
+	documentationFileNames []string
 }
 
 func newModuleForProto(
@@ -68,12 +86,44 @@ func newModuleForProto(
 	)
 }
 
+func newModuleForFiles(
+	ctx context.Context,
+	pathToData map[string][]byte,
+	options ...ModuleOption,
+) (*module, error) {
+	paths := make([]string, 0, len(pathToData))
+	for path := range pathToData {
+		paths = append(paths, path)
+	}
+	// sort so that the first invalid path is deterministic
+	sort.Strings(paths)
+	readBucketBuilder := storagemem.NewReadBucketBuilder()
+	for _, path := range paths {
+		if err := ValidateModuleFilePath(path); err != nil {
+			return nil, fmt.Errorf("invalid module file path %q: %w", path, err)
+		}
+		if err := storage.PutPath(ctx, readBucketBuilder, path, pathToData[path]); err != nil {
+			return nil, err
+		}
+	}
+	sourceReadBucket, err := readBucketBuilder.ToReadBucket()
+	if err != nil {
+		return nil, err
+	}
+	return newModuleForBucket(ctx, sourceReadBucket, options...)
+}
+
 func newModuleForBucket(
 	ctx context.Context,
 	sourceReadBucket storage.ReadBucket,
 	options ...ModuleOption,
 ) (*module, error) {
-	dependencyModulePins, err := getDependencyModulePinsForBucket(ctx, sourceReadBucket)
+	// we need the lock file path before the module is constructed
+	optionsModule := &module{}
+	for _, option := range options {
+		option(optionsModule)
+	}
+	dependencyModulePins, err := getDependencyModulePinsForBucket(ctx, sourceReadBucket, optionsModule.lockFilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -94,60 +144,161 @@ func newModuleForBucketWithDependencyModulePins(
 	if err := ValidateModulePinsUniqueByIdentity(dependencyModulePins); err != nil {
 		return nil, err
 	}
-	documentationReader, err := sourceReadBucket.Get(ctx, DocumentationFilePath)
-	// we allow the lack of documentation file
+	// we rely on this being sorted here
+	SortModulePins(dependencyModulePins)
+	module := &module{
+		dependencyModulePins: dependencyModulePins,
+	}
+	for _, option := range options {
+		option(module)
+	}
+	if len(module.importPaths) > 0 {
+		module.importPathMap = make(map[string]struct{}, len(module.importPaths))
+		for _, importPath := range module.importPaths {
+			normalizedImportPath, err := normalpath.NormalizeAndValidate(importPath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid import path %q: %w", importPath, err)
+			}
+			module.importPathMap[normalizedImportPath] = struct{}{}
+		}
+	}
+	if module.documentationOverride != nil {
+		// the override takes precedence, the documentation file is ignored
+		module.documentation = *module.documentationOverride
+	} else {
+		documentationReader, err := sourceReadBucket.Get(ctx, DocumentationFilePath)
+		// we allow the lack of documentation file
+		if err != nil && !storage.IsNotExist(err) {
+			return nil, err
+		}
+		if documentationReader != nil {
+			documentationBytes, err := io.ReadAll(documentationReader)
+			if err != nil {
+				return nil, multierr.Append(err, documentationReader.Close())
+			}
+			if err := documentationReader.Close(); err != nil {
+				return nil, err
+			}
+			module.documentation = string(documentationBytes)
+		}
+	}
+	if len(module.documentationFileNames) > 0 {
+		dirPathToDocumentation, err := getDirPathToDocumentation(ctx, sourceReadBucket, module.documentationFileNames)
+		if err != nil {
+			return nil, err
+		}
+		module.dirPathToDocumentation = dirPathToDocumentation
+	}
+	mappers := []storage.Mapper{
+		storage.MatchPathExt(".proto"),
+	}
+	ignoreData, err := storage.ReadPath(ctx, sourceReadBucket, IgnoreFilePath)
+	// we allow the lack of ignore file
 	if err != nil && !storage.IsNotExist(err) {
 		return nil, err
 	}
-	documentationContents := ""
-	if documentationReader != nil {
-		documentationBytes, err := io.ReadAll(documentationReader)
+	if len(ignoreData) > 0 {
+		ignoreMatcher, err := newIgnoreMatcher(ignoreData)
 		if err != nil {
 			return nil, err
 		}
-		documentationContents = string(documentationBytes)
+		mappers = append(mappers, storage.MatchNot(storage.MatchPathFunc(ignoreMatcher.Matches)))
 	}
-	// we rely on this being sorted here
-	SortModulePins(dependencyModulePins)
-	module := &module{
-		sourceReadBucket:     storage.MapReadBucket(sourceReadBucket, storage.MatchPathExt(".proto")),
-		dependencyModulePins: dependencyModulePins,
-		documentation:        documentationContents,
+	if len(module.excludeGlobs) > 0 {
+		excludeMatchers := make([]storage.Matcher, len(module.excludeGlobs))
+		for i, excludeGlob := range module.excludeGlobs {
+			excludeMatchers[i] = storage.MatchPathGlob(excludeGlob)
+		}
+		mappers = append(mappers, storage.MatchNot(storage.MatchOr(excludeMatchers...)))
 	}
-	for _, option := range options {
-		option(module)
+	module.sourceReadBucket = storage.MapReadBucket(sourceReadBucket, mappers...)
+	if module.targetFilePathsSet {
+		targetFilePaths, err := validateTargetFilePaths(ctx, module.sourceReadBucket, module.targetFilePaths)
+		if err != nil {
+			return nil, err
+		}
+		module.targetFilePaths = targetFilePaths
 	}
 	return module, nil
 }
 
 func (m *module) TargetFileInfos(ctx context.Context) ([]FileInfo, error) {
-	return m.SourceFileInfos(ctx)
+	if !m.targetFilePathsSet {
+		return m.SourceFileInfos(ctx)
+	}
+	fileInfos := make([]FileInfo, 0, len(m.targetFilePaths))
+	for _, targetFilePath := range m.targetFilePaths {
+		objectInfo, err := m.sourceReadBucket.Stat(ctx, targetFilePath)
+		if err != nil {
+			return nil, err
+		}
+		fileInfo, _, err := m.newSourceFileInfo(objectInfo, nil)
+		if err != nil {
+			return nil, err
+		}
+		fileInfos = append(fileInfos, fileInfo)
+	}
+	sortFileInfos(fileInfos)
+	return fileInfos, nil
 }
 
 func (m *module) SourceFileInfos(ctx context.Context) ([]FileInfo, error) {
 	var fileInfos []FileInfo
+	if err := m.WalkSourceFileInfos(ctx, func(fileInfo FileInfo) error {
+		fileInfos = append(fileInfos, fileInfo)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sortFileInfos(fileInfos)
+	return fileInfos, nil
+}
+
+func (m *module) WalkSourceFileInfos(
+	ctx context.Context,
+	f func(FileInfo) error,
+	options ...WalkSourceFileInfosOption,
+) error {
+	walkSourceFileInfosOptions := newWalkSourceFileInfosOptions()
+	for _, option := range options {
+		option(walkSourceFileInfosOptions)
+	}
+	if walkSourceFileInfosOptions.sorted {
+		fileInfos, err := m.SourceFileInfos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, fileInfo := range fileInfos {
+			if err := f(fileInfo); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	// we want to return errors from f as-is, and only wrap errors from walking
+	var fErr error
+	resolvedExternalPaths := make(map[string]struct{})
 	if walkErr := m.sourceReadBucket.Walk(ctx, "", func(objectInfo storage.ObjectInfo) error {
 		// super overkill but ok
 		if err := ValidateModuleFilePath(objectInfo.Path()); err != nil {
 			return err
 		}
-		fileInfo, err := NewFileInfo(
-			objectInfo.Path(),
-			objectInfo.ExternalPath(),
-			false,
-			m.moduleIdentity,
-			m.commit,
-		)
+		fileInfo, ok, err := m.newSourceFileInfo(objectInfo, resolvedExternalPaths)
 		if err != nil {
 			return err
 		}
-		fileInfos = append(fileInfos, fileInfo)
-		return nil
+		if !ok {
+			return nil
+		}
+		fErr = f(fileInfo)
+		return fErr
 	}); walkErr != nil {
-		return nil, fmt.Errorf("failed to enumerate module files: %w", walkErr)
+		if fErr != nil {
+			return fErr
+		}
+		return fmt.Errorf("failed to enumerate module files: %w", walkErr)
 	}
-	sortFileInfos(fileInfos)
-	return fileInfos, nil
+	return nil
 }
 
 func (m *module) GetModuleFile(ctx context.Context, path string) (ModuleFile, error) {
@@ -155,23 +306,63 @@ func (m *module) GetModuleFile(ctx context.Context, path string) (ModuleFile, er
 	if err := ValidateModuleFilePath(path); err != nil {
 		return nil, err
 	}
+	var objectInfo storage.ObjectInfo
+	if m.symlinkPolicy != 0 {
+		// the ObjectInfo of the ReadObjectCloser does not say if it was reached through a symlink
+		var err error
+		objectInfo, err = m.sourceReadBucket.Stat(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+	}
 	readObjectCloser, err := m.sourceReadBucket.Get(ctx, path)
 	if err != nil {
 		return nil, err
 	}
-	fileInfo, err := NewFileInfo(
-		readObjectCloser.Path(),
-		readObjectCloser.ExternalPath(),
-		false,
-		m.moduleIdentity,
-		m.commit,
-	)
+	if objectInfo == nil {
+		objectInfo = readObjectCloser
+	}
+	fileInfo, _, err := m.newSourceFileInfo(objectInfo, nil)
 	if err != nil {
-		return nil, err
+		return nil, multierr.Append(err, readObjectCloser.Close())
 	}
 	return newModuleFile(fileInfo, readObjectCloser), nil
 }
 
+func (m *module) AllPaths(ctx context.Context) ([]string, error) {
+	if m.symlinkPolicy != 0 {
+		// the symlink policy can reject or skip files, which requires the FileInfos
+		sourceFileInfos, err := m.SourceFileInfos(ctx)
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, len(sourceFileInfos))
+		for i, sourceFileInfo := range sourceFileInfos {
+			paths[i] = sourceFileInfo.Path()
+		}
+		return paths, nil
+	}
+	var paths []string
+	if walkErr := m.sourceReadBucket.Walk(ctx, "", func(objectInfo storage.ObjectInfo) error {
+		if err := ValidateModuleFilePath(objectInfo.Path()); err != nil {
+			return err
+		}
+		paths = append(paths, objectInfo.Path())
+		return nil
+	}); walkErr != nil {
+		return nil, fmt.Errorf("failed to enumerate module files: %w", walkErr)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func (m *module) ContainsPath(ctx context.Context, path string) (bool, error) {
+	if err := ValidateModuleFilePath(path); err != nil {
+		return false, err
+	}
+	return storage.Exists(ctx, m.sourceReadBucket, path)
+}
+
 func (m *module) DependencyModulePins() []ModulePin {
 	// already sorted in constructor
 	return m.dependencyModulePins
@@ -181,6 +372,14 @@ func (m *module) Documentation() string {
 	return m.documentation
 }
 
+func (m *module) DocumentationForPath(dirPath string) string {
+	dirPath = normalpath.Normalize(dirPath)
+	if dirPath == "." {
+		return m.documentation
+	}
+	return m.dirPathToDocumentation[dirPath]
+}
+
 func (m *module) getSourceReadBucket() storage.ReadBucket {
 	return m.sourceReadBucket
 }
@@ -193,4 +392,112 @@ func (m *module) getCommit() string {
 	return m.commit
 }
 
+func (m *module) isImportPath(path string) bool {
+	return normalpath.MapHasEqualOrContainingPath(m.importPathMap, path, normalpath.Relative)
+}
+
 func (m *module) isModule() {}
+
+type walkSourceFileInfosOptions struct {
+	sorted bool
+}
+
+func newWalkSourceFileInfosOptions() *walkSourceFileInfosOptions {
+	return &walkSourceFileInfosOptions{}
+}
+
+// validateTargetFilePaths normalizes and validates the target file paths, and
+// validates that they are unique and exist in the sourceReadBucket.
+func validateTargetFilePaths(
+	ctx context.Context,
+	sourceReadBucket storage.ReadBucket,
+	targetFilePaths []string,
+) ([]string, error) {
+	normalizedTargetFilePaths := make([]string, 0, len(targetFilePaths))
+	seen := make(map[string]struct{}, len(targetFilePaths))
+	for _, targetFilePath := range targetFilePaths {
+		if err := ValidateModuleFilePath(targetFilePath); err != nil {
+			return nil, fmt.Errorf("invalid target path %q: %w", targetFilePath, err)
+		}
+		normalizedTargetFilePath, err := normalpath.NormalizeAndValidate(targetFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target path %q: %w", targetFilePath, err)
+		}
+		if _, ok := seen[normalizedTargetFilePath]; ok {
+			continue
+		}
+		seen[normalizedTargetFilePath] = struct{}{}
+		if _, err := sourceReadBucket.Stat(ctx, normalizedTargetFilePath); err != nil {
+			return nil, fmt.Errorf("target path %q: %w", targetFilePath, err)
+		}
+		normalizedTargetFilePaths = append(normalizedTargetFilePaths, normalizedTargetFilePath)
+	}
+	return normalizedTargetFilePaths, nil
+}
+
+// getDirPathToDocumentation reads the documentation files in the subdirectories
+// of the readBucket.
+func getDirPathToDocumentation(
+	ctx context.Context,
+	readBucket storage.ReadBucket,
+	documentationFileNames []string,
+) (map[string]string, error) {
+	for _, documentationFileName := range documentationFileNames {
+		if _, err := path.Match(documentationFileName, ""); err != nil {
+			return nil, fmt.Errorf("invalid documentation file name %q: %w", documentationFileName, err)
+		}
+	}
+	// the index within documentationFileNames of the file chosen for each directory
+	dirPathToFileNameIndex := make(map[string]int)
+	dirPathToFilePath := make(map[string]string)
+	if err := readBucket.Walk(
+		ctx,
+		"",
+		func(objectInfo storage.ObjectInfo) error {
+			filePath := objectInfo.Path()
+			dirPath := normalpath.Dir(filePath)
+			// the root documentation file is read separately
+			if dirPath == "." {
+				return nil
+			}
+			fileNameIndex := getDocumentationFileNameIndex(normalpath.Base(filePath), documentationFileNames)
+			if fileNameIndex < 0 {
+				return nil
+			}
+			if existingFilePath, ok := dirPathToFilePath[dirPath]; ok {
+				existingFileNameIndex := dirPathToFileNameIndex[dirPath]
+				if existingFileNameIndex < fileNameIndex ||
+					(existingFileNameIndex == fileNameIndex && existingFilePath < filePath) {
+					return nil
+				}
+			}
+			dirPathToFileNameIndex[dirPath] = fileNameIndex
+			dirPathToFilePath[dirPath] = filePath
+			return nil
+		},
+	); err != nil {
+		return nil, err
+	}
+	dirPathToDocumentation := make(map[string]string, len(dirPathToFilePath))
+	for dirPath, filePath := range dirPathToFilePath {
+		data, err := storage.ReadPath(ctx, readBucket, filePath)
+		if err != nil {
+			return nil, err
+		}
+		dirPathToDocumentation[dirPath] = string(data)
+	}
+	return dirPathToDocumentation, nil
+}
+
+// getDocumentationFileNameIndex returns the index of the first documentation file
+// name that matches the base name, or -1 if none match.
+//
+// The documentation file names are expected to have been validated.
+func getDocumentationFileNameIndex(baseName string, documentationFileNames []string) int {
+	for i, documentationFileName := range documentationFileNames {
+		if matched, _ := path.Match(documentationFileName, baseName); matched {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+func diffModules(ctx context.Context, oldModule Module, newModule Module) (*ModuleDiff, error) {
+	oldPaths, err := getSourceFilePathSet(ctx, oldModule)
+	if err != nil {
+		return nil, err
+	}
+	newPaths, err := getSourceFilePathSet(ctx, newModule)
+	if err != nil {
+		return nil, err
+	}
+	moduleDiff := &ModuleDiff{}
+	for _, path := range stringutil.MapToSortedSlice(newPaths) {
+		if _, ok := oldPaths[path]; !ok {
+			moduleDiff.AddedPaths = append(moduleDiff.AddedPaths, path)
+			continue
+		}
+		oldDigest, err := getFileContentDigest(ctx, oldModule.getSourceReadBucket(), path)
+		if err != nil {
+			return nil, err
+		}
+		newDigest, err := getFileContentDigest(ctx, newModule.getSourceReadBucket(), path)
+		if err != nil {
+			return nil, err
+		}
+		if oldDigest != newDigest {
+			moduleDiff.ModifiedPaths = append(moduleDiff.ModifiedPaths, path)
+		}
+	}
+	for _, path := range stringutil.MapToSortedSlice(oldPaths) {
+		if _, ok := newPaths[path]; !ok {
+			moduleDiff.RemovedPaths = append(moduleDiff.RemovedPaths, path)
+		}
+	}
+	return moduleDiff, nil
+}
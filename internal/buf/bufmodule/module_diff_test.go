@@ -0,0 +1,62 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffModules(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	oldModule, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto":       []byte(`syntax = "proto3"; package a;`),
+			"b/b.proto":     []byte(`syntax = "proto3"; package b;`),
+			"removed.proto": []byte(`syntax = "proto3"; package removed;`),
+		},
+	)
+	require.NoError(t, err)
+	newModule, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto":     []byte(`syntax = "proto3"; package a;`),
+			"b/b.proto":   []byte(`syntax = "proto3"; package b; message Foo {}`),
+			"added.proto": []byte(`syntax = "proto3" package added`),
+			"README.md":   []byte(`not a source file`),
+		},
+	)
+	require.NoError(t, err)
+	moduleDiff, err := bufmodule.DiffModules(ctx, oldModule, newModule)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		&bufmodule.ModuleDiff{
+			AddedPaths:    []string{"added.proto"},
+			RemovedPaths:  []string{"removed.proto"},
+			ModifiedPaths: []string{"b/b.proto"},
+		},
+		moduleDiff,
+	)
+	moduleDiff, err = bufmodule.DiffModules(ctx, newModule, newModule)
+	require.NoError(t, err)
+	assert.Equal(t, &bufmodule.ModuleDiff{}, moduleDiff)
+}
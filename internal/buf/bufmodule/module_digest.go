@@ -0,0 +1,108 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"go.uber.org/multierr"
+	"golang.org/x/crypto/sha3"
+)
+
+// shake256DigestLength is the number of bytes of SHAKE256 output used for a digest.
+const shake256DigestLength = 64
+
+// Digest hashes a manifest of the module rather than the raw contents so
+// that boundaries between paths, contents, and pins are unambiguous.
+//
+// The manifest is made of:
+//
+//   - One line per source file, sorted by path: "file <path> <content digest>".
+//   - One line per dependency pin, sorted by SortModulePins:
+//     "dep <remote>/<owner>/<repository> <commit> <digest>".
+//   - If the documentation is not empty: "doc <documentation digest>".
+func (m *module) Digest(ctx context.Context) (string, error) {
+	hash := sha3.NewShake256()
+	// SourceFileInfos are sorted by path, and the paths do not depend on external paths
+	sourceFileInfos, err := m.SourceFileInfos(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, sourceFileInfo := range sourceFileInfos {
+		contentDigest, err := getFileContentDigest(ctx, m.sourceReadBucket, sourceFileInfo.Path())
+		if err != nil {
+			return "", err
+		}
+		if _, err := fmt.Fprintf(hash, "file %q %s\n", sourceFileInfo.Path(), contentDigest); err != nil {
+			return "", err
+		}
+	}
+	// already sorted in constructor
+	for _, dependencyModulePin := range m.dependencyModulePins {
+		if _, err := fmt.Fprintf(
+			hash,
+			"dep %q %q %q\n",
+			dependencyModulePin.IdentityString(),
+			dependencyModulePin.Commit(),
+			dependencyModulePin.Digest(),
+		); err != nil {
+			return "", err
+		}
+	}
+	if m.documentation != "" {
+		if _, err := fmt.Fprintf(
+			hash,
+			"doc %s\n",
+			shake256Hex([]byte(m.documentation)),
+		); err != nil {
+			return "", err
+		}
+	}
+	return shake256DigestPrefix + ":" + shake256HashHex(hash), nil
+}
+
+func getFileContentDigest(ctx context.Context, readBucket storage.ReadBucket, path string) (_ string, retErr error) {
+	readObjectCloser, err := readBucket.Get(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, readObjectCloser.Close())
+	}()
+	hash := sha3.NewShake256()
+	if _, err := io.Copy(hash, readObjectCloser); err != nil {
+		return "", err
+	}
+	return shake256HashHex(hash), nil
+}
+
+// shake256Hex returns the hex-encoded SHAKE256 digest of the data.
+func shake256Hex(data []byte) string {
+	digest := make([]byte, shake256DigestLength)
+	sha3.ShakeSum256(digest, data)
+	return hex.EncodeToString(digest)
+}
+
+// shake256HashHex returns the hex-encoded digest read from the ShakeHash.
+func shake256HashHex(shakeHash sha3.ShakeHash) string {
+	digest := make([]byte, shake256DigestLength)
+	// Read from a ShakeHash never returns an error
+	_, _ = shakeHash.Read(digest)
+	return hex.EncodeToString(digest)
+}
@@ -0,0 +1,147 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleDigest(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	protoModule := &modulev1alpha1.Module{
+		Files: []*modulev1alpha1.ModuleFile{
+			{
+				Path:    "b/b.proto",
+				Content: []byte(`syntax = "proto3"; package b;`),
+			},
+			{
+				Path:    "a/a.proto",
+				Content: []byte(`syntax = "proto3"; package a;`),
+			},
+		},
+		Dependencies:  []*modulev1alpha1.ModulePin{bufmodule.NewProtoModulePinForModulePin(modulePin)},
+		Documentation: "# docs",
+	}
+	protoModuleDigest := testGetModuleDigest(t, protoModule)
+	assert.True(t, strings.HasPrefix(protoModuleDigest, "shake256:"), protoModuleDigest)
+	// deterministic
+	assert.Equal(t, protoModuleDigest, testGetModuleDigest(t, protoModule))
+
+	// same content written in a different order with different external paths
+	readBucketBuilder := storagemem.NewReadBucketBuilder()
+	testPutPathWithExternalPath(t, readBucketBuilder, "a/a.proto", "/other/layout/a/a.proto", `syntax = "proto3"; package a;`)
+	testPutPathWithExternalPath(t, readBucketBuilder, "b/b.proto", "/other/layout/b/b.proto", `syntax = "proto3"; package b;`)
+	testPutPathWithExternalPath(t, readBucketBuilder, bufmodule.DocumentationFilePath, "/other/layout/buf.md", "# docs")
+	require.NoError(t, bufmodule.PutModuleDependencyModulePinsToBucket(ctx, readBucketBuilder, []bufmodule.ModulePin{modulePin}))
+	readBucket, err := readBucketBuilder.ToReadBucket()
+	require.NoError(t, err)
+	bucketModule, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	bucketModuleDigest, err := bucketModule.Digest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, protoModuleDigest, bucketModuleDigest)
+	protoModuleModule, err := bufmodule.NewModuleForProto(ctx, protoModule)
+	require.NoError(t, err)
+	equal, err := bufmodule.ModuleDigestEqual(ctx, protoModuleModule, bucketModule)
+	require.NoError(t, err)
+	assert.True(t, equal)
+
+	// changing any of the contents changes the digest
+	changedFileModule := &modulev1alpha1.Module{
+		Files: []*modulev1alpha1.ModuleFile{
+			protoModule.Files[0],
+			{
+				Path:    "a/a.proto",
+				Content: []byte(`syntax = "proto3"; package a.v1;`),
+			},
+		},
+		Dependencies:  protoModule.Dependencies,
+		Documentation: protoModule.Documentation,
+	}
+	assert.NotEqual(t, protoModuleDigest, testGetModuleDigest(t, changedFileModule))
+	renamedFileModule := &modulev1alpha1.Module{
+		Files: []*modulev1alpha1.ModuleFile{
+			protoModule.Files[0],
+			{
+				Path:    "a/c.proto",
+				Content: protoModule.Files[1].Content,
+			},
+		},
+		Dependencies:  protoModule.Dependencies,
+		Documentation: protoModule.Documentation,
+	}
+	assert.NotEqual(t, protoModuleDigest, testGetModuleDigest(t, renamedFileModule))
+	noDependenciesModule := &modulev1alpha1.Module{
+		Files:         protoModule.Files,
+		Documentation: protoModule.Documentation,
+	}
+	assert.NotEqual(t, protoModuleDigest, testGetModuleDigest(t, noDependenciesModule))
+	changedDocumentationModule := &modulev1alpha1.Module{
+		Files:         protoModule.Files,
+		Dependencies:  protoModule.Dependencies,
+		Documentation: "# other docs",
+	}
+	changedDocumentationModuleModule, err := bufmodule.NewModuleForProto(ctx, changedDocumentationModule)
+	require.NoError(t, err)
+	equal, err = bufmodule.ModuleDigestEqual(ctx, protoModuleModule, changedDocumentationModuleModule)
+	require.NoError(t, err)
+	assert.False(t, equal)
+}
+
+func testGetModuleDigest(t *testing.T, protoModule *modulev1alpha1.Module) string {
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(ctx, protoModule)
+	require.NoError(t, err)
+	digest, err := module.Digest(ctx)
+	require.NoError(t, err)
+	return digest
+}
+
+func testPutPathWithExternalPath(
+	t *testing.T,
+	writeBucket storage.WriteBucket,
+	path string,
+	externalPath string,
+	content string,
+) {
+	writeObjectCloser, err := writeBucket.Put(context.Background(), path)
+	require.NoError(t, err)
+	_, err = writeObjectCloser.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, writeObjectCloser.SetExternalPath(externalPath))
+	require.NoError(t, writeObjectCloser.Close())
+}
@@ -75,7 +75,7 @@ func (m *moduleFileSet) AllFileInfos(ctx context.Context) ([]FileInfo, error) {
 		fileInfo, err := NewFileInfo(
 			moduleObjectInfo.Path(),
 			moduleObjectInfo.ExternalPath(),
-			!isNotImport,
+			!isNotImport || m.Module.isImportPath(moduleObjectInfo.Path()),
 			moduleObjectInfo.ModuleIdentity(),
 			moduleObjectInfo.Commit(),
 		)
@@ -110,7 +110,7 @@ func (m *moduleFileSet) GetModuleFile(ctx context.Context, path string) (ModuleF
 	fileInfo, err := NewFileInfo(
 		readObjectCloser.Path(),
 		readObjectCloser.ExternalPath(),
-		!isNotImport,
+		!isNotImport || m.Module.isImportPath(path),
 		moduleObjectInfo.ModuleIdentity(),
 		moduleObjectInfo.Commit(),
 	)
@@ -0,0 +1,56 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+)
+
+type moduleIdentityMatcher struct {
+	pattern string
+}
+
+func newModuleIdentityMatcher(pattern string) (*moduleIdentityMatcher, error) {
+	if err := normalpath.ValidateGlob(pattern); err != nil {
+		return nil, fmt.Errorf("invalid module identity pattern: %w", err)
+	}
+	components := strings.Split(pattern, "/")
+	hasDoubleStar := false
+	for _, component := range components {
+		if component == "**" {
+			hasDoubleStar = true
+			break
+		}
+	}
+	if !hasDoubleStar && len(components) != 3 {
+		return nil, fmt.Errorf("invalid module identity pattern %q: must be in the form remote/owner/repository", pattern)
+	}
+	return &moduleIdentityMatcher{
+		pattern: pattern,
+	}, nil
+}
+
+func (m *moduleIdentityMatcher) String() string {
+	return m.pattern
+}
+
+func (m *moduleIdentityMatcher) Matches(moduleIdentity ModuleIdentity) bool {
+	return normalpath.MatchGlob(m.pattern, moduleIdentity.IdentityString())
+}
+
+func (*moduleIdentityMatcher) isModuleIdentityMatcher() {}
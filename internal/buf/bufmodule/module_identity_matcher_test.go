@@ -0,0 +1,64 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleIdentityMatcher(t *testing.T) {
+	t.Parallel()
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/*", "bsr.acme.com/acme/weather", true)
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/*", "bsr.acme.com/other/weather", false)
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/*", "buf.build/acme/weather", false)
+	testModuleIdentityMatcher(t, "bsr.acme.com/*/weather", "bsr.acme.com/other/weather", true)
+	testModuleIdentityMatcher(t, "*/acme/weather", "bsr.acme.com:8443/acme/weather", true)
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/weather-*", "bsr.acme.com/acme/weather-v2", true)
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/weather", "bsr.acme.com/acme/weather", true)
+	testModuleIdentityMatcher(t, "bsr.acme.com/acme/weather", "bsr.acme.com/acme/weathers", false)
+	// "*" does not match across components, "**" does
+	testModuleIdentityMatcher(t, "*/weather/*", "bsr.acme.com/acme/weather", false)
+	testModuleIdentityMatcher(t, "bsr.acme.com/**", "bsr.acme.com/acme/weather", true)
+	testModuleIdentityMatcher(t, "**/weather", "bsr.acme.com/acme/weather", true)
+	testModuleIdentityMatcher(t, "**/acme/**", "buf.build/acme/weather", true)
+	testModuleIdentityMatcher(t, "**/acme/**", "buf.build/other/weather", false)
+
+	for _, pattern := range []string{
+		"",
+		"bsr.acme.com/acme",
+		"bsr.acme.com/*",
+		"bsr.acme.com/acme/weather/extra",
+		"bsr.acme.com//weather",
+		"bsr.acme.com/acme**/weather",
+		"bsr.acme.com/[acme/weather",
+		"/bsr.acme.com/acme/weather",
+	} {
+		_, err := bufmodule.NewModuleIdentityMatcher(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
+
+func testModuleIdentityMatcher(t *testing.T, pattern string, identityString string, expected bool) {
+	moduleIdentityMatcher, err := bufmodule.NewModuleIdentityMatcher(pattern)
+	require.NoError(t, err)
+	assert.Equal(t, pattern, moduleIdentityMatcher.String())
+	moduleIdentity, err := bufmodule.ModuleIdentityForString(identityString)
+	require.NoError(t, err)
+	assert.Equal(t, expected, moduleIdentityMatcher.Matches(moduleIdentity), "%s %s", pattern, identityString)
+}
@@ -0,0 +1,52 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleIdentityForStringWithPort(t *testing.T) {
+	t.Parallel()
+	moduleIdentity, err := ModuleIdentityForString("bsr.internal.acme.com:8443/acme/weather")
+	require.NoError(t, err)
+	assert.Equal(t, "bsr.internal.acme.com:8443", moduleIdentity.Remote())
+	assert.Equal(t, "acme", moduleIdentity.Owner())
+	assert.Equal(t, "weather", moduleIdentity.Repository())
+	assert.Equal(t, "bsr.internal.acme.com:8443/acme/weather", moduleIdentity.IdentityString())
+	moduleReference, err := ModuleReferenceForString("bsr.internal.acme.com:8443/acme/weather:v1")
+	require.NoError(t, err)
+	assert.Equal(t, "bsr.internal.acme.com:8443", moduleReference.Remote())
+	assert.Equal(t, "v1", moduleReference.Reference())
+	assert.Equal(t, "bsr.internal.acme.com:8443/acme/weather:v1", moduleReference.String())
+}
+
+func TestModuleIdentityForStringError(t *testing.T) {
+	t.Parallel()
+	for _, path := range []string{
+		"bsr.internal.acme.com:http/acme/weather",
+		"bsr.internal.acme.com:0/acme/weather",
+		"bsr.internal.acme.com:65536/acme/weather",
+		"bsr.internal.acme.com:/acme/weather",
+		"bsr.internal.acme.com:8443/ /weather",
+		"bsr.internal.acme.com:8443/acme/ ",
+	} {
+		_, err := ModuleIdentityForString(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateModulePinTimestamps(t *testing.T) {
+	t.Parallel()
+	valid := testNewModulePinWithCreateTime(t, "valid", time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, bufmodule.ValidateModulePinTimestamps([]bufmodule.ModulePin{valid}))
+	require.NoError(t, bufmodule.ValidateModulePinTimestamps(nil))
+
+	zero := testNewModulePinWithCreateTime(t, "zero", time.Time{})
+	future := testNewModulePinWithCreateTime(t, "future", time.Now().Add(24*time.Hour))
+	err := bufmodule.ValidateModulePinTimestamps([]bufmodule.ModulePin{valid, zero, future})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "buf.build/foob/zero:"+bufmoduletesting.TestCommit+" has no create time")
+	assert.Contains(t, err.Error(), "buf.build/foob/future:"+bufmoduletesting.TestCommit+" has a create time in the future")
+	assert.NotContains(t, err.Error(), "foob/valid")
+}
+
+func TestNewModulePin(t *testing.T) {
+	t.Parallel()
+	createTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	modulePin, err := bufmodule.NewModulePin(
+		"bsr.acme.com",
+		"acme",
+		"payments",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		createTime,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "bsr.acme.com/acme/payments", modulePin.IdentityString())
+	assert.Equal(t, "main", modulePin.Branch())
+	assert.Equal(t, bufmoduletesting.TestCommit, modulePin.Commit())
+	assert.Equal(t, bufmoduletesting.TestDigest, modulePin.Digest())
+	assert.True(t, createTime.Equal(modulePin.CreateTime()))
+	// the values are validated the same way as the proto ModulePin
+	protoModulePin, err := bufmodule.NewModulePinForProto(bufmodule.NewProtoModulePinForModulePin(modulePin))
+	require.NoError(t, err)
+	assert.Equal(t, modulePin, protoModulePin)
+
+	for _, values := range [][6]string{
+		{"", "acme", "payments", "main", bufmoduletesting.TestCommit, bufmoduletesting.TestDigest},
+		{"bsr.acme.com", "", "payments", "main", bufmoduletesting.TestCommit, bufmoduletesting.TestDigest},
+		{"bsr.acme.com", "acme", "", "main", bufmoduletesting.TestCommit, bufmoduletesting.TestDigest},
+		{"bsr.acme.com", "acme", "payments", "", bufmoduletesting.TestCommit, bufmoduletesting.TestDigest},
+		{"bsr.acme.com", "acme", "payments", "main", "", bufmoduletesting.TestDigest},
+		{"bsr.acme.com", "acme", "payments", "main", bufmoduletesting.TestCommit, "foo"},
+	} {
+		_, err := bufmodule.NewModulePin(values[0], values[1], values[2], values[3], values[4], values[5], createTime)
+		assert.Error(t, err, values)
+	}
+}
+
+func testNewModulePinWithCreateTime(t *testing.T, repository string, createTime time.Time) bufmodule.ModulePin {
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		repository,
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		createTime,
+	)
+	require.NoError(t, err)
+	return modulePin
+}
@@ -23,6 +23,8 @@ type moduleReference struct {
 	owner      string
 	repository string
 	reference  string
+	// constraint is nil if the reference is not a version constraint.
+	constraint *versionConstraint
 }
 
 func newModuleReference(
@@ -46,11 +48,22 @@ func newModuleReferenceForProto(
 	if err := ValidateProtoModuleReference(protoModuleReference); err != nil {
 		return nil, err
 	}
+	reference := protoModuleReference.Reference
+	var constraint *versionConstraint
+	if isVersionConstraintString(reference) {
+		var err error
+		constraint, err = newVersionConstraint(reference)
+		if err != nil {
+			return nil, err
+		}
+		reference = constraint.String()
+	}
 	return &moduleReference{
 		remote:     protoModuleReference.Remote,
 		owner:      protoModuleReference.Owner,
 		repository: protoModuleReference.Repository,
-		reference:  protoModuleReference.Reference,
+		reference:  reference,
+		constraint: constraint,
 	}, nil
 }
 
@@ -83,6 +96,14 @@ func (m *moduleReference) Reference() string {
 	return m.reference
 }
 
+func (m *moduleReference) Constraint() VersionConstraint {
+	// we need to return a nil interface and not a typed nil
+	if m.constraint == nil {
+		return nil
+	}
+	return m.constraint
+}
+
 func (m *moduleReference) String() string {
 	return m.remote + "/" + m.owner + "/" + m.repository + ":" + m.reference
 }
@@ -93,3 +93,40 @@ func TestModuleReferenceForStringError(t *testing.T) {
 		})
 	}
 }
+
+func TestSortModuleReferences(t *testing.T) {
+	t.Parallel()
+	var moduleReferences []ModuleReference
+	for _, moduleReferenceString := range []string{
+		"foo.com/barr/baz:v2",
+		"bar.com/barr/baz:main",
+		"foo.com/barr/baz:v1",
+		"foo.com/aaa/zzz:main",
+		"foo.com/barr/bat:main",
+	} {
+		moduleReference, err := ModuleReferenceForString(moduleReferenceString)
+		require.NoError(t, err)
+		moduleReferences = append(moduleReferences, moduleReference)
+	}
+	SortModuleReferences(moduleReferences)
+	moduleReferenceStrings := make([]string, len(moduleReferences))
+	for i, moduleReference := range moduleReferences {
+		moduleReferenceStrings[i] = moduleReference.String()
+	}
+	require.Equal(
+		t,
+		[]string{
+			"bar.com/barr/baz:main",
+			"foo.com/aaa/zzz:main",
+			"foo.com/barr/bat:main",
+			"foo.com/barr/baz:v1",
+			"foo.com/barr/baz:v2",
+		},
+		moduleReferenceStrings,
+	)
+	require.Equal(t, -1, CompareModuleReferences(moduleReferences[3], moduleReferences[4]))
+	require.Equal(t, 1, CompareModuleReferences(moduleReferences[4], moduleReferences[3]))
+	require.Equal(t, 0, CompareModuleReferences(moduleReferences[0], moduleReferences[0]))
+	require.Equal(t, -1, CompareModuleReferences(nil, moduleReferences[0]))
+	require.Equal(t, 0, CompareModuleReferences(nil, nil))
+}
@@ -0,0 +1,591 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/bufmodule/bufmoduletesting"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestModuleWithLockFilePath(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	readBucketBuilder := storagemem.NewReadBucketBuilder()
+	require.NoError(t, storage.PutPath(ctx, readBucketBuilder, "a.proto", []byte(`syntax = "proto3";`)))
+	require.NoError(
+		t,
+		bufmodule.PutModuleDependencyModulePinsToBucket(
+			ctx,
+			readBucketBuilder,
+			[]bufmodule.ModulePin{modulePin},
+			bufmodule.PutModuleDependencyModulePinsWithLockFilePath("locks/deps.lock"),
+		),
+	)
+	readBucket, err := readBucketBuilder.ToReadBucket()
+	require.NoError(t, err)
+
+	module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Empty(t, module.DependencyModulePins())
+
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithLockFilePath("locks/deps.lock"))
+	require.NoError(t, err)
+	require.Len(t, module.DependencyModulePins(), 1)
+	assert.True(t, bufmodule.ModulePinEqual(modulePin, module.DependencyModulePins()[0]))
+
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithLockFilePath("locks/missing.lock"))
+	require.NoError(t, err)
+	assert.Empty(t, module.DependencyModulePins())
+}
+
+func TestModuleWithDocumentation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto":                       []byte(`syntax = "proto3";`),
+			bufmodule.DocumentationFilePath: []byte("# from file"),
+		},
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	assert.Equal(t, "# from file", module.Documentation())
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithDocumentation("# from option"))
+	require.NoError(t, err)
+	assert.Equal(t, "# from option", module.Documentation())
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithDocumentation(""))
+	require.NoError(t, err)
+	assert.Empty(t, module.Documentation())
+
+	readBucket, err = storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithDocumentation("# from option"))
+	require.NoError(t, err)
+	assert.Equal(t, "# from option", module.Documentation())
+}
+
+func TestNewModuleForFiles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto":   []byte(`syntax = "proto3";`),
+			"b/b.proto": []byte(`syntax = "proto3";`),
+		},
+		bufmodule.ModuleWithDocumentation("# docs"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "# docs", module.Documentation())
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, sourceFileInfos, 2)
+	assert.Equal(t, "a.proto", sourceFileInfos[0].Path())
+	assert.Equal(t, "b/b.proto", sourceFileInfos[1].Path())
+	module, err = bufmodule.ModuleWithTargetPaths(module, []string{"b"})
+	require.NoError(t, err)
+	targetFileInfos, err := module.TargetFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, targetFileInfos, 1)
+	assert.Equal(t, "b/b.proto", targetFileInfos[0].Path())
+
+	_, err = bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"": []byte(`syntax = "proto3";`),
+		},
+	)
+	assert.Error(t, err)
+}
+
+func TestModuleWithImportPaths(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto":                         []byte(`syntax = "proto3";`),
+			"google/protobuf/any.proto":       []byte(`syntax = "proto3";`),
+			"google/protobuf/timestamp.proto": []byte(`syntax = "proto3";`),
+			"vendor.proto":                    []byte(`syntax = "proto3";`),
+		},
+		bufmodule.ModuleWithImportPaths([]string{"google/protobuf", "./vendor.proto"}),
+	)
+	require.NoError(t, err)
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, sourceFileInfos, 4)
+	pathToIsImport := make(map[string]bool)
+	for _, sourceFileInfo := range sourceFileInfos {
+		pathToIsImport[sourceFileInfo.Path()] = sourceFileInfo.IsImport()
+	}
+	assert.Equal(
+		t,
+		map[string]bool{
+			"a.proto":                         false,
+			"google/protobuf/any.proto":       true,
+			"google/protobuf/timestamp.proto": true,
+			"vendor.proto":                    true,
+		},
+		pathToIsImport,
+	)
+	moduleFile, err := module.GetModuleFile(ctx, "google/protobuf/any.proto")
+	require.NoError(t, err)
+	assert.True(t, moduleFile.IsImport())
+	require.NoError(t, moduleFile.Close())
+	moduleFile, err = module.GetModuleFile(ctx, "a.proto")
+	require.NoError(t, err)
+	assert.False(t, moduleFile.IsImport())
+	require.NoError(t, moduleFile.Close())
+	targetModule, err := bufmodule.ModuleWithTargetPaths(module, []string{"google"})
+	require.NoError(t, err)
+	targetFileInfos, err := targetModule.TargetFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, targetFileInfos, 2)
+	assert.True(t, targetFileInfos[0].IsImport())
+
+	_, err = bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto": []byte(`syntax = "proto3";`),
+		},
+		bufmodule.ModuleWithImportPaths([]string{"../a"}),
+	)
+	assert.Error(t, err)
+}
+
+func TestModuleWithDirectoryDocumentation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pathToData := map[string][]byte{
+		"buf.md":      []byte("# root"),
+		"a/a.proto":   []byte(`syntax = "proto3";`),
+		"a/buf.md":    []byte("# a"),
+		"a/b/buf.md":  []byte("# b"),
+		"c/c.proto":   []byte(`syntax = "proto3";`),
+		"c/README.md": []byte("# not documentation"),
+	}
+	module, err := bufmodule.NewModuleForFiles(ctx, pathToData, bufmodule.ModuleWithDirectoryDocumentation())
+	require.NoError(t, err)
+	assert.Equal(t, "# root", module.Documentation())
+	assert.Equal(t, "# root", module.DocumentationForPath("."))
+	assert.Equal(t, "# a", module.DocumentationForPath("a"))
+	assert.Equal(t, "# b", module.DocumentationForPath("a/b/"))
+	assert.Empty(t, module.DocumentationForPath("c"))
+	assert.Empty(t, module.DocumentationForPath("d"))
+
+	module, err = bufmodule.NewModuleForFiles(ctx, pathToData)
+	require.NoError(t, err)
+	assert.Equal(t, "# root", module.DocumentationForPath("."))
+	assert.Empty(t, module.DocumentationForPath("a"))
+}
+
+func TestModuleWithDocumentationFileNames(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	pathToData := map[string][]byte{
+		"buf.md":       []byte("# root"),
+		"README.md":    []byte("# root readme"),
+		"a/a.proto":    []byte(`syntax = "proto3";`),
+		"a/buf.md":     []byte("# a"),
+		"a/README.md":  []byte("# a readme"),
+		"b/README.md":  []byte("# b readme"),
+		"c/c.proto":    []byte(`syntax = "proto3";`),
+		"c/guide.md":   []byte("# c guide"),
+		"c/example.md": []byte("# c example"),
+		"d/d.proto":    []byte(`syntax = "proto3";`),
+		"d/notes.txt":  []byte("not documentation"),
+	}
+	module, err := bufmodule.NewModuleForFiles(
+		ctx,
+		pathToData,
+		bufmodule.ModuleWithDocumentationFileNames("README.md", "*.md"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "# root", module.Documentation())
+	assert.Equal(t, "# root", module.DocumentationForPath("."))
+	// README.md is listed before *.md
+	assert.Equal(t, "# a readme", module.DocumentationForPath("a"))
+	assert.Equal(t, "# b readme", module.DocumentationForPath("b"))
+	// the file that sorts first is used for the same file name
+	assert.Equal(t, "# c example", module.DocumentationForPath("c"))
+	assert.Empty(t, module.DocumentationForPath("d"))
+
+	_, err = bufmodule.NewModuleForFiles(
+		ctx,
+		pathToData,
+		bufmodule.ModuleWithDocumentationFileNames("[.md"),
+	)
+	assert.Error(t, err)
+}
+
+func TestModuleAllPathsAndContainsPath(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"b/b.proto":   []byte(`syntax = "proto3";`),
+			"a.proto":     []byte(`syntax = "proto3";`),
+			"c/c.proto":   []byte(`syntax = "proto3";`),
+			"c/README.md": []byte(`# c`),
+		},
+		bufmodule.ModuleWithExcludeGlobs([]string{"c/**"}),
+	)
+	require.NoError(t, err)
+	paths, err := module.AllPaths(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.proto", "b/b.proto"}, paths)
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, sourceFileInfos, len(paths))
+	for i, sourceFileInfo := range sourceFileInfos {
+		assert.Equal(t, sourceFileInfo.Path(), paths[i])
+	}
+
+	contains, err := module.ContainsPath(ctx, "b/b.proto")
+	require.NoError(t, err)
+	assert.True(t, contains)
+	for _, path := range []string{"b.proto", "c/c.proto", "c/README.md", "b"} {
+		contains, err = module.ContainsPath(ctx, path)
+		require.NoError(t, err, path)
+		assert.False(t, contains, path)
+	}
+	for _, path := range []string{"", "/a.proto", "../a.proto"} {
+		_, err = module.ContainsPath(ctx, path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestModuleWithIgnoreFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForFiles(
+		ctx,
+		map[string][]byte{
+			"a.proto":                []byte(`syntax = "proto3";`),
+			"examples/b.proto":       []byte(`syntax = "proto3";`),
+			"examples/keep.proto":    []byte(`syntax = "proto3";`),
+			"c/generated.proto":      []byte(`syntax = "proto3";`),
+			"c/keep.proto":           []byte(`syntax = "proto3";`),
+			bufmodule.IgnoreFilePath: []byte("examples/\nc/*.proto\n!c/keep.proto\n"),
+		},
+	)
+	require.NoError(t, err)
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	paths := make([]string, len(sourceFileInfos))
+	for i, sourceFileInfo := range sourceFileInfos {
+		paths[i] = sourceFileInfo.Path()
+	}
+	assert.Equal(t, []string{"a.proto", "c/keep.proto"}, paths)
+	_, err = module.GetModuleFile(ctx, "examples/b.proto")
+	assert.True(t, storage.IsNotExist(err))
+	_, err = module.GetModuleFile(ctx, "c/generated.proto")
+	assert.True(t, storage.IsNotExist(err))
+	moduleFile, err := module.GetModuleFile(ctx, "c/keep.proto")
+	require.NoError(t, err)
+	assert.NoError(t, moduleFile.Close())
+}
+
+func TestModuleWalkSourceFileInfos(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"b/b.proto":                     []byte(`syntax = "proto3";`),
+			"a.proto":                       []byte(`syntax = "proto3";`),
+			"a/a.proto":                     []byte(`syntax = "proto3";`),
+			bufmodule.DocumentationFilePath: []byte("# docs"),
+		},
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	var paths []string
+	require.NoError(
+		t,
+		module.WalkSourceFileInfos(ctx, func(fileInfo bufmodule.FileInfo) error {
+			paths = append(paths, fileInfo.Path())
+			return nil
+		}),
+	)
+	assert.ElementsMatch(t, []string{"a.proto", "a/a.proto", "b/b.proto"}, paths)
+	paths = nil
+	require.NoError(
+		t,
+		module.WalkSourceFileInfos(
+			ctx,
+			func(fileInfo bufmodule.FileInfo) error {
+				paths = append(paths, fileInfo.Path())
+				return nil
+			},
+			bufmodule.WalkSourceFileInfosWithSorted(),
+		),
+	)
+	assert.Equal(t, []string{"a.proto", "a/a.proto", "b/b.proto"}, paths)
+	// errors from f are returned as-is and stop the walk
+	stopErr := errors.New("stop")
+	calls := 0
+	err = module.WalkSourceFileInfos(ctx, func(bufmodule.FileInfo) error {
+		calls++
+		return stopErr
+	})
+	assert.Equal(t, stopErr, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestModuleWithTargetFilePaths(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readBucket, err := storagemem.NewReadBucket(
+		map[string][]byte{
+			"a.proto":   []byte(`syntax = "proto3";`),
+			"b/b.proto": []byte(`syntax = "proto3";`),
+			"c/c.proto": []byte(`syntax = "proto3";`),
+		},
+	)
+	require.NoError(t, err)
+	module, err := bufmodule.NewModuleForBucket(
+		ctx,
+		readBucket,
+		bufmodule.ModuleWithTargetFilePaths([]string{"c/c.proto", "./a.proto"}),
+	)
+	require.NoError(t, err)
+	targetFileInfos, err := module.TargetFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, targetFileInfos, 2)
+	assert.Equal(t, "a.proto", targetFileInfos[0].Path())
+	assert.Equal(t, "c/c.proto", targetFileInfos[1].Path())
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sourceFileInfos, 3)
+	_, err = module.GetModuleFile(ctx, "b/b.proto")
+	require.NoError(t, err)
+
+	_, err = bufmodule.NewModuleForBucket(
+		ctx,
+		readBucket,
+		bufmodule.ModuleWithTargetFilePaths([]string{"a.proto", "missing.proto"}),
+	)
+	require.Error(t, err)
+	assert.True(t, storage.IsNotExist(err))
+	_, err = bufmodule.NewModuleForBucket(
+		ctx,
+		readBucket,
+		bufmodule.ModuleWithTargetFilePaths([]string{""}),
+	)
+	require.Error(t, err)
+}
+
+func TestPutModuleDependencyModulePinsWithDigestAlgorithm(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dependencyModule, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "dep/dep.proto",
+					Content: []byte(`syntax = "proto3"; package dep;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	b2Digest, err := bufmodule.ModuleDigestB2(ctx, dependencyModule)
+	require.NoError(t, err)
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	moduleReader := &testModuleReader{module: dependencyModule}
+
+	testGetWrittenDigest := func(digestAlgorithm bufmodule.DigestAlgorithm) string {
+		readBucketBuilder := storagemem.NewReadBucketBuilder()
+		require.NoError(
+			t,
+			bufmodule.PutModuleDependencyModulePinsToBucket(
+				ctx,
+				readBucketBuilder,
+				[]bufmodule.ModulePin{modulePin},
+				bufmodule.PutModuleDependencyModulePinsWithDigestAlgorithm(digestAlgorithm, moduleReader),
+			),
+		)
+		readBucket, err := readBucketBuilder.ToReadBucket()
+		require.NoError(t, err)
+		module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+		require.NoError(t, err)
+		require.Len(t, module.DependencyModulePins(), 1)
+		return module.DependencyModulePins()[0].Digest()
+	}
+	assert.Equal(t, bufmoduletesting.TestDigest, testGetWrittenDigest(bufmodule.DigestAlgorithmB1))
+	assert.Equal(t, b2Digest, testGetWrittenDigest(bufmodule.DigestAlgorithmB2))
+}
+
+func TestParseDigestAlgorithm(t *testing.T) {
+	t.Parallel()
+	digestAlgorithm, err := bufmodule.ParseDigestAlgorithm("")
+	require.NoError(t, err)
+	assert.Equal(t, bufmodule.DigestAlgorithmB1, digestAlgorithm)
+	digestAlgorithm, err = bufmodule.ParseDigestAlgorithm("b2")
+	require.NoError(t, err)
+	assert.Equal(t, bufmodule.DigestAlgorithmB2, digestAlgorithm)
+	_, err = bufmodule.ParseDigestAlgorithm("sha1")
+	require.Error(t, err)
+}
+
+func TestModuleToProtoModuleRoundTrip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	modulePin, err := bufmodule.NewModulePin(
+		"buf.build",
+		"foob",
+		"bar",
+		"main",
+		bufmoduletesting.TestCommit,
+		bufmoduletesting.TestDigest,
+		time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	protoModule := &modulev1alpha1.Module{
+		Files: []*modulev1alpha1.ModuleFile{
+			{
+				Path:    "a/a.proto",
+				Content: []byte(`syntax = "proto3"; package a;`),
+			},
+			{
+				Path:    "b/b.proto",
+				Content: []byte(`syntax = "proto3"; package b;`),
+			},
+		},
+		Dependencies:  []*modulev1alpha1.ModulePin{bufmodule.NewProtoModulePinForModulePin(modulePin)},
+		Documentation: "# Documentation",
+	}
+	module, err := bufmodule.NewModuleForProto(ctx, protoModule)
+	require.NoError(t, err)
+	roundTripProtoModule, err := bufmodule.ModuleToProtoModule(ctx, module)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(protoModule, roundTripProtoModule))
+	roundTripModule, err := bufmodule.NewModuleForProto(ctx, roundTripProtoModule)
+	require.NoError(t, err)
+	secondRoundTripProtoModule, err := bufmodule.ModuleToProtoModule(ctx, roundTripModule)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(roundTripProtoModule, secondRoundTripProtoModule))
+}
+
+type testModuleReader struct {
+	module bufmodule.Module
+}
+
+func (r *testModuleReader) GetModule(context.Context, bufmodule.ModulePin) (bufmodule.Module, error) {
+	return r.module, nil
+}
+
+func TestModuleWithSymlinkPolicy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip symlink tests on Windows")
+	}
+	t.Parallel()
+	ctx := context.Background()
+	tmpDirPath := t.TempDir()
+	sharedDirPath := filepath.Join(tmpDirPath, "shared")
+	moduleDirPath := filepath.Join(tmpDirPath, "module")
+	require.NoError(t, os.MkdirAll(sharedDirPath, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDirPath, "a"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(moduleDirPath, "b"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedDirPath, "shared.proto"), []byte(`syntax = "proto3";`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDirPath, "a", "a.proto"), []byte(`syntax = "proto3";`), 0600))
+	// b/a.proto is a second path to a/a.proto
+	require.NoError(t, os.Symlink(filepath.Join("..", "a", "a.proto"), filepath.Join(moduleDirPath, "b", "a.proto")))
+	require.NoError(t, os.Symlink(filepath.Join("..", "shared", "shared.proto"), filepath.Join(moduleDirPath, "shared.proto")))
+	readBucket, err := storageos.NewProvider(storageos.ProviderWithSymlinks()).NewReadWriteBucket(
+		moduleDirPath,
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	require.NoError(t, err)
+
+	module, err := bufmodule.NewModuleForBucket(ctx, readBucket)
+	require.NoError(t, err)
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	// the bucket only walks each target once
+	require.Len(t, sourceFileInfos, 2)
+	for _, sourceFileInfo := range sourceFileInfos {
+		assert.False(t, sourceFileInfo.IsSymlink())
+	}
+
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithSymlinkPolicy(bufmodule.SymlinkPolicyResolve))
+	require.NoError(t, err)
+	sourceFileInfos, err = module.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, sourceFileInfos, 2)
+	assert.Equal(t, "a/a.proto", sourceFileInfos[0].Path())
+	assert.False(t, sourceFileInfos[0].IsSymlink())
+	assert.Equal(t, "shared.proto", sourceFileInfos[1].Path())
+	assert.True(t, sourceFileInfos[1].IsSymlink())
+	assert.True(t, sourceFileInfos[1].WithIsImport(true).IsSymlink())
+	moduleFile, err := module.GetModuleFile(ctx, "b/a.proto")
+	require.NoError(t, err)
+	assert.True(t, moduleFile.IsSymlink())
+	require.NoError(t, moduleFile.Close())
+
+	module, err = bufmodule.NewModuleForBucket(ctx, readBucket, bufmodule.ModuleWithSymlinkPolicy(bufmodule.SymlinkPolicyReject))
+	require.NoError(t, err)
+	_, err = module.SourceFileInfos(ctx)
+	require.Error(t, err)
+	_, err = module.GetModuleFile(ctx, "shared.proto")
+	require.Error(t, err)
+	moduleFile, err = module.GetModuleFile(ctx, "a/a.proto")
+	require.NoError(t, err)
+	assert.False(t, moduleFile.IsSymlink())
+	require.NoError(t, moduleFile.Close())
+}
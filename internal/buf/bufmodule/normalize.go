@@ -0,0 +1,82 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func normalizeReferenceString(s string, options ...NormalizeOption) (string, error) {
+	normalizeOptions := newNormalizeOptions()
+	for _, option := range options {
+		option(normalizeOptions)
+	}
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return "", errors.New("module reference is empty")
+	}
+	identityString := trimmed
+	var reference string
+	// a colon before the last slash is part of the remote, such as a port
+	if index := strings.LastIndex(trimmed, ":"); index > strings.LastIndex(trimmed, "/") {
+		identityString = trimmed[:index]
+		reference = strings.TrimSpace(trimmed[index+1:])
+		if reference == "" {
+			return "", fmt.Errorf("module reference %q has an empty reference after %q", s, ":")
+		}
+	}
+	components := strings.Split(identityString, "/")
+	switch len(components) {
+	case 3:
+	case 2:
+		if normalizeOptions.defaultRemote == "" {
+			return "", fmt.Errorf("module reference %q has no remote, expected the form remote/owner/repository[:reference]", s)
+		}
+		components = append([]string{normalizeOptions.defaultRemote}, components...)
+	default:
+		return "", fmt.Errorf("module reference %q must be of the form remote/owner/repository[:reference]", s)
+	}
+	for i, name := range []string{"remote", "owner", "repository"} {
+		components[i] = strings.ToLower(strings.TrimSpace(components[i]))
+		if components[i] == "" {
+			return "", fmt.Errorf("module reference %q has an empty %s", s, name)
+		}
+	}
+	switch {
+	case reference == "":
+		reference = MainBranch
+	case IsCommitReference(reference):
+		reference = strings.ToLower(reference)
+	}
+	moduleReference, err := NewModuleReference(components[0], components[1], components[2], reference)
+	if err != nil {
+		return "", fmt.Errorf("module reference %q: %w", s, err)
+	}
+	if normalizeOptions.withoutMainBranch && moduleReference.Reference() == MainBranch {
+		return moduleReference.IdentityString(), nil
+	}
+	return moduleReference.String(), nil
+}
+
+type normalizeOptions struct {
+	defaultRemote     string
+	withoutMainBranch bool
+}
+
+func newNormalizeOptions() *normalizeOptions {
+	return &normalizeOptions{}
+}
@@ -0,0 +1,82 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeReferenceString(t *testing.T) {
+	t.Parallel()
+	testNormalizeReferenceString(t, "buf.build/acme/weather:main", "buf.build/acme/weather")
+	testNormalizeReferenceString(t, "buf.build/acme/weather:main", " Buf.Build/ACME/Weather ")
+	testNormalizeReferenceString(t, "buf.build/acme/weather:Dev", "buf.build/Acme/weather:Dev")
+	testNormalizeReferenceString(
+		t,
+		"buf.build/acme/weather:7e8b594e68324329a7aefc6e750d18b9",
+		"buf.build/acme/weather:7E8B594E68324329A7AEFC6E750D18B9",
+	)
+	testNormalizeReferenceString(t, "bsr.internal.acme.com:8443/acme/weather:v1", "BSR.internal.acme.com:8443/acme/weather:v1")
+	testNormalizeReferenceString(
+		t,
+		"buf.build/acme/weather:main",
+		"Acme/Weather",
+		bufmodule.NormalizeWithDefaultRemote("buf.build"),
+	)
+	testNormalizeReferenceString(
+		t,
+		"buf.build/acme/weather",
+		"acme/weather:main",
+		bufmodule.NormalizeWithDefaultRemote("buf.build"),
+		bufmodule.NormalizeWithoutMainBranch(),
+	)
+	testNormalizeReferenceString(
+		t,
+		"buf.build/acme/weather:v1",
+		"buf.build/acme/weather:v1",
+		bufmodule.NormalizeWithoutMainBranch(),
+	)
+}
+
+func TestNormalizeReferenceStringError(t *testing.T) {
+	t.Parallel()
+	testNormalizeReferenceStringError(t, "", "empty")
+	testNormalizeReferenceStringError(t, "acme/weather", "has no remote")
+	testNormalizeReferenceStringError(t, "buf.build/acme/weather:", "empty reference")
+	testNormalizeReferenceStringError(t, "buf.build//weather", "empty owner")
+	testNormalizeReferenceStringError(t, "buf.build/acme/weather/foo", "must be of the form")
+	testNormalizeReferenceStringError(t, "buf.build:http/acme/weather", "invalid port")
+}
+
+func testNormalizeReferenceString(
+	t *testing.T,
+	expected string,
+	s string,
+	options ...bufmodule.NormalizeOption,
+) {
+	normalized, err := bufmodule.NormalizeReferenceString(s, options...)
+	require.NoError(t, err)
+	assert.Equal(t, expected, normalized)
+}
+
+func testNormalizeReferenceStringError(t *testing.T, s string, expectedErrorContains string) {
+	_, err := bufmodule.NormalizeReferenceString(s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), expectedErrorContains)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+)
+
+func validatePackageDirectoryConsistency(ctx context.Context, module Module) ([]Inconsistency, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var inconsistencies []Inconsistency
+	for _, parsedSourceFile := range parsedSourceFiles {
+		pkg := parsedSourceFile.fileDescriptorProto.GetPackage()
+		dirPath := normalpath.Dir(parsedSourceFile.fileInfo.Path())
+		expectedDirPath := "."
+		if pkg != "" {
+			expectedDirPath = normalpath.Join(strings.Split(pkg, ".")...)
+		}
+		if dirPath != expectedDirPath {
+			inconsistencies = append(
+				inconsistencies,
+				Inconsistency{
+					FileInfo: parsedSourceFile.fileInfo,
+					Message:  fmt.Sprintf("package %q is expected to be in directory %q but is in directory %q", pkg, expectedDirPath, dirPath),
+				},
+			)
+		}
+	}
+	return inconsistencies, nil
+}
+
+func modulePackages(ctx context.Context, module Module) ([]string, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	packages := make([]string, 0, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		packages = append(packages, parsedSourceFile.fileDescriptorProto.GetPackage())
+	}
+	return stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(packages), nil
+}
+
+func validateRequiredPackages(ctx context.Context, module Module, required []string) ([]string, error) {
+	packages, err := modulePackages(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	packageMap := stringutil.SliceToMap(packages)
+	var missingPackages []string
+	for _, requiredPackage := range stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(required) {
+		if _, ok := packageMap[requiredPackage]; !ok {
+			missingPackages = append(missingPackages, requiredPackage)
+		}
+	}
+	return missingPackages, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePackageDirectoryConsistency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "root.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+				{
+					Path:    "foo/bar/v1/a.proto",
+					Content: []byte(`syntax = "proto3"; package foo.bar.v1;`),
+				},
+				{
+					Path:    "foo/bar/v1/b.proto",
+					Content: []byte(`syntax = "proto3"; package foo.baz.v1;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	inconsistencies, err := bufmodule.ValidatePackageDirectoryConsistency(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, inconsistencies, 1)
+	assert.Equal(t, "foo/bar/v1/b.proto", inconsistencies[0].FileInfo.Path())
+	assert.Contains(t, inconsistencies[0].Message, `"foo/baz/v1"`)
+}
+
+func TestValidateRequiredPackages(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "root.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+				{
+					Path:    "foo/v1/a.proto",
+					Content: []byte(`syntax = "proto3"; package foo.v1;`),
+				},
+				{
+					Path:    "foo/v1/b.proto",
+					Content: []byte(`syntax = "proto3"; package foo.v1;`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	packages, err := bufmodule.ModulePackages(ctx, module)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo.v1"}, packages)
+	missingPackages, err := bufmodule.ValidateRequiredPackages(ctx, module, []string{"foo.v1", "common.types.v1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"common.types.v1"}, missingPackages)
+	missingPackages, err = bufmodule.ValidateRequiredPackages(ctx, module, []string{"foo.v1"})
+	require.NoError(t, err)
+	assert.Empty(t, missingPackages)
+}
@@ -0,0 +1,141 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"io"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// parsedSourceFile is a source file of a Module along with its unlinked FileDescriptorProto.
+type parsedSourceFile struct {
+	fileInfo            FileInfo
+	fileDescriptorProto *descriptorpb.FileDescriptorProto
+}
+
+// parseSourceFiles parses the source files of the Module.
+//
+// The files are not linked, so imports are not resolved and type references
+// are left as they appear in the source. This is enough to inspect
+// declarations without needing the dependencies of the Module.
+//
+// The returned parsedSourceFiles are sorted by path.
+func parseSourceFiles(ctx context.Context, module Module) ([]*parsedSourceFile, error) {
+	sourceFileInfos, err := module.SourceFileInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sourceFileInfos) == 0 {
+		return nil, nil
+	}
+	paths := make([]string, len(sourceFileInfos))
+	for i, sourceFileInfo := range sourceFileInfos {
+		paths[i] = sourceFileInfo.Path()
+	}
+	parser := protoparse.Parser{
+		Accessor: func(path string) (io.ReadCloser, error) {
+			return module.GetModuleFile(ctx, path)
+		},
+		IncludeSourceCodeInfo: true,
+	}
+	fileDescriptorProtos, err := parser.ParseFilesButDoNotLink(paths...)
+	if err != nil {
+		return nil, err
+	}
+	parsedSourceFiles := make([]*parsedSourceFile, len(sourceFileInfos))
+	for i, sourceFileInfo := range sourceFileInfos {
+		parsedSourceFiles[i] = &parsedSourceFile{
+			fileInfo:            sourceFileInfo,
+			fileDescriptorProto: fileDescriptorProtos[i],
+		}
+	}
+	return parsedSourceFiles, nil
+}
+
+// forEachSymbol calls f for every message, enum, and service declared
+// in the file, including nested messages and enums.
+//
+// Names are fully-qualified without a leading dot.
+func forEachSymbol(
+	fileDescriptorProto *descriptorpb.FileDescriptorProto,
+	f func(fullName string),
+) {
+	forEachMessage(fileDescriptorProto, func(fullName string, _ *descriptorpb.DescriptorProto) {
+		f(fullName)
+	})
+	forEachEnum(fileDescriptorProto, func(fullName string, _ *descriptorpb.EnumDescriptorProto) {
+		f(fullName)
+	})
+	for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+		f(joinSymbolName(fileDescriptorProto.GetPackage(), serviceDescriptorProto.GetName()))
+	}
+}
+
+// forEachMessage calls f for every message declared in the file, including
+// nested messages.
+//
+// The synthetic map entry messages generated for map fields are skipped.
+// Names are fully-qualified without a leading dot.
+func forEachMessage(
+	fileDescriptorProto *descriptorpb.FileDescriptorProto,
+	f func(fullName string, descriptorProto *descriptorpb.DescriptorProto),
+) {
+	for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+		forEachNestedMessage(fileDescriptorProto.GetPackage(), descriptorProto, f)
+	}
+}
+
+func forEachNestedMessage(
+	prefix string,
+	descriptorProto *descriptorpb.DescriptorProto,
+	f func(fullName string, descriptorProto *descriptorpb.DescriptorProto),
+) {
+	if descriptorProto.GetOptions().GetMapEntry() {
+		return
+	}
+	fullName := joinSymbolName(prefix, descriptorProto.GetName())
+	f(fullName, descriptorProto)
+	for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		forEachNestedMessage(fullName, nestedDescriptorProto, f)
+	}
+}
+
+// forEachEnum calls f for every enum declared in the file, including
+// enums nested in messages.
+//
+// Names are fully-qualified without a leading dot.
+func forEachEnum(
+	fileDescriptorProto *descriptorpb.FileDescriptorProto,
+	f func(fullName string, enumDescriptorProto *descriptorpb.EnumDescriptorProto),
+) {
+	for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+		f(joinSymbolName(fileDescriptorProto.GetPackage(), enumDescriptorProto.GetName()), enumDescriptorProto)
+	}
+	forEachMessage(fileDescriptorProto, func(messageFullName string, descriptorProto *descriptorpb.DescriptorProto) {
+		for _, enumDescriptorProto := range descriptorProto.GetEnumType() {
+			f(joinSymbolName(messageFullName, enumDescriptorProto.GetName()), enumDescriptorProto)
+		}
+	})
+}
+
+func joinSymbolName(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,86 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func validateFieldPresenceConsistency(ctx context.Context, module Module) ([]Inconsistency, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	var inconsistencies []Inconsistency
+	for _, parsedSourceFile := range parsedSourceFiles {
+		if parsedSourceFile.fileDescriptorProto.GetSyntax() != "proto3" {
+			continue
+		}
+		forEachMessage(
+			parsedSourceFile.fileDescriptorProto,
+			func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+				var explicitFieldNames []string
+				var implicitFieldNames []string
+				for _, fieldDescriptorProto := range descriptorProto.GetField() {
+					if fieldDescriptorProto.GetProto3Optional() {
+						explicitFieldNames = append(explicitFieldNames, fieldDescriptorProto.GetName())
+					} else if fieldHasImplicitPresence(fieldDescriptorProto) {
+						implicitFieldNames = append(implicitFieldNames, fieldDescriptorProto.GetName())
+					}
+				}
+				if len(explicitFieldNames) > 0 && len(implicitFieldNames) > 0 {
+					inconsistencies = append(
+						inconsistencies,
+						Inconsistency{
+							FileInfo: parsedSourceFile.fileInfo,
+							Message: fmt.Sprintf(
+								"message %q mixes optional fields %v with fields without presence %v",
+								fullName,
+								explicitFieldNames,
+								implicitFieldNames,
+							),
+						},
+					)
+				}
+			},
+		)
+	}
+	return inconsistencies, nil
+}
+
+// fieldHasImplicitPresence returns true if the proto3 field is a singular scalar
+// field that could have been declared optional but was not.
+func fieldHasImplicitPresence(fieldDescriptorProto *descriptorpb.FieldDescriptorProto) bool {
+	if fieldDescriptorProto.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+	if fieldDescriptorProto.OneofIndex != nil {
+		return false
+	}
+	// the type of a field that references a message or an enum is not
+	// resolved when parsing, and we cannot tell which of the two it is
+	if fieldDescriptorProto.Type == nil {
+		return false
+	}
+	switch fieldDescriptorProto.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFieldPresenceConsistency(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+message Mixed {
+  optional string one = 1;
+  int32 two = 2;
+  repeated string three = 3;
+}
+message AllOptional {
+  optional string one = 1;
+  optional int32 two = 2;
+  repeated string three = 3;
+  oneof choice {
+    string four = 4;
+  }
+  Mixed five = 5;
+}
+message NoneOptional {
+  string one = 1;
+  int32 two = 2;
+}
+`),
+				},
+				{
+					Path: "b.proto",
+					Content: []byte(`syntax = "proto2";
+package b;
+message Proto2 {
+  optional string one = 1;
+  required int32 two = 2;
+}
+`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	inconsistencies, err := bufmodule.ValidateFieldPresenceConsistency(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, inconsistencies, 1)
+	assert.Equal(t, "a.proto", inconsistencies[0].FileInfo.Path())
+	assert.Equal(t, `message "a.Mixed" mixes optional fields [one] with fields without presence [two]`, inconsistencies[0].Message)
+}
@@ -0,0 +1,54 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+)
+
+func remapModulePaths(
+	ctx context.Context,
+	module Module,
+	mapper func(string) (string, error),
+) (Module, error) {
+	protoModule, err := ModuleToProtoModule(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	mappedPathToPath := make(map[string]string, len(protoModule.Files))
+	for _, protoModuleFile := range protoModule.Files {
+		path := protoModuleFile.Path
+		mappedPath, err := mapper(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not remap %q: %w", path, err)
+		}
+		mappedPath, err = normalpath.NormalizeAndValidate(mappedPath)
+		if err != nil {
+			return nil, fmt.Errorf("%q was remapped to an invalid path: %w", path, err)
+		}
+		if normalpath.Ext(mappedPath) != ".proto" {
+			return nil, fmt.Errorf("%q was remapped to %q, which is not a .proto file", path, mappedPath)
+		}
+		if existingPath, ok := mappedPathToPath[mappedPath]; ok {
+			return nil, fmt.Errorf("%q and %q were both remapped to %q", existingPath, path, mappedPath)
+		}
+		mappedPathToPath[mappedPath] = path
+		protoModuleFile.Path = mappedPath
+	}
+	return NewModuleForProto(ctx, protoModule)
+}
@@ -0,0 +1,95 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemapModulePaths(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "v1/a/a.proto",
+					Content: []byte(`syntax = "proto3"; package a;`),
+				},
+				{
+					Path:    "v1/b/b.proto",
+					Content: []byte(`syntax = "proto3"; package b;`),
+				},
+			},
+			Documentation: "docs",
+		},
+	)
+	require.NoError(t, err)
+	remappedModule, err := bufmodule.RemapModulePaths(
+		ctx,
+		module,
+		func(path string) (string, error) {
+			return strings.TrimPrefix(path, "v1/"), nil
+		},
+	)
+	require.NoError(t, err)
+	sourceFileInfos, err := remappedModule.SourceFileInfos(ctx)
+	require.NoError(t, err)
+	require.Len(t, sourceFileInfos, 2)
+	assert.Equal(t, "a/a.proto", sourceFileInfos[0].Path())
+	assert.Equal(t, "b/b.proto", sourceFileInfos[1].Path())
+	assert.Equal(t, "docs", remappedModule.Documentation())
+	moduleFile, err := remappedModule.GetModuleFile(ctx, "a/a.proto")
+	require.NoError(t, err)
+	require.NoError(t, moduleFile.Close())
+}
+
+func TestRemapModulePathsCollision(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "v1/a.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+				{
+					Path:    "v2/a.proto",
+					Content: []byte(`syntax = "proto3";`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	_, err = bufmodule.RemapModulePaths(
+		ctx,
+		module,
+		func(path string) (string, error) {
+			return "a.proto", nil
+		},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"v1/a.proto" and "v2/a.proto" were both remapped to "a.proto"`)
+}
@@ -0,0 +1,53 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"sort"
+)
+
+func reverseImportIndex(ctx context.Context, module Module) (map[string][]string, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	return reverseImportIndexForParsedSourceFiles(parsedSourceFiles), nil
+}
+
+func reverseImportIndexForParsedSourceFiles(parsedSourceFiles []*parsedSourceFile) map[string][]string {
+	pathToImporters := make(map[string][]string, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		if _, ok := pathToImporters[parsedSourceFile.fileInfo.Path()]; !ok {
+			pathToImporters[parsedSourceFile.fileInfo.Path()] = []string{}
+		}
+	}
+	// parsedSourceFiles are sorted by path, so the importers are as well
+	for _, parsedSourceFile := range parsedSourceFiles {
+		seenImportPaths := make(map[string]struct{})
+		for _, importPath := range parsedSourceFile.fileDescriptorProto.GetDependency() {
+			if _, ok := seenImportPaths[importPath]; ok {
+				continue
+			}
+			seenImportPaths[importPath] = struct{}{}
+			pathToImporters[importPath] = append(pathToImporters[importPath], parsedSourceFile.fileInfo.Path())
+		}
+	}
+	for _, importers := range pathToImporters {
+		// just in case
+		sort.Strings(importers)
+	}
+	return pathToImporters
+}
@@ -0,0 +1,46 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/storage"
+)
+
+func fileForSymbol(ctx context.Context, module Module, symbol string) (FileInfo, error) {
+	symbol = strings.TrimPrefix(strings.TrimSpace(symbol), ".")
+	if symbol == "" {
+		return nil, errors.New("symbol is required")
+	}
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	for _, parsedSourceFile := range parsedSourceFiles {
+		found := false
+		forEachSymbol(parsedSourceFile.fileDescriptorProto, func(fullName string) {
+			if fullName == symbol {
+				found = true
+			}
+		})
+		if found {
+			return parsedSourceFile.fileInfo, nil
+		}
+	}
+	return nil, storage.NewErrNotExist(symbol)
+}
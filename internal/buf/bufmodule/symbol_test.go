@@ -0,0 +1,58 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileForSymbol(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "a/v1/a.proto",
+					Content: []byte(`syntax = "proto3"; package a.v1; message Foo { message Bar {} }`),
+				},
+				{
+					Path:    "b/v1/b.proto",
+					Content: []byte(`syntax = "proto3"; package b.v1; import "a/v1/a.proto"; message Foo { a.v1.Foo foo = 1; }`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	fileInfo, err := bufmodule.FileForSymbol(ctx, module, "a.v1.Foo")
+	require.NoError(t, err)
+	assert.Equal(t, "a/v1/a.proto", fileInfo.Path())
+	fileInfo, err = bufmodule.FileForSymbol(ctx, module, ".a.v1.Foo.Bar")
+	require.NoError(t, err)
+	assert.Equal(t, "a/v1/a.proto", fileInfo.Path())
+	fileInfo, err = bufmodule.FileForSymbol(ctx, module, "b.v1.Foo")
+	require.NoError(t, err)
+	assert.Equal(t, "b/v1/b.proto", fileInfo.Path())
+	_, err = bufmodule.FileForSymbol(ctx, module, "Foo")
+	assert.True(t, storage.IsNotExist(err))
+}
@@ -0,0 +1,69 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"fmt"
+
+	"github.com/bufbuild/buf/internal/pkg/storage"
+)
+
+// newSourceFileInfo returns a new FileInfo for the source file, applying the
+// SymlinkPolicy of the module.
+//
+// If resolvedExternalPaths is not nil, it contains the resolved external paths of the
+// files returned so far, and is used to skip duplicates with SymlinkPolicyResolve.
+// Returns false if the file should be skipped.
+func (m *module) newSourceFileInfo(
+	objectInfo storage.ObjectInfo,
+	resolvedExternalPaths map[string]struct{},
+) (FileInfo, bool, error) {
+	fileInfo, err := newFileInfo(
+		objectInfo.Path(),
+		objectInfo.ExternalPath(),
+		m.isImportPath(objectInfo.Path()),
+		m.moduleIdentity,
+		m.commit,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if m.symlinkPolicy == 0 {
+		return fileInfo, true, nil
+	}
+	resolvedObjectInfo, ok := objectInfo.(storage.ResolvedObjectInfo)
+	if !ok {
+		// the bucket does not follow symlinks
+		return fileInfo, true, nil
+	}
+	fileInfo.isSymlink = resolvedObjectInfo.IsSymlink()
+	switch m.symlinkPolicy {
+	case SymlinkPolicyReject:
+		if fileInfo.isSymlink {
+			return nil, false, fmt.Errorf("%s is reached through a symlink, which is not allowed", objectInfo.ExternalPath())
+		}
+	case SymlinkPolicyResolve:
+		if resolvedExternalPaths != nil {
+			resolvedExternalPath := resolvedObjectInfo.ResolvedExternalPath()
+			if _, ok := resolvedExternalPaths[resolvedExternalPath]; ok {
+				return nil, false, nil
+			}
+			resolvedExternalPaths[resolvedExternalPath] = struct{}{}
+		}
+	default:
+		return nil, false, fmt.Errorf("unknown symlink policy: %v", m.symlinkPolicy)
+	}
+	return fileInfo, true, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// fileSyntaxTag is the field number of syntax within FileDescriptorProto.
+const fileSyntaxTag = 12
+
+func modulePrimarySyntax(ctx context.Context, module Module) (string, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return "", err
+	}
+	syntaxToCount := make(map[string]int)
+	for _, parsedSourceFile := range parsedSourceFiles {
+		if syntax, ok := declaredSyntax(parsedSourceFile.fileDescriptorProto); ok {
+			syntaxToCount[syntax]++
+		}
+	}
+	var primarySyntax string
+	var primaryCount int
+	for syntax, count := range syntaxToCount {
+		// ties are broken by the lexicographically greatest syntax so that
+		// the result is deterministic, which means proto3 wins over proto2
+		if count > primaryCount || (count == primaryCount && syntax > primarySyntax) {
+			primarySyntax = syntax
+			primaryCount = count
+		}
+	}
+	if primarySyntax == "" {
+		return "", errors.New("no source file of the module declares a syntax")
+	}
+	return primarySyntax, nil
+}
+
+// declaredSyntax returns the syntax of the file, and false if the file has
+// no syntax declaration.
+//
+// The parser only sets the syntax for proto3 files, so source code info is
+// used to determine if a proto2 file declared its syntax explicitly.
+func declaredSyntax(fileDescriptorProto *descriptorpb.FileDescriptorProto) (string, bool) {
+	if syntax := fileDescriptorProto.GetSyntax(); syntax != "" {
+		return syntax, true
+	}
+	for _, location := range fileDescriptorProto.GetSourceCodeInfo().GetLocation() {
+		if path := location.GetPath(); len(path) == 1 && path[0] == fileSyntaxTag {
+			return "proto2", true
+		}
+	}
+	return "", false
+}
@@ -79,7 +79,7 @@ func (m *targetingModule) TargetFileInfos(ctx context.Context) (fileInfos []File
 					fileInfo, err := NewFileInfo(
 						objectInfo.Path(),
 						objectInfo.ExternalPath(),
-						false,
+						m.Module.isImportPath(objectInfo.Path()),
 						m.Module.getModuleIdentity(),
 						m.Module.getCommit(),
 					)
@@ -129,7 +129,7 @@ func (m *targetingModule) TargetFileInfos(ctx context.Context) (fileInfos []File
 					fileInfo, err := NewFileInfo(
 						objectInfo.Path(),
 						objectInfo.ExternalPath(),
-						false,
+						m.Module.isImportPath(objectInfo.Path()),
 						m.Module.getModuleIdentity(),
 						m.Module.getCommit(),
 					)
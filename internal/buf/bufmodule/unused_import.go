@@ -0,0 +1,281 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/gen/data/datawkt"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func unusedImports(ctx context.Context, module Module) (map[string][]string, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	pathToFileDescriptorProto := make(map[string]*descriptorpb.FileDescriptorProto, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		pathToFileDescriptorProto[parsedSourceFile.fileInfo.Path()] = parsedSourceFile.fileDescriptorProto
+	}
+	wellKnownTypeFileDescriptorProtos, err := parseWellKnownTypeImports(ctx, parsedSourceFiles, pathToFileDescriptorProto)
+	if err != nil {
+		return nil, err
+	}
+	for path, fileDescriptorProto := range wellKnownTypeFileDescriptorProtos {
+		pathToFileDescriptorProto[path] = fileDescriptorProto
+	}
+	pathToSymbols := make(map[string]map[string]struct{}, len(pathToFileDescriptorProto))
+	for path, fileDescriptorProto := range pathToFileDescriptorProto {
+		pathToSymbols[path] = getReferenceableSymbols(fileDescriptorProto)
+	}
+	pathToUnusedImports := make(map[string][]string)
+	for _, parsedSourceFile := range parsedSourceFiles {
+		fileDescriptorProto := parsedSourceFile.fileDescriptorProto
+		publicDependencyIndexes := make(map[int32]struct{}, len(fileDescriptorProto.GetPublicDependency()))
+		for _, publicDependencyIndex := range fileDescriptorProto.GetPublicDependency() {
+			publicDependencyIndexes[publicDependencyIndex] = struct{}{}
+		}
+		// the imports we know the contents of, and can therefore determine are unused
+		var knownImports []string
+		importToSymbols := make(map[string]map[string]struct{})
+		for i, importPath := range fileDescriptorProto.GetDependency() {
+			if _, ok := publicDependencyIndexes[int32(i)]; ok {
+				// public imports are declared to be re-exported, not to be used
+				continue
+			}
+			if _, ok := pathToFileDescriptorProto[importPath]; !ok {
+				// the contents of imports that are not part of the module, such as
+				// dependencies, are not available
+				continue
+			}
+			knownImports = append(knownImports, importPath)
+			importToSymbols[importPath] = getPublicImportSymbols(importPath, pathToFileDescriptorProto, pathToSymbols, make(map[string]struct{}))
+		}
+		if len(knownImports) == 0 {
+			continue
+		}
+		usedImports := make(map[string]struct{})
+		localSymbols := pathToSymbols[parsedSourceFile.fileInfo.Path()]
+		forEachTypeReference(fileDescriptorProto, func(scope string, name string) {
+			for _, candidate := range getReferenceCandidates(scope, name) {
+				if _, ok := localSymbols[candidate]; ok {
+					return
+				}
+				for _, importPath := range knownImports {
+					if _, ok := importToSymbols[importPath][candidate]; ok {
+						usedImports[importPath] = struct{}{}
+						return
+					}
+				}
+			}
+		})
+		var fileUnusedImports []string
+		for _, importPath := range knownImports {
+			if _, ok := usedImports[importPath]; !ok {
+				fileUnusedImports = append(fileUnusedImports, importPath)
+			}
+		}
+		if len(fileUnusedImports) > 0 {
+			sort.Strings(fileUnusedImports)
+			pathToUnusedImports[parsedSourceFile.fileInfo.Path()] = fileUnusedImports
+		}
+	}
+	return pathToUnusedImports, nil
+}
+
+// parseWellKnownTypeImports parses the Well-Known Types imported by the source files
+// that are not source files themselves.
+func parseWellKnownTypeImports(
+	ctx context.Context,
+	parsedSourceFiles []*parsedSourceFile,
+	pathToFileDescriptorProto map[string]*descriptorpb.FileDescriptorProto,
+) (map[string]*descriptorpb.FileDescriptorProto, error) {
+	wellKnownTypePathMap := make(map[string]struct{})
+	for _, parsedSourceFile := range parsedSourceFiles {
+		for _, importPath := range parsedSourceFile.fileDescriptorProto.GetDependency() {
+			if _, ok := pathToFileDescriptorProto[importPath]; ok {
+				continue
+			}
+			if _, err := datawkt.ReadBucket.Stat(ctx, importPath); err == nil {
+				wellKnownTypePathMap[importPath] = struct{}{}
+			}
+		}
+	}
+	if len(wellKnownTypePathMap) == 0 {
+		return nil, nil
+	}
+	wellKnownTypePaths := make([]string, 0, len(wellKnownTypePathMap))
+	for wellKnownTypePath := range wellKnownTypePathMap {
+		wellKnownTypePaths = append(wellKnownTypePaths, wellKnownTypePath)
+	}
+	sort.Strings(wellKnownTypePaths)
+	parser := protoparse.Parser{
+		Accessor: func(path string) (io.ReadCloser, error) {
+			return datawkt.ReadBucket.Get(ctx, path)
+		},
+	}
+	fileDescriptorProtos, err := parser.ParseFilesButDoNotLink(wellKnownTypePaths...)
+	if err != nil {
+		return nil, err
+	}
+	wellKnownTypeFileDescriptorProtos := make(map[string]*descriptorpb.FileDescriptorProto, len(fileDescriptorProtos))
+	for i, fileDescriptorProto := range fileDescriptorProtos {
+		wellKnownTypeFileDescriptorProtos[wellKnownTypePaths[i]] = fileDescriptorProto
+	}
+	return wellKnownTypeFileDescriptorProtos, nil
+}
+
+// getPublicImportSymbols returns the symbols of the file at the path, along with the
+// symbols of every known file it transitively imports publicly.
+func getPublicImportSymbols(
+	path string,
+	pathToFileDescriptorProto map[string]*descriptorpb.FileDescriptorProto,
+	pathToSymbols map[string]map[string]struct{},
+	seenPaths map[string]struct{},
+) map[string]struct{} {
+	seenPaths[path] = struct{}{}
+	fileDescriptorProto := pathToFileDescriptorProto[path]
+	if len(fileDescriptorProto.GetPublicDependency()) == 0 {
+		return pathToSymbols[path]
+	}
+	symbols := make(map[string]struct{}, len(pathToSymbols[path]))
+	for symbol := range pathToSymbols[path] {
+		symbols[symbol] = struct{}{}
+	}
+	for _, publicDependencyIndex := range fileDescriptorProto.GetPublicDependency() {
+		if int(publicDependencyIndex) >= len(fileDescriptorProto.GetDependency()) {
+			continue
+		}
+		publicImportPath := fileDescriptorProto.GetDependency()[publicDependencyIndex]
+		if _, ok := seenPaths[publicImportPath]; ok {
+			continue
+		}
+		if _, ok := pathToFileDescriptorProto[publicImportPath]; !ok {
+			continue
+		}
+		for symbol := range getPublicImportSymbols(publicImportPath, pathToFileDescriptorProto, pathToSymbols, seenPaths) {
+			symbols[symbol] = struct{}{}
+		}
+	}
+	return symbols
+}
+
+// getReferenceableSymbols returns the fully-qualified names of the messages, enums,
+// and extensions declared in the file, which are what other files can reference.
+func getReferenceableSymbols(fileDescriptorProto *descriptorpb.FileDescriptorProto) map[string]struct{} {
+	symbols := make(map[string]struct{})
+	for _, fieldDescriptorProto := range fileDescriptorProto.GetExtension() {
+		symbols[joinSymbolName(fileDescriptorProto.GetPackage(), fieldDescriptorProto.GetName())] = struct{}{}
+	}
+	forEachMessage(fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+		symbols[fullName] = struct{}{}
+		for _, fieldDescriptorProto := range descriptorProto.GetExtension() {
+			symbols[joinSymbolName(fullName, fieldDescriptorProto.GetName())] = struct{}{}
+		}
+	})
+	forEachEnum(fileDescriptorProto, func(fullName string, _ *descriptorpb.EnumDescriptorProto) {
+		symbols[fullName] = struct{}{}
+	})
+	return symbols
+}
+
+// forEachTypeReference calls f for every reference to a message, enum, or extension
+// in the file, along with the fully-qualified name of the scope the reference is
+// resolved relative to.
+//
+// Files are parsed without linking, so names are as written in the source file. This
+// includes the types of fields and extensions, the extendees of extensions, the input
+// and output types of methods, and the extensions used as custom options.
+func forEachTypeReference(
+	fileDescriptorProto *descriptorpb.FileDescriptorProto,
+	f func(scope string, name string),
+) {
+	pkg := fileDescriptorProto.GetPackage()
+	forEachFieldTypeReference(pkg, fileDescriptorProto.GetExtension(), f)
+	for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+		forEachMessageTypeReference(pkg, descriptorProto, f)
+	}
+	for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+		serviceScope := joinSymbolName(pkg, serviceDescriptorProto.GetName())
+		for _, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+			f(serviceScope, methodDescriptorProto.GetInputType())
+			f(serviceScope, methodDescriptorProto.GetOutputType())
+		}
+	}
+	for _, uninterpretedOption := range getFileUninterpretedOptions(fileDescriptorProto) {
+		for _, namePart := range uninterpretedOption.GetName() {
+			if namePart.GetIsExtension() {
+				f(pkg, namePart.GetNamePart())
+			}
+		}
+	}
+}
+
+func forEachMessageTypeReference(
+	prefix string,
+	descriptorProto *descriptorpb.DescriptorProto,
+	f func(scope string, name string),
+) {
+	// map entry messages are included, as the types of map values are references
+	scope := joinSymbolName(prefix, descriptorProto.GetName())
+	forEachFieldTypeReference(scope, descriptorProto.GetField(), f)
+	forEachFieldTypeReference(scope, descriptorProto.GetExtension(), f)
+	for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		forEachMessageTypeReference(scope, nestedDescriptorProto, f)
+	}
+}
+
+func forEachFieldTypeReference(
+	scope string,
+	fieldDescriptorProtos []*descriptorpb.FieldDescriptorProto,
+	f func(scope string, name string),
+) {
+	for _, fieldDescriptorProto := range fieldDescriptorProtos {
+		if typeName := fieldDescriptorProto.GetTypeName(); typeName != "" {
+			f(scope, typeName)
+		}
+		if extendee := fieldDescriptorProto.GetExtendee(); extendee != "" {
+			f(scope, extendee)
+		}
+	}
+}
+
+// getReferenceCandidates returns the fully-qualified names the name could refer to
+// from within the scope, in the order protoc searches them.
+//
+// For example, the name "Bar" within the scope "foo.Baz" could refer to "foo.Baz.Bar",
+// "foo.Bar", or "Bar".
+func getReferenceCandidates(scope string, name string) []string {
+	if strings.HasPrefix(name, ".") {
+		return []string{strings.TrimPrefix(name, ".")}
+	}
+	var candidates []string
+	for {
+		candidates = append(candidates, joinSymbolName(scope, name))
+		if scope == "" {
+			return candidates
+		}
+		if index := strings.LastIndex(scope, "."); index >= 0 {
+			scope = scope[:index]
+		} else {
+			scope = ""
+		}
+	}
+}
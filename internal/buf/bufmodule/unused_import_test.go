@@ -0,0 +1,81 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnusedImports(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path: "a/a.proto",
+					Content: []byte(`syntax = "proto3";
+package a;
+import "b/b.proto";
+import "c/c.proto";
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/duration.proto";
+import "acme/options.proto";
+message Foo {
+  b.Bar bar = 1;
+  map<string, google.protobuf.Timestamp> times = 2;
+}`),
+				},
+				{
+					Path:    "b/b.proto",
+					Content: []byte(`syntax = "proto3"; package b; import public "c/c.proto"; message Bar {}`),
+				},
+				{
+					Path:    "c/c.proto",
+					Content: []byte(`syntax = "proto3"; package c; message Baz {}`),
+				},
+				{
+					Path: "d/d.proto",
+					Content: []byte(`syntax = "proto3";
+package d;
+import "b/b.proto";
+service Qux {
+  rpc Get(c.Baz) returns (c.Baz);
+}`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	unusedImports, err := bufmodule.UnusedImports(ctx, module)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		map[string][]string{
+			"a/a.proto": {
+				"c/c.proto",
+				"google/protobuf/duration.proto",
+			},
+		},
+		unusedImports,
+	)
+}
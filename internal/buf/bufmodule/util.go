@@ -144,6 +144,46 @@ func modulePinCompareTo(a ModulePin, b ModulePin) int {
 	return 0
 }
 
+// return -1 if less
+// return 1 if greater
+// return 0 if equal
+func moduleReferenceCompareTo(a ModuleReference, b ModuleReference) int {
+	if a == nil && b == nil {
+		return 0
+	}
+	if a == nil && b != nil {
+		return -1
+	}
+	if a != nil && b == nil {
+		return 1
+	}
+	if a.Remote() < b.Remote() {
+		return -1
+	}
+	if a.Remote() > b.Remote() {
+		return 1
+	}
+	if a.Owner() < b.Owner() {
+		return -1
+	}
+	if a.Owner() > b.Owner() {
+		return 1
+	}
+	if a.Repository() < b.Repository() {
+		return -1
+	}
+	if a.Repository() > b.Repository() {
+		return 1
+	}
+	if a.Reference() < b.Reference() {
+		return -1
+	}
+	if a.Reference() > b.Reference() {
+		return 1
+	}
+	return 0
+}
+
 func copyModulePinsSortedByOnlyCommit(modulePins []ModulePin) []ModulePin {
 	s := make([]ModulePin, len(modulePins))
 	copy(s, modulePins)
@@ -235,11 +275,32 @@ func putModulePinsToBucket(
 	ctx context.Context,
 	writeBucket storage.WriteBucket,
 	modulePins []ModulePin,
+	options ...PutModuleDependencyModulePinsOption,
 ) error {
+	putModuleDependencyModulePinsOptions := &putModuleDependencyModulePinsOptions{}
+	for _, option := range options {
+		option(putModuleDependencyModulePinsOptions)
+	}
+	var writeConfigOptions []buflock.WriteConfigOption
+	if putModuleDependencyModulePinsOptions.lockFilePath != "" {
+		writeConfigOptions = append(
+			writeConfigOptions,
+			buflock.WriteConfigWithFilePath(putModuleDependencyModulePinsOptions.lockFilePath),
+		)
+	}
 	lockFile := &buflock.Config{
 		Dependencies: make([]buflock.Dependency, 0, len(modulePins)),
 	}
 	for _, pin := range modulePins {
+		digest, err := getModulePinDigest(
+			ctx,
+			pin,
+			putModuleDependencyModulePinsOptions.digestAlgorithm,
+			putModuleDependencyModulePinsOptions.moduleReader,
+		)
+		if err != nil {
+			return err
+		}
 		lockFile.Dependencies = append(
 			lockFile.Dependencies,
 			buflock.Dependency{
@@ -248,19 +309,51 @@ func putModulePinsToBucket(
 				Repository: pin.Repository(),
 				Branch:     pin.Branch(),
 				Commit:     pin.Commit(),
-				Digest:     pin.Digest(),
+				Digest:     digest,
 				CreateTime: pin.CreateTime(),
 			},
 		)
 	}
-	return buflock.WriteConfig(ctx, writeBucket, lockFile)
+	return buflock.WriteConfig(ctx, writeBucket, lockFile, writeConfigOptions...)
 }
 
+type putModuleDependencyModulePinsOptions struct {
+	lockFilePath    string
+	digestAlgorithm DigestAlgorithm
+	moduleReader    ModuleReader
+}
+
+// digestAlgorithm may be 0, in which case the digest of the pin is returned as-is.
+func getModulePinDigest(
+	ctx context.Context,
+	pin ModulePin,
+	digestAlgorithm DigestAlgorithm,
+	moduleReader ModuleReader,
+) (string, error) {
+	if digestAlgorithm == 0 || strings.HasPrefix(pin.Digest(), digestAlgorithm.String()+"-") {
+		return pin.Digest(), nil
+	}
+	if moduleReader == nil {
+		return "", fmt.Errorf("no module reader to compute the %s digest for %s", digestAlgorithm.String(), pin.String())
+	}
+	module, err := moduleReader.GetModule(ctx, pin)
+	if err != nil {
+		return "", err
+	}
+	return ModuleDigest(ctx, module, digestAlgorithm)
+}
+
+// lockFilePath may be empty, in which case the default lock file path is used.
 func getDependencyModulePinsForBucket(
 	ctx context.Context,
 	sourceReadBucket storage.ReadBucket,
+	lockFilePath string,
 ) ([]ModulePin, error) {
-	lockFile, err := buflock.ReadConfig(ctx, sourceReadBucket)
+	var readConfigOptions []buflock.ReadConfigOption
+	if lockFilePath != "" {
+		readConfigOptions = append(readConfigOptions, buflock.ReadConfigWithFilePath(lockFilePath))
+	}
+	lockFile, err := buflock.ReadConfig(ctx, sourceReadBucket, readConfigOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read lock file: %w", err)
 	}
@@ -19,9 +19,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
 	"github.com/bufbuild/buf/internal/pkg/netextended"
+	"go.uber.org/multierr"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -191,6 +193,37 @@ func ValidateTag(tag string) error {
 	return nil
 }
 
+// ValidateModulePinTimestamps verifies that the create times of the module pins
+// are set and are not in the future.
+//
+// This is a cheap check that does not contact the registry, and is intended to catch
+// lock files with invalid pins before any dependencies are fetched. An error is
+// returned for every invalid pin.
+func ValidateModulePinTimestamps(modulePins []ModulePin) error {
+	return validateModulePinTimestamps(modulePins, time.Now())
+}
+
+func validateModulePinTimestamps(modulePins []ModulePin, now time.Time) error {
+	var err error
+	for _, modulePin := range modulePins {
+		createTime := modulePin.CreateTime()
+		switch {
+		case createTime.IsZero() || createTime.Unix() == 0:
+			err = multierr.Append(err, fmt.Errorf("module pin %s has no create time", modulePin.String()))
+		case createTime.After(now):
+			err = multierr.Append(
+				err,
+				fmt.Errorf(
+					"module pin %s has a create time in the future: %s",
+					modulePin.String(),
+					createTime.UTC().Format(time.RFC3339),
+				),
+			)
+		}
+	}
+	return err
+}
+
 // ValidateDigest verifies the given digest's prefix,
 // decodes its base64 representation and checks the
 // length of the encoded bytes.
@@ -204,7 +237,7 @@ func ValidateDigest(digest string) error {
 	}
 	digestPrefix := split[0]
 	digestValue := split[1]
-	if digestPrefix != b1DigestPrefix {
+	if _, ok := stringToDigestAlgorithm[digestPrefix]; !ok {
 		return fmt.Errorf("unknown digest prefix: %s", digestPrefix)
 	}
 	decoded, err := base64.URLEncoding.DecodeString(digestValue)
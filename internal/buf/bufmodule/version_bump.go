@@ -0,0 +1,77 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func recommendVersionBump(ctx context.Context, current Module, baseline Module) (string, error) {
+	currentSymbols, err := getModuleVersionBumpSymbols(ctx, current)
+	if err != nil {
+		return "", err
+	}
+	baselineSymbols, err := getModuleVersionBumpSymbols(ctx, baseline)
+	if err != nil {
+		return "", err
+	}
+	for baselineSymbol := range baselineSymbols {
+		if _, ok := currentSymbols[baselineSymbol]; !ok {
+			return VersionBumpMajor, nil
+		}
+	}
+	if len(currentSymbols) > len(baselineSymbols) {
+		// every baseline symbol is in currentSymbols, so there are additions
+		return VersionBumpMinor, nil
+	}
+	return VersionBumpPatch, nil
+}
+
+// getModuleVersionBumpSymbols returns the names of the messages, fields, enums,
+// enum values, services, and methods declared in the source files of the Module.
+//
+// Fields, enum values, and methods are named relative to their parent declaration.
+func getModuleVersionBumpSymbols(ctx context.Context, module Module) (map[string]struct{}, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	symbols := make(map[string]struct{})
+	for _, parsedSourceFile := range parsedSourceFiles {
+		fileDescriptorProto := parsedSourceFile.fileDescriptorProto
+		forEachMessage(fileDescriptorProto, func(fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+			symbols[fullName] = struct{}{}
+			for _, fieldDescriptorProto := range descriptorProto.GetField() {
+				symbols[joinSymbolName(fullName, fieldDescriptorProto.GetName())] = struct{}{}
+			}
+		})
+		forEachEnum(fileDescriptorProto, func(fullName string, enumDescriptorProto *descriptorpb.EnumDescriptorProto) {
+			symbols[fullName] = struct{}{}
+			for _, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
+				symbols[joinSymbolName(fullName, enumValueDescriptorProto.GetName())] = struct{}{}
+			}
+		})
+		for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+			serviceFullName := joinSymbolName(fileDescriptorProto.GetPackage(), serviceDescriptorProto.GetName())
+			symbols[serviceFullName] = struct{}{}
+			for _, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+				symbols[joinSymbolName(serviceFullName, methodDescriptorProto.GetName())] = struct{}{}
+			}
+		}
+	}
+	return symbols, nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendVersionBump(t *testing.T) {
+	t.Parallel()
+	baseline := `syntax = "proto3";
+package foo;
+message Foo {
+  string one = 1;
+}
+`
+	testRecommendVersionBump(
+		t,
+		bufmodule.VersionBumpMajor,
+		baseline,
+		`syntax = "proto3";
+package foo;
+message Foo {
+  string uno = 1;
+}
+`,
+	)
+	testRecommendVersionBump(
+		t,
+		bufmodule.VersionBumpMinor,
+		baseline,
+		`syntax = "proto3";
+package foo;
+message Foo {
+  string one = 1;
+  string two = 2;
+}
+`,
+	)
+	testRecommendVersionBump(
+		t,
+		bufmodule.VersionBumpPatch,
+		baseline,
+		`syntax = "proto3";
+package foo;
+// Foo is a foo.
+message Foo {
+  string one = 1;
+}
+`,
+	)
+}
+
+func testRecommendVersionBump(t *testing.T, expected string, baselineContent string, currentContent string) {
+	ctx := context.Background()
+	baseline, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "foo/foo.proto",
+					Content: []byte(baselineContent),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	current, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "foo/foo.proto",
+					Content: []byte(currentContent),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	versionBump, err := bufmodule.RecommendVersionBump(ctx, current, baseline)
+	require.NoError(t, err)
+	assert.Equal(t, expected, versionBump)
+}
@@ -0,0 +1,281 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// versionConstraintOperators are the comparison operators of a version constraint.
+//
+// The two-character operators must come first so that they are matched before
+// their one-character prefixes.
+var versionConstraintOperators = []string{">=", "<=", ">", "<", "="}
+
+type versionConstraint struct {
+	comparisons []*versionComparison
+}
+
+func newVersionConstraint(s string) (*versionConstraint, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, errors.New("version constraint is empty")
+	}
+	var comparisons []*versionComparison
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		// allow whitespace between the operator and the version, ie ">= v1.2.0"
+		if isVersionConstraintOperator(field) && i+1 < len(fields) {
+			i++
+			field += fields[i]
+		}
+		comparison, err := newVersionComparison(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return &versionConstraint{
+		comparisons: comparisons,
+	}, nil
+}
+
+func (v *versionConstraint) Matches(version string) bool {
+	parsedVersion, err := parseSemanticVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, comparison := range v.comparisons {
+		if !comparison.matches(parsedVersion) {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *versionConstraint) String() string {
+	comparisonStrings := make([]string, len(v.comparisons))
+	for i, comparison := range v.comparisons {
+		comparisonStrings[i] = comparison.operator + comparison.version.String()
+	}
+	return strings.Join(comparisonStrings, " ")
+}
+
+func (*versionConstraint) isVersionConstraint() {}
+
+type versionComparison struct {
+	operator string
+	version  *semanticVersion
+}
+
+func newVersionComparison(s string) (*versionComparison, error) {
+	for _, operator := range versionConstraintOperators {
+		if strings.HasPrefix(s, operator) {
+			version, err := parseSemanticVersion(strings.TrimPrefix(s, operator))
+			if err != nil {
+				return nil, err
+			}
+			return &versionComparison{
+				operator: operator,
+				version:  version,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%q must start with one of %s", s, strings.Join(versionConstraintOperators, ", "))
+}
+
+func (v *versionComparison) matches(version *semanticVersion) bool {
+	compare := version.compareTo(v.version)
+	switch v.operator {
+	case ">=":
+		return compare >= 0
+	case "<=":
+		return compare <= 0
+	case ">":
+		return compare > 0
+	case "<":
+		return compare < 0
+	default:
+		return compare == 0
+	}
+}
+
+// semanticVersion is a version of the form vMAJOR.MINOR.PATCH, with an
+// optional pre-release suffix.
+//
+// Build metadata is accepted but ignored for comparisons.
+type semanticVersion struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+}
+
+func parseSemanticVersion(s string) (*semanticVersion, error) {
+	if !strings.HasPrefix(s, "v") {
+		return nil, fmt.Errorf("version %q must be of the form vMAJOR.MINOR.PATCH", s)
+	}
+	rest := strings.TrimPrefix(s, "v")
+	if index := strings.IndexByte(rest, '+'); index >= 0 {
+		rest = rest[:index]
+	}
+	var prerelease []string
+	if index := strings.IndexByte(rest, '-'); index >= 0 {
+		prerelease = strings.Split(rest[index+1:], ".")
+		rest = rest[:index]
+		for _, identifier := range prerelease {
+			if identifier == "" {
+				return nil, fmt.Errorf("version %q has an empty pre-release identifier", s)
+			}
+		}
+	}
+	numbers := strings.Split(rest, ".")
+	if len(numbers) != 3 {
+		return nil, fmt.Errorf("version %q must be of the form vMAJOR.MINOR.PATCH", s)
+	}
+	parsedNumbers := make([]int, 3)
+	for i, number := range numbers {
+		parsedNumber, err := strconv.Atoi(number)
+		if err != nil || parsedNumber < 0 || number != strconv.Itoa(parsedNumber) {
+			return nil, fmt.Errorf("version %q must be of the form vMAJOR.MINOR.PATCH", s)
+		}
+		parsedNumbers[i] = parsedNumber
+	}
+	return &semanticVersion{
+		major:      parsedNumbers[0],
+		minor:      parsedNumbers[1],
+		patch:      parsedNumbers[2],
+		prerelease: prerelease,
+	}, nil
+}
+
+func (s *semanticVersion) String() string {
+	version := "v" + strconv.Itoa(s.major) + "." + strconv.Itoa(s.minor) + "." + strconv.Itoa(s.patch)
+	if len(s.prerelease) > 0 {
+		version += "-" + strings.Join(s.prerelease, ".")
+	}
+	return version
+}
+
+// compareTo follows the precedence rules of semantic versioning, where a
+// version with a pre-release has a lower precedence than the same version
+// without one.
+func (s *semanticVersion) compareTo(other *semanticVersion) int {
+	if compare := compareInts(s.major, other.major); compare != 0 {
+		return compare
+	}
+	if compare := compareInts(s.minor, other.minor); compare != 0 {
+		return compare
+	}
+	if compare := compareInts(s.patch, other.patch); compare != 0 {
+		return compare
+	}
+	switch {
+	case len(s.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(s.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(s.prerelease) && i < len(other.prerelease); i++ {
+		if compare := comparePrereleaseIdentifiers(s.prerelease[i], other.prerelease[i]); compare != 0 {
+			return compare
+		}
+	}
+	return compareInts(len(s.prerelease), len(other.prerelease))
+}
+
+// comparePrereleaseIdentifiers compares numeric identifiers numerically, and
+// all other identifiers lexically. Numeric identifiers have a lower precedence.
+func comparePrereleaseIdentifiers(one string, two string) int {
+	oneNumber, oneErr := strconv.Atoi(one)
+	twoNumber, twoErr := strconv.Atoi(two)
+	switch {
+	case oneErr == nil && twoErr == nil:
+		return compareInts(oneNumber, twoNumber)
+	case oneErr == nil:
+		return -1
+	case twoErr == nil:
+		return 1
+	}
+	return strings.Compare(one, two)
+}
+
+func compareInts(one int, two int) int {
+	if one < two {
+		return -1
+	}
+	if one > two {
+		return 1
+	}
+	return 0
+}
+
+func isVersionConstraintOperator(s string) bool {
+	for _, operator := range versionConstraintOperators {
+		if s == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// isVersionConstraintString returns true if the reference is a version constraint
+// rather than a branch, tag, or commit.
+func isVersionConstraintString(reference string) bool {
+	for _, operator := range versionConstraintOperators {
+		if strings.HasPrefix(reference, operator) {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveModuleReference(available []ModuleReference, constraint ModuleReference) (ModuleReference, error) {
+	if constraint == nil {
+		return nil, errors.New("module reference is required")
+	}
+	versionConstraint := constraint.Constraint()
+	if versionConstraint == nil {
+		return nil, fmt.Errorf("module reference %q does not have a version constraint", constraint.String())
+	}
+	var resolved ModuleReference
+	var resolvedVersion *semanticVersion
+	for _, moduleReference := range available {
+		if moduleReference == nil || moduleReference.IdentityString() != constraint.IdentityString() {
+			continue
+		}
+		version, err := parseSemanticVersion(moduleReference.Reference())
+		if err != nil {
+			// not a version, ie a branch or commit
+			continue
+		}
+		if !versionConstraint.Matches(moduleReference.Reference()) {
+			continue
+		}
+		if resolvedVersion == nil || version.compareTo(resolvedVersion) > 0 {
+			resolved = moduleReference
+			resolvedVersion = version
+		}
+	}
+	if resolved == nil {
+		return nil, fmt.Errorf("no version of %s satisfies %q", constraint.IdentityString(), versionConstraint.String())
+	}
+	return resolved, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleReferenceForStringConstraint(t *testing.T) {
+	t.Parallel()
+	moduleReference, err := ModuleReferenceForString("buf.build/acme/payments:>= v1.2.0  <v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, ">=v1.2.0 <v2.0.0", moduleReference.Reference())
+	assert.Equal(t, "buf.build/acme/payments:>=v1.2.0 <v2.0.0", moduleReference.String())
+	constraint := moduleReference.Constraint()
+	require.NotNil(t, constraint)
+	assert.True(t, constraint.Matches("v1.2.0"))
+	assert.True(t, constraint.Matches("v1.10.3"))
+	assert.False(t, constraint.Matches("v1.1.9"))
+	assert.False(t, constraint.Matches("v2.0.0"))
+	// pre-releases of v2.0.0 have a lower precedence than v2.0.0
+	assert.True(t, constraint.Matches("v2.0.0-rc.1"))
+	assert.False(t, constraint.Matches("main"))
+
+	moduleReference, err = ModuleReferenceForString("buf.build/acme/payments:v1.2.0")
+	require.NoError(t, err)
+	assert.Nil(t, moduleReference.Constraint())
+
+	for _, invalid := range []string{
+		"buf.build/acme/payments:>=1.2.0",
+		"buf.build/acme/payments:>=v1.2",
+		"buf.build/acme/payments:>=v1.2.0 v2.0.0",
+		"buf.build/acme/payments:>=",
+		"buf.build/acme/payments:<v01.0.0",
+	} {
+		_, err := ModuleReferenceForString(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestVersionConstraintPrerelease(t *testing.T) {
+	t.Parallel()
+	constraint, err := ParseVersionConstraint(">v1.0.0-alpha.1 <v1.0.0")
+	require.NoError(t, err)
+	assert.True(t, constraint.Matches("v1.0.0-alpha.2"))
+	assert.True(t, constraint.Matches("v1.0.0-beta"))
+	assert.True(t, constraint.Matches("v1.0.0-alpha.1.1"))
+	assert.False(t, constraint.Matches("v1.0.0-alpha"))
+	assert.False(t, constraint.Matches("v1.0.0"))
+}
+
+func TestResolveModuleReference(t *testing.T) {
+	t.Parallel()
+	var available []ModuleReference
+	for _, s := range []string{
+		"buf.build/acme/payments:v1.1.0",
+		"buf.build/acme/payments:v1.3.0",
+		"buf.build/acme/payments:v1.2.5",
+		"buf.build/acme/payments:v2.0.0",
+		"buf.build/acme/payments:main",
+		"buf.build/acme/other:v1.9.0",
+	} {
+		moduleReference, err := ModuleReferenceForString(s)
+		require.NoError(t, err)
+		available = append(available, moduleReference)
+	}
+	constraint, err := ModuleReferenceForString("buf.build/acme/payments:>=v1.2.0 <v2.0.0")
+	require.NoError(t, err)
+	resolved, err := ResolveModuleReference(available, constraint)
+	require.NoError(t, err)
+	assert.Equal(t, "buf.build/acme/payments:v1.3.0", resolved.String())
+
+	constraint, err = ModuleReferenceForString("buf.build/acme/payments:>v2.0.0")
+	require.NoError(t, err)
+	_, err = ResolveModuleReference(available, constraint)
+	assert.Error(t, err)
+
+	_, err = ResolveModuleReference(available, available[0])
+	assert.Error(t, err)
+}
@@ -0,0 +1,69 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bufbuild/buf/internal/pkg/protoversion"
+)
+
+func validateVersionImportBoundaries(ctx context.Context, module Module) ([]Violation, error) {
+	parsedSourceFiles, err := parseSourceFiles(ctx, module)
+	if err != nil {
+		return nil, err
+	}
+	pathToPackage := make(map[string]string, len(parsedSourceFiles))
+	for _, parsedSourceFile := range parsedSourceFiles {
+		pathToPackage[parsedSourceFile.fileInfo.Path()] = parsedSourceFile.fileDescriptorProto.GetPackage()
+	}
+	var violations []Violation
+	for _, parsedSourceFile := range parsedSourceFiles {
+		pkg := parsedSourceFile.fileDescriptorProto.GetPackage()
+		packageVersion, ok := protoversion.NewPackageVersionForPackage(pkg)
+		if !ok {
+			continue
+		}
+		for _, importPath := range parsedSourceFile.fileDescriptorProto.GetDependency() {
+			// imports of files outside of the module, such as dependencies,
+			// are not checked as we do not know their package
+			importPackage, ok := pathToPackage[importPath]
+			if !ok {
+				continue
+			}
+			importPackageVersion, ok := protoversion.NewPackageVersionForPackage(importPackage)
+			if !ok {
+				continue
+			}
+			if packageVersion.Major() < importPackageVersion.Major() {
+				violations = append(
+					violations,
+					Violation{
+						FileInfo:   parsedSourceFile.fileInfo,
+						ImportPath: importPath,
+						Message: fmt.Sprintf(
+							"package %q imports %q which has the newer major version package %q",
+							pkg,
+							importPath,
+							importPackage,
+						),
+					},
+				)
+			}
+		}
+	}
+	return violations, nil
+}
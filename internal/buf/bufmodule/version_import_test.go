@@ -0,0 +1,55 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufmodule_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufmodule"
+	modulev1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/module/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateVersionImportBoundaries(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	module, err := bufmodule.NewModuleForProto(
+		ctx,
+		&modulev1alpha1.Module{
+			Files: []*modulev1alpha1.ModuleFile{
+				{
+					Path:    "foo/v1/a.proto",
+					Content: []byte(`syntax = "proto3"; package foo.v1; import "foo/v2/b.proto"; import "google/protobuf/empty.proto";`),
+				},
+				{
+					Path:    "foo/v2/b.proto",
+					Content: []byte(`syntax = "proto3"; package foo.v2;`),
+				},
+				{
+					Path:    "foo/v2/c.proto",
+					Content: []byte(`syntax = "proto3"; package foo.v2; import "foo/v1/a.proto";`),
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	violations, err := bufmodule.ValidateVersionImportBoundaries(ctx, module)
+	require.NoError(t, err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "foo/v1/a.proto", violations[0].FileInfo.Path())
+	assert.Equal(t, "foo/v2/b.proto", violations[0].ImportPath)
+}
@@ -150,6 +150,7 @@ func (i *imageConfigReader) getSourceOrModuleImageConfigs(
 			ctx,
 			moduleConfig.Module(),
 			bufmodulebuild.WithWorkspace(moduleConfig.Workspace()),
+			bufmodulebuild.WithDependencyImportAliases(moduleConfig.Config().DependencyImportAliases),
 		)
 		if err != nil {
 			return nil, nil, err
@@ -596,6 +596,7 @@ SERVICE_PASCAL_CASE               BASIC, DEFAULT           Checks that services
 SYNTAX_SPECIFIED                  BASIC, DEFAULT           Checks that all files have a syntax specified.
 ENUM_VALUE_PREFIX                 DEFAULT                  Checks that enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE.
 ENUM_ZERO_VALUE_SUFFIX            DEFAULT                  Checks that enum zero values are suffixed with _UNSPECIFIED (suffix is configurable).
+FIELD_NUMBER_ALLOWED              DEFAULT                  Checks that field numbers are within the allowed ranges and not within the reserved range 19000 to 19999 (ranges are configurable).
 FILE_LOWER_SNAKE_CASE             DEFAULT                  Checks that filenames are lower_snake_case.
 PACKAGE_VERSION_SUFFIX            DEFAULT                  Checks that the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1.
 RPC_REQUEST_RESPONSE_UNIQUE       DEFAULT                  Checks that RPC request and response types are only used in one RPC (configurable).
@@ -1142,6 +1143,31 @@ breaking:
 	)
 }
 
+func TestRepositoryCreateVisibilityRequiredWithoutConfig(t *testing.T) {
+	t.Parallel()
+	stderr := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandExitCode(
+		t,
+		func(use string) *appcmd.Command { return testNewRootCommand(use) },
+		1,
+		func(use string) map[string]string {
+			return map[string]string{
+				useEnvVar(use, "CONFIG_DIR"): "testdata/config",
+				useEnvVar(use, "CACHE_DIR"):  "cache",
+			}
+		},
+		nil,
+		nil,
+		stderr,
+		"beta",
+		"registry",
+		"repository",
+		"create",
+		"buf.build/foo/bar",
+	)
+	assert.Contains(t, stderr.String(), `Failure: required flag "visibility" not set`)
+}
+
 func TestMigrateV1Beta1(t *testing.T) {
 	t.Parallel()
 	storageosProvider := storageos.NewProvider()
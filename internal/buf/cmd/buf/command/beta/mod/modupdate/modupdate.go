@@ -120,11 +120,22 @@ func run(
 	}
 
 	var dependencyModulePins []bufmodule.ModulePin
+	var putModuleDependencyModulePinsOptions []bufmodule.PutModuleDependencyModulePinsOption
 	if len(moduleConfig.Build.DependencyModuleReferences) != 0 {
 		apiProvider, err := bufcli.NewRegistryProvider(ctx, container)
 		if err != nil {
 			return err
 		}
+		if moduleConfig.DigestAlgorithm != bufmodule.DigestAlgorithmB1 {
+			moduleReader, err := bufcli.NewModuleReaderAndCreateCacheDirs(container, apiProvider)
+			if err != nil {
+				return err
+			}
+			putModuleDependencyModulePinsOptions = append(
+				putModuleDependencyModulePinsOptions,
+				bufmodule.PutModuleDependencyModulePinsWithDigestAlgorithm(moduleConfig.DigestAlgorithm, moduleReader),
+			)
+		}
 		service, err := apiProvider.NewResolveService(ctx, remote)
 		if err != nil {
 			return err
@@ -173,7 +184,12 @@ func run(
 	if err != nil {
 		return bufcli.NewInternalError(err)
 	}
-	if err := bufmodule.PutModuleDependencyModulePinsToBucket(ctx, readWriteBucket, module.DependencyModulePins()); err != nil {
+	if err := bufmodule.PutModuleDependencyModulePinsToBucket(
+		ctx,
+		readWriteBucket,
+		module.DependencyModulePins(),
+		putModuleDependencyModulePinsOptions...,
+	); err != nil {
 		return bufcli.NewInternalError(err)
 	}
 	return nil
@@ -19,15 +19,19 @@ import (
 	"fmt"
 
 	"github.com/bufbuild/buf/internal/buf/bufcli"
+	"github.com/bufbuild/buf/internal/buf/bufconfig"
 	"github.com/bufbuild/buf/internal/buf/bufmodule"
 	"github.com/bufbuild/buf/internal/buf/bufprint"
 	registryv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/registry/v1alpha1"
 	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
 	"github.com/bufbuild/buf/internal/pkg/app/appflag"
 	"github.com/bufbuild/buf/internal/pkg/rpc"
+	"github.com/bufbuild/buf/internal/pkg/storage"
+	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/zap"
 )
 
 const (
@@ -83,9 +87,11 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		&f.Visibility,
 		visibilityFlagName,
 		"",
-		fmt.Sprintf(`The repository's visibility setting. Must be one of %s.`, stringutil.SliceToString(allVisibiltyStrings)),
+		fmt.Sprintf(
+			`The repository's visibility setting. Must be one of %s. Defaults to the default_visibility of the configuration file in the current directory, if present.`,
+			stringutil.SliceToString(allVisibiltyStrings),
+		),
 	)
-	_ = cobra.MarkFlagRequired(flagSet, visibilityFlagName)
 }
 
 func run(
@@ -97,9 +103,16 @@ func run(
 	if err != nil {
 		return appcmd.NewInvalidArgumentError(err.Error())
 	}
-	visibility, err := visibilityFlagToVisibility(flags.Visibility)
+	readBucket, err := storageos.NewProvider(storageos.ProviderWithSymlinks()).NewReadWriteBucket(
+		".",
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
 	if err != nil {
-		return appcmd.NewInvalidArgumentError(err.Error())
+		return bufcli.NewInternalError(err)
+	}
+	visibility, err := getVisibility(ctx, container.Logger(), readBucket, flags.Visibility)
+	if err != nil {
+		return err
 	}
 	format, err := bufprint.ParseFormat(flags.Format)
 	if err != nil {
@@ -132,6 +145,37 @@ func run(
 	).PrintRepository(ctx, format, repository)
 }
 
+// getVisibility returns the visibility for the flag value.
+//
+// If the flag value is empty, the default visibility of the configuration file
+// in the readBucket is used. If there is no configuration file, the flag is required.
+func getVisibility(
+	ctx context.Context,
+	logger *zap.Logger,
+	readBucket storage.ReadBucket,
+	visibilityFlag string,
+) (registryv1alpha1.Visibility, error) {
+	if visibilityFlag != "" {
+		visibility, err := visibilityFlagToVisibility(visibilityFlag)
+		if err != nil {
+			return 0, appcmd.NewInvalidArgumentError(err.Error())
+		}
+		return visibility, nil
+	}
+	exists, err := bufconfig.ConfigExists(ctx, readBucket)
+	if err != nil {
+		return 0, bufcli.NewInternalError(err)
+	}
+	if !exists {
+		return 0, appcmd.NewInvalidArgumentErrorf("required flag %q not set", visibilityFlagName)
+	}
+	config, err := bufconfig.NewProvider(logger).GetConfig(ctx, readBucket)
+	if err != nil {
+		return 0, err
+	}
+	return visibilityFlagToVisibility(config.DefaultVisibility.String())
+}
+
 // visibilityFlagToVisibility parses the given string as a registryv1alpha1.Visibility.
 func visibilityFlagToVisibility(visibility string) (registryv1alpha1.Visibility, error) {
 	switch visibility {
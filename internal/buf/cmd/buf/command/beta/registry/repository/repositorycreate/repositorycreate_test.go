@@ -0,0 +1,73 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repositorycreate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufconfig"
+	registryv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/registry/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/storage/storagemem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetVisibility(t *testing.T) {
+	t.Parallel()
+	testGetVisibility(t, nil, "public", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+	testGetVisibility(t, nil, "private", registryv1alpha1.Visibility_VISIBILITY_PRIVATE)
+	testGetVisibility(t, []byte("version: v1\ndefault_visibility: public\n"), "", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+	testGetVisibility(t, []byte("version: v1\ndefault_visibility: public\n"), "private", registryv1alpha1.Visibility_VISIBILITY_PRIVATE)
+	testGetVisibility(t, []byte("version: v1\n"), "", registryv1alpha1.Visibility_VISIBILITY_PRIVATE)
+	testGetVisibilityError(t, nil, "")
+	testGetVisibilityError(t, nil, "unknown")
+	testGetVisibilityError(t, []byte("version: v1\ndefault_visibility: unknown\n"), "")
+}
+
+func testGetVisibility(
+	t *testing.T,
+	configData []byte,
+	visibilityFlag string,
+	expectedVisibility registryv1alpha1.Visibility,
+) {
+	visibility, err := testGetVisibilityForConfigData(t, configData, visibilityFlag)
+	require.NoError(t, err)
+	assert.Equal(t, expectedVisibility, visibility)
+}
+
+func testGetVisibilityError(
+	t *testing.T,
+	configData []byte,
+	visibilityFlag string,
+) {
+	_, err := testGetVisibilityForConfigData(t, configData, visibilityFlag)
+	assert.Error(t, err)
+}
+
+func testGetVisibilityForConfigData(
+	t *testing.T,
+	configData []byte,
+	visibilityFlag string,
+) (registryv1alpha1.Visibility, error) {
+	pathToData := make(map[string][]byte)
+	if configData != nil {
+		pathToData[bufconfig.ExternalConfigFilePath] = configData
+	}
+	readBucket, err := storagemem.NewReadBucket(pathToData)
+	require.NoError(t, err)
+	return getVisibility(context.Background(), zap.NewNop(), readBucket, visibilityFlag)
+}
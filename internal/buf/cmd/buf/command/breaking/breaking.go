@@ -112,9 +112,9 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
-		"text",
+		"",
 		fmt.Sprintf(
-			"The format for build errors or check violations, printed to stdout. Must be one of %s.",
+			"The format for build errors or check violations, printed to stdout. Must be one of %s. Check violations default to the output_format of the configuration. Build errors, and check violations without a configured output_format, default to text.",
 			stringutil.SliceToString(bufanalysis.AllFormatStrings),
 		),
 	)
@@ -294,12 +294,13 @@ func run(
 		if err := bufanalysis.PrintFileAnnotations(
 			container.Stdout(),
 			fileAnnotations,
-			flags.ErrorFormat,
+			bufcli.GetBuildErrorFormat(flags.ErrorFormat),
 		); err != nil {
 			return err
 		}
 		return errors.New("")
 	}
+	errorFormat := bufcli.GetErrorFormat(flags.ErrorFormat, imageConfigs)
 	// TODO: this doesn't actually work because we're using the same file paths for both sides
 	// if the roots change, then we're torched
 	externalPaths := paths
@@ -329,7 +330,7 @@ func run(
 		if err := bufanalysis.PrintFileAnnotations(
 			container.Stdout(),
 			fileAnnotations,
-			flags.ErrorFormat,
+			bufcli.GetBuildErrorFormat(flags.ErrorFormat),
 		); err != nil {
 			return err
 		}
@@ -352,7 +353,7 @@ func run(
 			imageConfig,
 			againstImageConfigs[i],
 			flags.ExcludeImports,
-			flags.ErrorFormat,
+			errorFormat,
 		)
 		if err != nil {
 			return err
@@ -367,7 +368,7 @@ func run(
 		if err := bufanalysis.PrintFileAnnotations(
 			container.Stdout(),
 			allFileAnnotations,
-			flags.ErrorFormat,
+			errorFormat,
 		); err != nil {
 			return err
 		}
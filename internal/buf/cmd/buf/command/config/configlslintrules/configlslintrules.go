@@ -119,7 +119,7 @@ func run(
 			if err != nil {
 				return err
 			}
-		case bufconfig.V1Version:
+		case bufconfig.V1Version, bufconfig.V2Version:
 			rules, err = buflint.GetAllRulesV1()
 			if err != nil {
 				return err
@@ -94,9 +94,9 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 	flagSet.StringVar(
 		&f.ErrorFormat,
 		errorFormatFlagName,
-		"text",
+		"",
 		fmt.Sprintf(
-			"The format for build errors or check violations, printed to stdout. Must be one of %s.",
+			"The format for build errors or check violations, printed to stdout. Must be one of %s. Check violations default to the output_format of the configuration. Build errors, and check violations without a configured output_format, default to text.",
 			stringutil.SliceToString(buflint.AllFormatStrings),
 		),
 	)
@@ -193,11 +193,11 @@ func run(
 		return err
 	}
 	if len(fileAnnotations) > 0 {
-		formatString := flags.ErrorFormat
-		if formatString == "config-ignore-yaml" {
-			formatString = "text"
-		}
-		if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, formatString); err != nil {
+		if err := bufanalysis.PrintFileAnnotations(
+			container.Stdout(),
+			fileAnnotations,
+			bufcli.GetBuildErrorFormat(flags.ErrorFormat),
+		); err != nil {
 			return err
 		}
 		return bufcli.ErrFileAnnotation
@@ -222,7 +222,7 @@ func run(
 		if err := buflint.PrintFileAnnotations(
 			container.Stdout(),
 			allFileAnnotations,
-			flags.ErrorFormat,
+			bufcli.GetErrorFormat(flags.ErrorFormat, imageConfigs),
 		); err != nil {
 			return err
 		}
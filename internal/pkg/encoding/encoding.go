@@ -21,14 +21,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
+	"github.com/BurntSushi/toml"
 	"go.uber.org/multierr"
 	"gopkg.in/yaml.v3"
 )
 
 // UnmarshalJSONStrict unmarshals the data as JSON, returning a user error on failure.
 //
+// Unknown fields and duplicate keys result in an error.
+//
 // If the data length is 0, this is a no-op.
 func UnmarshalJSONStrict(data []byte, v interface{}) error {
 	if len(data) == 0 {
@@ -37,13 +41,20 @@ func UnmarshalJSONStrict(data []byte, v interface{}) error {
 	jsonDecoder := json.NewDecoder(bytes.NewReader(data))
 	jsonDecoder.DisallowUnknownFields()
 	if err := jsonDecoder.Decode(v); err != nil {
-		return fmt.Errorf("could not unmarshal as JSON: %v", err)
+		return fmt.Errorf("could not unmarshal as JSON: %w", err)
+	}
+	// encoding/json silently uses the last value for duplicate keys, while
+	// the strict YAML decoder rejects them, so we do the same for JSON
+	if err := validateJSONNoDuplicateKeys(data); err != nil {
+		return fmt.Errorf("could not unmarshal as JSON: %w", err)
 	}
 	return nil
 }
 
 // UnmarshalYAMLStrict unmarshals the data as YAML, returning a user error on failure.
 //
+// Unknown fields and duplicate keys result in an error that includes the line number.
+//
 // If the data length is 0, this is a no-op.
 func UnmarshalYAMLStrict(data []byte, v interface{}) error {
 	if len(data) == 0 {
@@ -51,7 +62,7 @@ func UnmarshalYAMLStrict(data []byte, v interface{}) error {
 	}
 	yamlDecoder := NewYAMLDecoderStrict(bytes.NewReader(data))
 	if err := yamlDecoder.Decode(v); err != nil {
-		return fmt.Errorf("could not unmarshal as YAML: %v", err)
+		return fmt.Errorf("could not unmarshal as YAML: %w", err)
 	}
 	return nil
 }
@@ -66,7 +77,10 @@ func UnmarshalJSONOrYAMLStrict(data []byte, v interface{}) error {
 	}
 	if jsonErr := UnmarshalJSONStrict(data, v); jsonErr != nil {
 		if yamlErr := UnmarshalYAMLStrict(data, v); yamlErr != nil {
-			return errors.New(jsonErr.Error() + "\n" + yamlErr.Error())
+			return &jsonOrYAMLError{
+				jsonErr: jsonErr,
+				yamlErr: yamlErr,
+			}
 		}
 	}
 	return nil
@@ -81,7 +95,7 @@ func UnmarshalJSONNonStrict(data []byte, v interface{}) error {
 	}
 	jsonDecoder := json.NewDecoder(bytes.NewReader(data))
 	if err := jsonDecoder.Decode(v); err != nil {
-		return fmt.Errorf("could not unmarshal as JSON: %v", err)
+		return fmt.Errorf("could not unmarshal as JSON: %w", err)
 	}
 	return nil
 }
@@ -95,7 +109,7 @@ func UnmarshalYAMLNonStrict(data []byte, v interface{}) error {
 	}
 	yamlDecoder := NewYAMLDecoderNonStrict(bytes.NewReader(data))
 	if err := yamlDecoder.Decode(v); err != nil {
-		return fmt.Errorf("could not unmarshal as YAML: %v", err)
+		return fmt.Errorf("could not unmarshal as YAML: %w", err)
 	}
 	return nil
 }
@@ -116,6 +130,44 @@ func UnmarshalJSONOrYAMLNonStrict(data []byte, v interface{}) error {
 	return nil
 }
 
+// UnmarshalTOMLStrict unmarshals the data as TOML, returning a user error on failure.
+//
+// The toml struct tags are used. Unknown fields and duplicate keys result in an error.
+//
+// If the data length is 0, this is a no-op.
+func UnmarshalTOMLStrict(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	metaData, err := toml.Decode(string(data), v)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal as TOML: %w", err)
+	}
+	if undecoded := metaData.Undecoded(); len(undecoded) > 0 {
+		undecodedStrings := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			undecodedStrings[i] = strconv.Quote(key.String())
+		}
+		return fmt.Errorf("could not unmarshal as TOML: unknown fields %s", strings.Join(undecodedStrings, ", "))
+	}
+	return nil
+}
+
+// UnmarshalTOMLNonStrict unmarshals the data as TOML, returning a user error on failure.
+//
+// The toml struct tags are used.
+//
+// If the data length is 0, this is a no-op.
+func UnmarshalTOMLNonStrict(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err := toml.Decode(string(data), v); err != nil {
+		return fmt.Errorf("could not unmarshal as TOML: %w", err)
+	}
+	return nil
+}
+
 // GetJSONStringOrStringValue returns the JSON string for the RawMessage if the
 // RawMessage is a string, and the raw value as a string otherwise.
 //
@@ -190,3 +242,70 @@ func InterfaceSliceOrStringToCommaSepString(in interface{}) (string, error) {
 	}
 	return opt, nil
 }
+
+// validateJSONNoDuplicateKeys returns an error with the line number if any object
+// within the JSON data has a duplicate key.
+//
+// The data must be valid JSON.
+func validateJSONNoDuplicateKeys(data []byte) error {
+	return validateJSONValueNoDuplicateKeys(json.NewDecoder(bytes.NewReader(data)), data)
+}
+
+func validateJSONValueNoDuplicateKeys(jsonDecoder *json.Decoder, data []byte) error {
+	token, err := jsonDecoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	switch delim {
+	case '{':
+		keys := make(map[string]struct{})
+		for jsonDecoder.More() {
+			token, err := jsonDecoder.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := token.(string)
+			if !ok {
+				return fmt.Errorf("unexpected object key %v", token)
+			}
+			if _, ok := keys[key]; ok {
+				line := bytes.Count(data[:jsonDecoder.InputOffset()], []byte("\n")) + 1
+				return fmt.Errorf("line %d: duplicate key %q", line, key)
+			}
+			keys[key] = struct{}{}
+			if err := validateJSONValueNoDuplicateKeys(jsonDecoder, data); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for jsonDecoder.More() {
+			if err := validateJSONValueNoDuplicateKeys(jsonDecoder, data); err != nil {
+				return err
+			}
+		}
+	}
+	// the closing delimiter
+	_, err = jsonDecoder.Token()
+	return err
+}
+
+// jsonOrYAMLError is the error of UnmarshalJSONOrYAMLStrict if the data could
+// be unmarshalled as neither JSON nor YAML.
+type jsonOrYAMLError struct {
+	jsonErr error
+	yamlErr error
+}
+
+func (e *jsonOrYAMLError) Error() string {
+	return e.jsonErr.Error() + "\n" + e.yamlErr.Error()
+}
+
+// As allows errors.As to find the errors of both the JSON and the YAML decoder,
+// with the JSON error taking precedence.
+func (e *jsonOrYAMLError) As(target interface{}) bool {
+	return errors.As(e.jsonErr, target) || errors.As(e.yamlErr, target)
+}
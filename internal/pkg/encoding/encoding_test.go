@@ -16,10 +16,51 @@ package encoding
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func TestUnmarshalStrictDuplicateKeys(t *testing.T) {
+	t.Parallel()
+	type foo struct {
+		One []string `json:"one,omitempty" yaml:"one,omitempty"`
+		Two string   `json:"two,omitempty" yaml:"two,omitempty"`
+	}
+	err := UnmarshalYAMLStrict([]byte("one:\n  - a\ntwo: b\none:\n  - c\n"), &foo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 4")
+	err = UnmarshalJSONStrict([]byte("{\n  \"one\": [\"a\"],\n  \"one\": [\"c\"]\n}"), &foo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `line 3: duplicate key "one"`)
+	err = UnmarshalJSONStrict([]byte(`{"one": [{"two": "a", "two": "b"}]}`), &map[string]interface{}{})
+	require.Error(t, err)
+	err = UnmarshalJSONStrict([]byte(`{"one": ["a", "c"], "two": "b"}`), &foo{})
+	require.NoError(t, err)
+	err = UnmarshalJSONStrict([]byte(`{"one": ["a"], "three": "b"}`), &foo{})
+	require.Error(t, err)
+}
+
+func TestUnmarshalTOMLStrict(t *testing.T) {
+	t.Parallel()
+	type foo struct {
+		One   []string  `json:"one,omitempty" yaml:"one,omitempty" toml:"one,omitempty"`
+		Two   string    `json:"two,omitempty" yaml:"two,omitempty" toml:"two,omitempty"`
+		Three time.Time `json:"three,omitempty" yaml:"three,omitempty" toml:"three,omitempty"`
+	}
+	value := &foo{}
+	require.NoError(t, UnmarshalTOMLStrict([]byte("one = [\"a\", \"c\"]\ntwo = \"b\"\nthree = 1979-05-27T07:32:00Z"), value))
+	assert.Equal(t, &foo{One: []string{"a", "c"}, Two: "b", Three: time.Date(1979, 5, 27, 7, 32, 0, 0, time.UTC)}, value)
+	err := UnmarshalTOMLStrict([]byte(`four = "b"`), &foo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown fields "four"`)
+	assert.NoError(t, UnmarshalTOMLNonStrict([]byte(`four = "b"`), &foo{}))
+	err = UnmarshalTOMLStrict([]byte("two = \"a\"\ntwo = \"b\""), &foo{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
 func TestInterfaceSliceOrStringToCommaSepString(t *testing.T) {
 	t.Parallel()
 	testInterfaceSliceOrStringToCommaSepString(t, "mystring", "mystring")
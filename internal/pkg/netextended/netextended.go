@@ -18,16 +18,20 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 )
 
 const (
 	domainNameMinLength = 2
 	domainNameMaxLength = 254
 	maxSegmentLength    = 63
+	maxPort             = 65535
 )
 
 // ValidateHostname verifies the given hostname is a well-formed IP address
 // or domain name, optionally including a port, and returns the hostname part.
+//
+// If a port is included, it must be a number between 1 and 65535.
 func ValidateHostname(hostname string) (string, error) {
 	if len(hostname) == 0 {
 		return "", errors.New("must not be empty")
@@ -37,7 +41,10 @@ func ValidateHostname(hostname string) (string, error) {
 	}
 
 	parsedHost := hostname
-	if host, _, err := net.SplitHostPort(hostname); err == nil {
+	if host, port, err := net.SplitHostPort(hostname); err == nil {
+		if err := validatePort(port); err != nil {
+			return "", fmt.Errorf("invalid port %q: %w", port, err)
+		}
 		parsedHost = host
 	}
 	if net.ParseIP(parsedHost) != nil {
@@ -50,6 +57,23 @@ func ValidateHostname(hostname string) (string, error) {
 	return hostname, nil
 }
 
+// validatePort validates that the port is a number between 1 and 65535.
+func validatePort(port string) error {
+	if port == "" {
+		return errors.New("must not be empty")
+	}
+	for _, char := range port {
+		if char < '0' || char > '9' {
+			return errors.New("must only contain digits")
+		}
+	}
+	value, err := strconv.Atoi(port)
+	if err != nil || value < 1 || value > maxPort {
+		return fmt.Errorf("must be between 1 and %d", maxPort)
+	}
+	return nil
+}
+
 // isValidDomainName validates a hostname according to the requirements set for
 // domain names internally in the Go standard library's net package, see
 // golang.org/issue/12421.
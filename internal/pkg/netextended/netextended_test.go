@@ -91,6 +91,31 @@ func TestValidateHostname(t *testing.T) {
 			hostname:    "is.this.a.valid.domain?",
 			isValid:     false,
 		},
+		{
+			description: "domain name with maximum port is valid",
+			hostname:    "bsr.internal.acme.com:65535",
+			isValid:     true,
+		},
+		{
+			description: "non-numeric port is invalid",
+			hostname:    "localhost:http",
+			isValid:     false,
+		},
+		{
+			description: "empty port is invalid",
+			hostname:    "localhost:",
+			isValid:     false,
+		},
+		{
+			description: "zero port is invalid",
+			hostname:    "localhost:0",
+			isValid:     false,
+		},
+		{
+			description: "out of range port is invalid",
+			hostname:    "localhost:65536",
+			isValid:     false,
+		},
 		{
 			description: "hostname must be set",
 			hostname:    "",
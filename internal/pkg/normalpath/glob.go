@@ -0,0 +1,94 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalpath
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const globDoubleStar = "**"
+
+// ValidateGlob validates that the pattern is a valid pattern for MatchGlob.
+//
+// The pattern must be relative, must not contain ".." components, and "**" must
+// be an entire path component.
+func ValidateGlob(pattern string) error {
+	if pattern == "" {
+		return errors.New("glob pattern must not be empty")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return fmt.Errorf("glob pattern %q must be relative", pattern)
+	}
+	for _, component := range strings.Split(pattern, "/") {
+		switch {
+		case component == "":
+			return fmt.Errorf("glob pattern %q must not contain empty path components", pattern)
+		case component == "..":
+			return fmt.Errorf("glob pattern %q must not contain \"..\"", pattern)
+		case component == globDoubleStar:
+		case strings.Contains(component, globDoubleStar):
+			return fmt.Errorf("glob pattern %q can only use \"**\" as an entire path component", pattern)
+		default:
+			if _, err := path.Match(component, ""); err != nil {
+				return fmt.Errorf("glob pattern %q is invalid: %w", pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchGlob returns true if the normalized relative path matches the pattern.
+//
+// Path components are matched with the syntax of path.Match, so "*" matches any
+// sequence of characters within a single path component. A "**" path component
+// matches zero or more path components, for example "**/vendor/**" matches all
+// files within any vendor directory.
+//
+// The pattern is expected to have been validated with ValidateGlob. Invalid
+// patterns never match.
+func MatchGlob(pattern string, path string) bool {
+	return matchGlobComponents(
+		strings.Split(pattern, "/"),
+		strings.Split(Normalize(path), "/"),
+	)
+}
+
+func matchGlobComponents(patternComponents []string, pathComponents []string) bool {
+	for len(patternComponents) > 0 {
+		patternComponent := patternComponents[0]
+		if patternComponent == globDoubleStar {
+			// try every possible number of components for the "**"
+			for i := 0; i <= len(pathComponents); i++ {
+				if matchGlobComponents(patternComponents[1:], pathComponents[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(pathComponents) == 0 {
+			return false
+		}
+		matched, err := path.Match(patternComponent, pathComponents[0])
+		if err != nil || !matched {
+			return false
+		}
+		patternComponents = patternComponents[1:]
+		pathComponents = pathComponents[1:]
+	}
+	return len(pathComponents) == 0
+}
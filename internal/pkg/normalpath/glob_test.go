@@ -0,0 +1,54 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+	testMatchGlob(t, true, "**/vendor/**", "vendor/a.proto")
+	testMatchGlob(t, true, "**/vendor/**", "a/vendor/b/c.proto")
+	testMatchGlob(t, false, "**/vendor/**", "a/vendored/c.proto")
+	testMatchGlob(t, true, "*.proto", "a.proto")
+	testMatchGlob(t, false, "*.proto", "a/a.proto")
+	testMatchGlob(t, true, "**/*.proto", "a.proto")
+	testMatchGlob(t, true, "**/*.proto", "a/b/a.proto")
+	testMatchGlob(t, true, "a/*/c.proto", "a/b/c.proto")
+	testMatchGlob(t, false, "a/*/c.proto", "a/b/b/c.proto")
+	testMatchGlob(t, true, "a/**/c.proto", "a/c.proto")
+	testMatchGlob(t, true, "gen/**", "gen/a/b.proto")
+	testMatchGlob(t, false, "gen/**", "other/gen/b.proto")
+	testMatchGlob(t, true, "foo_*.proto", "./foo_bar.proto")
+}
+
+func TestValidateGlob(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, ValidateGlob("**/vendor/**"))
+	assert.NoError(t, ValidateGlob("a/*.proto"))
+	assert.Error(t, ValidateGlob(""))
+	assert.Error(t, ValidateGlob("/a/*.proto"))
+	assert.Error(t, ValidateGlob("../a/*.proto"))
+	assert.Error(t, ValidateGlob("a//b"))
+	assert.Error(t, ValidateGlob("a/**.proto"))
+	assert.Error(t, ValidateGlob("a/[.proto"))
+}
+
+func testMatchGlob(t *testing.T, expected bool, pattern string, path string) {
+	assert.Equal(t, expected, MatchGlob(pattern, path), "pattern %q path %q", pattern, path)
+}
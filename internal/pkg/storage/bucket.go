@@ -151,6 +151,22 @@ type ObjectInfo interface {
 	ExternalPath() string
 }
 
+// ResolvedObjectInfo is an ObjectInfo of a bucket that follows symlinks.
+//
+// The ObjectInfos returned from Stat and Walk of such buckets implement this interface.
+type ResolvedObjectInfo interface {
+	ObjectInfo
+
+	// ResolvedExternalPath is the absolute external path of the object with all
+	// symlinks resolved.
+	//
+	// Two objects with the same ResolvedExternalPath are the same file.
+	ResolvedExternalPath() string
+	// IsSymlink returns true if the path of the object within the bucket goes through
+	// a symlink, either because the object or one of its parent directories is a symlink.
+	IsSymlink() bool
+}
+
 // ReadObject is an object read from a bucket.
 type ReadObject interface {
 	ObjectInfo
@@ -227,6 +227,14 @@ func replaceObjectInfoPath(objectInfo ObjectInfo, path string) ObjectInfo {
 	if objectInfo.Path() == path {
 		return objectInfo
 	}
+	if resolvedObjectInfo, ok := objectInfo.(ResolvedObjectInfo); ok {
+		return storageutil.NewResolvedObjectInfo(
+			path,
+			resolvedObjectInfo.ExternalPath(),
+			resolvedObjectInfo.ResolvedExternalPath(),
+			resolvedObjectInfo.IsSymlink(),
+		)
+	}
 	return storageutil.NewObjectInfo(
 		path,
 		objectInfo.ExternalPath(),
@@ -56,6 +56,23 @@ func MatchPathContained(containingDir string) Matcher {
 	})
 }
 
+// MatchPathGlob returns a Matcher for the glob pattern.
+//
+// See normalpath.MatchGlob for the pattern syntax.
+func MatchPathGlob(pattern string) Matcher {
+	return pathMatcherFunc(func(path string) bool {
+		return normalpath.MatchGlob(pattern, path)
+	})
+}
+
+// MatchPathFunc returns a Matcher for the function.
+//
+// The function is called with the path within the bucket, and returns true
+// if the path matches.
+func MatchPathFunc(f func(string) bool) Matcher {
+	return pathMatcherFunc(f)
+}
+
 // MatchOr returns an Or of the Matchers.
 func MatchOr(matchers ...Matcher) Matcher {
 	return orMatcher(matchers)
@@ -33,6 +33,10 @@ var errNotDir = errors.New("not a directory")
 type bucket struct {
 	rootPath         string
 	absoluteRootPath string
+	// resolvedRootPath is absoluteRootPath with all symlinks resolved.
+	//
+	// This is only set if symlinks is true.
+	resolvedRootPath string
 	symlinks         bool
 }
 
@@ -45,12 +49,20 @@ func newBucket(rootPath string, symlinks bool) (*bucket, error) {
 	if err != nil {
 		return nil, err
 	}
+	var resolvedRootPath string
+	if symlinks {
+		resolvedRootPath, err = filepath.EvalSymlinks(absoluteRootPath)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// do not validate - allow anything with OS buckets including
 	// absolute paths and jumping context
 	rootPath = normalpath.Normalize(rootPath)
 	return &bucket{
 		rootPath:         rootPath,
 		absoluteRootPath: absoluteRootPath,
+		resolvedRootPath: resolvedRootPath,
 		symlinks:         symlinks,
 	}, nil
 }
@@ -91,10 +103,7 @@ func (b *bucket) Stat(ctx context.Context, path string) (storage.ObjectInfo, err
 		return nil, err
 	}
 	// we could use fileInfo.Name() however we might as well use the externalPath
-	return storageutil.NewObjectInfo(
-		path,
-		externalPath,
-	), nil
+	return b.newObjectInfo(path, externalPath)
 }
 
 func (b *bucket) Walk(
@@ -139,12 +148,11 @@ func (b *bucket) Walk(
 				if err != nil {
 					return err
 				}
-				if err := f(
-					storageutil.NewObjectInfo(
-						path,
-						externalPath,
-					),
-				); err != nil {
+				objectInfo, err := b.newObjectInfo(path, externalPath)
+				if err != nil {
+					return err
+				}
+				if err := f(objectInfo); err != nil {
 					return err
 				}
 			}
@@ -266,6 +274,34 @@ func (b *bucket) validateExternalPath(path string, externalPath string) error {
 	return nil
 }
 
+// newObjectInfo returns a new ObjectInfo for the path.
+//
+// If symlinks are followed, this returns a storage.ResolvedObjectInfo.
+func (b *bucket) newObjectInfo(path string, externalPath string) (storage.ObjectInfo, error) {
+	if !b.symlinks {
+		return storageutil.NewObjectInfo(
+			path,
+			externalPath,
+		), nil
+	}
+	absoluteExternalPath, err := filepath.Abs(externalPath)
+	if err != nil {
+		return nil, err
+	}
+	resolvedExternalPath, err := filepath.EvalSymlinks(absoluteExternalPath)
+	if err != nil {
+		return nil, err
+	}
+	// the root itself may be within a symlinked directory, so we compare to the
+	// resolved root to only detect symlinks within the bucket
+	return storageutil.NewResolvedObjectInfo(
+		path,
+		externalPath,
+		resolvedExternalPath,
+		resolvedExternalPath != filepath.Join(b.resolvedRootPath, normalpath.Unnormalize(path)),
+	), nil
+}
+
 func (b *bucket) getExternalPrefix(prefix string) (string, error) {
 	prefix, err := storageutil.ValidatePrefix(prefix)
 	if err != nil {
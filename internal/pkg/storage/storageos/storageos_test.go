@@ -15,13 +15,16 @@
 package storageos_test
 
 import (
+	"context"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/storage"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/bufbuild/buf/internal/pkg/storage/storagetesting"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -38,6 +41,58 @@ func TestOS(t *testing.T) {
 	)
 }
 
+func TestResolvedObjectInfos(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skip symlink tests on Windows")
+	}
+	t.Parallel()
+	ctx := context.Background()
+	filepathextendedTestdataDirPath := filepath.Join("..", "..", "filepathextended", "testdata")
+	readBucket, err := storageos.NewProvider(storageos.ProviderWithSymlinks()).NewReadWriteBucket(
+		filepath.Join(filepathextendedTestdataDirPath, "symlink_success"),
+		storageos.ReadWriteBucketWithSymlinksIfSupported(),
+	)
+	require.NoError(t, err)
+	pathToIsSymlink := make(map[string]bool)
+	require.NoError(
+		t,
+		readBucket.Walk(
+			ctx,
+			"",
+			func(objectInfo storage.ObjectInfo) error {
+				resolvedObjectInfo, ok := objectInfo.(storage.ResolvedObjectInfo)
+				require.True(t, ok, objectInfo.Path())
+				pathToIsSymlink[objectInfo.Path()] = resolvedObjectInfo.IsSymlink()
+				return nil
+			},
+		),
+	)
+	assert.False(t, pathToIsSymlink["file.proto"])
+	assert.True(t, pathToIsSymlink["1.proto"])
+	assert.True(t, pathToIsSymlink["a/file.proto"])
+	assert.True(t, pathToIsSymlink["ab/1.proto"])
+	objectInfo, err := readBucket.Stat(ctx, "1.proto")
+	require.NoError(t, err)
+	resolvedObjectInfo, ok := objectInfo.(storage.ResolvedObjectInfo)
+	require.True(t, ok)
+	assert.True(t, resolvedObjectInfo.IsSymlink())
+	expectedResolvedExternalPath, err := filepath.Abs(filepath.Join(filepathextendedTestdataDirPath, "base", "1.proto"))
+	require.NoError(t, err)
+	expectedResolvedExternalPath, err = filepath.EvalSymlinks(expectedResolvedExternalPath)
+	require.NoError(t, err)
+	assert.Equal(t, expectedResolvedExternalPath, resolvedObjectInfo.ResolvedExternalPath())
+
+	// ObjectInfos are only resolved if symlinks are followed
+	readBucket, err = storageos.NewProvider().NewReadWriteBucket(
+		filepath.Join(filepathextendedTestdataDirPath, "symlink_success"),
+	)
+	require.NoError(t, err)
+	objectInfo, err = readBucket.Stat(ctx, "file.proto")
+	require.NoError(t, err)
+	_, ok = objectInfo.(storage.ResolvedObjectInfo)
+	assert.False(t, ok)
+}
+
 func testNewReadBucket(t *testing.T, dirPath string, storageosProvider storageos.Provider) (storage.ReadBucket, storagetesting.GetExternalPathFunc) {
 	osBucket, err := storageosProvider.NewReadWriteBucket(
 		dirPath,
@@ -50,6 +50,38 @@ func (o ObjectInfo) ExternalPath() string {
 	return o.externalPath
 }
 
+// ResolvedObjectInfo is an embeddable ResolvedObjectInfo.
+type ResolvedObjectInfo struct {
+	ObjectInfo
+
+	resolvedExternalPath string
+	isSymlink            bool
+}
+
+// NewResolvedObjectInfo returns a new ResolvedObjectInfo.
+func NewResolvedObjectInfo(
+	path string,
+	externalPath string,
+	resolvedExternalPath string,
+	isSymlink bool,
+) ResolvedObjectInfo {
+	return ResolvedObjectInfo{
+		ObjectInfo:           NewObjectInfo(path, externalPath),
+		resolvedExternalPath: resolvedExternalPath,
+		isSymlink:            isSymlink,
+	}
+}
+
+// ResolvedExternalPath implements ResolvedObjectInfo.
+func (o ResolvedObjectInfo) ResolvedExternalPath() string {
+	return o.resolvedExternalPath
+}
+
+// IsSymlink implements ResolvedObjectInfo.
+func (o ResolvedObjectInfo) IsSymlink() bool {
+	return o.isSymlink
+}
+
 // ValidatePath validates a path.
 func ValidatePath(path string) (string, error) {
 	path, err := normalpath.NormalizeAndValidate(path)